@@ -0,0 +1,21 @@
+package app
+
+import "github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+
+// AuditDB audits the child chain stored in db against the root chain.
+func AuditDB(db *DB, rc *core.RootChain) (*core.AuditReport, error) {
+	var report *core.AuditReport
+	if err := db.View(func(txn core.Txn) error {
+		cc, err := core.OpenChildChain(txn)
+		if err != nil {
+			return err
+		}
+
+		report, err = cc.Audit(txn, rc)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
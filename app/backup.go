@@ -0,0 +1,180 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+const (
+	BackupVersionTrailer = "X-Backup-Version"
+
+	ExportLineTypeBlock = "block"
+	ExportLineTypeUTXO  = "utxo"
+)
+
+// RestoreDB loads badger backups taken with DB.Backup into dir, which has to be empty or missing.
+// An incremental backup has to follow the backups it was taken on top of, in the order they were taken.
+func RestoreDB(dir string, rs ...io.Reader) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(files) > 0 {
+		return fmt.Errorf("%s is not empty", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	db, err := NewDB(DBConfig{Engine: DBEngineBadger, Dir: dir})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for _, r := range rs {
+		if err := db.Load(r); err != nil {
+			return err
+		}
+	}
+
+	return db.DeleteMarkedKeys()
+}
+
+// ExportLine is a line of the NDJSON export of the child chain, which holds either a block or a utxo.
+type ExportLine struct {
+	Type  string            `json:"type"`
+	Block *ExportBlock      `json:"block,omitempty"`
+	UTXO  *core.AddressUTXO `json:"utxo,omitempty"`
+}
+
+// ExportBlock is an exported block. A pruned block is exported without its encoding.
+type ExportBlock struct {
+	Number   uint64      `json:"blknum"`
+	Root     common.Hash `json:"root"`
+	Encoded  string      `json:"blk,omitempty"`
+	IsPruned bool        `json:"pruned,omitempty"`
+}
+
+// ExportDB writes every block and then the utxo set of the child chain stored in db to w as NDJSON.
+func ExportDB(db *DB, w io.Writer) error {
+	return db.View(func(txn core.Txn) error {
+		cc, err := core.OpenChildChain(txn)
+		if err != nil {
+			return err
+		}
+
+		return exportChildChain(cc, txn, w)
+	})
+}
+
+func exportChildChain(cc *core.ChildChain, txn core.Txn, w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	currentBlkNum, err := cc.GetCurrentBlockNumber(txn)
+	if err != nil {
+		return err
+	}
+
+	for blkNum := uint64(core.FirstBlockNumber); blkNum < currentBlkNum; blkNum++ {
+		blk, err := cc.GetBlock(txn, blkNum)
+		if err == core.ErrBlockPruned {
+			rootHash, err := cc.GetBlockRoot(txn, blkNum)
+			if err != nil {
+				return err
+			}
+			if err := enc.Encode(&ExportLine{
+				Type: ExportLineTypeBlock,
+				Block: &ExportBlock{
+					Number:   blkNum,
+					Root:     rootHash,
+					IsPruned: true,
+				},
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		blkBytes, err := rlp.EncodeToBytes(blk)
+		if err != nil {
+			return err
+		}
+		rootHash, err := blk.Root()
+		if err != nil {
+			return err
+		}
+
+		if err := enc.Encode(&ExportLine{
+			Type: ExportLineTypeBlock,
+			Block: &ExportBlock{
+				Number:  blkNum,
+				Root:    rootHash,
+				Encoded: utils.EncodeToHex(blkBytes),
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
+	utxos, err := cc.GetAllUTXOs(txn)
+	if err != nil {
+		return err
+	}
+	for _, utxo := range utxos {
+		if err := enc.Encode(&ExportLine{
+			Type: ExportLineTypeUTXO,
+			UTXO: utxo,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VerifyBackup restores the backups read from rs in order into a temporary store
+// and compares the restored blocks with the roots committed to the root chain.
+// The backups are usually older than the root chain, so only drifts make them invalid.
+func VerifyBackup(rc *core.RootChain, rs ...io.Reader) (*core.ConsistencyReport, error) {
+	dir, err := ioutil.TempDir("", "mmpc-verify")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := RestoreDB(dir, rs...); err != nil {
+		return nil, err
+	}
+
+	db, err := NewDB(DBConfig{Engine: DBEngineBadger, Dir: dir})
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var report *core.ConsistencyReport
+	if err := db.View(func(txn core.Txn) error {
+		cc, err := core.OpenChildChain(txn)
+		if err != nil {
+			return err
+		}
+
+		report, err = cc.CheckConsistency(txn, rc)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
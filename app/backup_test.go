@@ -0,0 +1,200 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBadgerPlasma builds a plasma storing the child chain in badger under a temporary directory.
+func newTestBadgerPlasma(t *testing.T) (*Plasma, func()) {
+	dir, err := ioutil.TempDir("", "mmpc-backup")
+	require.NoError(t, err)
+
+	p, closePlasma := newTestPlasma(t, Config{
+		DB: DBConfig{
+			Engine: DBEngineBadger,
+			Dir:    dir,
+		},
+	})
+
+	return p, func() {
+		closePlasma()
+		os.RemoveAll(dir)
+	}
+}
+
+// restoreTestDB restores the backups read from rs into a new directory and opens it.
+func restoreTestDB(t *testing.T, rs ...io.Reader) (*DB, func()) {
+	dir, err := ioutil.TempDir("", "mmpc-restore")
+	require.NoError(t, err)
+
+	// the restored directory must not exist yet or be empty
+	dbDir := filepath.Join(dir, "db")
+	require.NoError(t, RestoreDB(dbDir, rs...))
+
+	db, err := NewDB(DBConfig{Engine: DBEngineBadger, Dir: dbDir})
+	require.NoError(t, err)
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// requireTestDBsEqual checks that expected and actual hold the same blocks, tokens and metadata, and the same keys at all.
+func requireTestDBsEqual(t *testing.T, expected, actual *DB) {
+	dump := func(db *DB) (string, map[string]string) {
+		var buf bytes.Buffer
+		require.NoError(t, ExportDB(db, &buf))
+
+		kvs := map[string]string{}
+		require.NoError(t, db.View(func(txn core.Txn) error {
+			it := txn.NewIterator(nil)
+			defer it.Close()
+			for ; it.Valid(); it.Next() {
+				val, err := it.Value()
+				if err != nil {
+					return err
+				}
+				kvs[string(it.Key())] = string(val)
+			}
+			return nil
+		}))
+
+		return buf.String(), kvs
+	}
+
+	expectedExport, expectedKVs := dump(expected)
+	actualExport, actualKVs := dump(actual)
+	require.Equal(t, expectedExport, actualExport)
+	require.Equal(t, expectedKVs, actualKVs)
+
+	// the metadata of the child chain
+	require.NoError(t, expected.View(func(expectedTxn core.Txn) error {
+		return actual.View(func(actualTxn core.Txn) error {
+			require.Equal(t, core.CountKeys(expectedTxn), core.CountKeys(actualTxn))
+
+			expectedCC, err := core.OpenChildChain(expectedTxn)
+			require.NoError(t, err)
+			actualCC, err := core.OpenChildChain(actualTxn)
+			require.NoError(t, err)
+
+			expectedBlkNum, err := expectedCC.GetCurrentBlockNumber(expectedTxn)
+			require.NoError(t, err)
+			actualBlkNum, err := actualCC.GetCurrentBlockNumber(actualTxn)
+			require.NoError(t, err)
+			require.Equal(t, expectedBlkNum, actualBlkNum)
+
+			return nil
+		})
+	}))
+}
+
+func TestExportDB(t *testing.T) {
+	// no child chain is created in an empty store
+	db, err := NewDB(DBConfig{Engine: DBEngineMemory})
+	require.NoError(t, err)
+	defer db.Close()
+
+	var buf bytes.Buffer
+	require.Equal(t, core.ErrChildChainNotFound, ExportDB(db, &buf))
+	require.Equal(t, core.ErrChildChainNotFound, ExportDB(db, &buf))
+	require.Zero(t, buf.Len())
+
+	p, closePlasma := newTestPlasma(t, Config{})
+	defer closePlasma()
+
+	a := newTestAccount(t)
+	depositBlkNum := addTestDeposit(t, p, a, 100)
+
+	require.NoError(t, ExportDB(p.db, &buf))
+
+	lines := []*ExportLine{}
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var line ExportLine
+		require.NoError(t, dec.Decode(&line))
+		lines = append(lines, &line)
+	}
+	require.Len(t, lines, 2)
+	require.Equal(t, ExportLineTypeBlock, lines[0].Type)
+	require.Equal(t, depositBlkNum, lines[0].Block.Number)
+	require.Equal(t, ExportLineTypeUTXO, lines[1].Type)
+	require.Equal(t, a.Address(), lines[1].UTXO.Address)
+}
+
+func TestVerifyBackup_NoChildChain(t *testing.T) {
+	// the root chain is not called for a backup without a child chain
+	_, err := VerifyBackup(nil)
+	require.Equal(t, core.ErrChildChainNotFound, err)
+}
+
+func TestRestoreDB(t *testing.T) {
+	p, closePlasma := newTestBadgerPlasma(t)
+	defer closePlasma()
+
+	a, b := newTestAccount(t), newTestAccount(t)
+	depositBlkNum := addTestDeposit(t, p, a, 100)
+	addTestBlock(t, p, a, newTestSpendingTx(t, types.NewTxOutPosition(depositBlkNum, 0, 0), a,
+		types.NewTxOut(b.Address(), big.NewInt(60)),
+		types.NewTxOut(a.Address(), big.NewInt(40)),
+	))
+
+	var buf bytes.Buffer
+	_, err := p.db.Backup(&buf, 0)
+	require.NoError(t, err)
+
+	db, closeDB := restoreTestDB(t, &buf)
+	defer closeDB()
+
+	requireTestDBsEqual(t, p.db, db)
+}
+
+func TestRestoreDB_Incremental(t *testing.T) {
+	p, closePlasma := newTestBadgerPlasma(t)
+	defer closePlasma()
+
+	a, b := newTestAccount(t), newTestAccount(t)
+	depositBlkNum := addTestDeposit(t, p, a, 100)
+
+	var fullBuf bytes.Buffer
+	version, err := p.db.Backup(&fullBuf, 0)
+	require.NoError(t, err)
+	fullBytes := fullBuf.Bytes()
+
+	// the deposit is spent and another deposit is made after the full backup
+	blkNum := addTestBlock(t, p, a, newTestSpendingTx(t, types.NewTxOutPosition(depositBlkNum, 0, 0), a,
+		types.NewTxOut(b.Address(), big.NewInt(100)),
+	))
+	addTestDeposit(t, p, b, 10)
+
+	var incBuf bytes.Buffer
+	_, err = p.db.Backup(&incBuf, version)
+	require.NoError(t, err)
+
+	// the full backup alone lacks the later blocks
+	db, closeDB := restoreTestDB(t, bytes.NewReader(fullBytes))
+	require.NoError(t, db.View(func(txn core.Txn) error {
+		cc, err := core.OpenChildChain(txn)
+		require.NoError(t, err)
+		_, err = cc.GetBlock(txn, blkNum)
+		require.Equal(t, core.ErrBlockNotFound, err)
+		return nil
+	}))
+	closeDB()
+
+	db, closeDB = restoreTestDB(t, bytes.NewReader(fullBytes), &incBuf)
+	defer closeDB()
+
+	requireTestDBsEqual(t, p.db, db)
+}
@@ -10,15 +10,39 @@ import (
 )
 
 type Config struct {
-	Port      int                  `json:"port"`
-	DB        DBConfig             `json:"db"`
-	Operator  OperatorConfig       `json:"operator"`
-	RootChain core.RootChainConfig `json:"rootchain"`
-	Heartbeat HeartbeatConfig      `json:"heartbeat"`
+	Port        int                  `json:"port"`
+	DB          DBConfig             `json:"db"`
+	Operator    OperatorConfig       `json:"operator"`
+	RootChain   core.RootChainConfig `json:"rootchain"`
+	Heartbeat   HeartbeatConfig      `json:"heartbeat"`
+	Mempool     MempoolConfig        `json:"mempool"`
+	Consistency ConsistencyConfig    `json:"consistency"`
+	Follower    FollowerConfig       `json:"follower"`
+	Archive     ArchiveConfig        `json:"archive"`
+	Admin       AdminConfig          `json:"admin"`
+	Prune       PruneConfig          `json:"prune"`
 }
 
 type DBConfig struct {
-	Dir string `json:"dir"`
+	Engine string `json:"engine"` // badger (default), leveldb or memory
+	Dir    string `json:"dir"`
+
+	// badger options, where the zero values keep the badger defaults
+	TableLoadingMode     string `json:"tableloadingmode"`    // fileio, loadtoram or mmap
+	ValueLogLoadingMode  string `json:"valuelogloadingmode"` // fileio or mmap
+	ValueThreshold       int    `json:"valuethreshold"`      // bytes, larger values are stored in the value log
+	IsSyncWritesDisabled bool   `json:"nosyncwrites"`        // do not sync every write to disk
+
+	GCIntervalInt  int     `json:"gcinterval"`     // seconds between value log GC runs, 0 disables GC
+	GCDiscardRatio float64 `json:"gcdiscardratio"` // rewrite a value log file once this ratio of it is discardable, 0.5 by default
+}
+
+func (conf DBConfig) IsGCEnabled() bool {
+	return conf.GCIntervalInt > 0
+}
+
+func (conf DBConfig) GCInterval() (time.Duration, error) {
+	return time.ParseDuration(fmt.Sprintf("%ds", conf.GCIntervalInt))
 }
 
 type OperatorConfig struct {
@@ -37,3 +61,73 @@ type HeartbeatConfig struct {
 func (conf HeartbeatConfig) Interval() (time.Duration, error) {
 	return time.ParseDuration(fmt.Sprintf("%ds", conf.IntervalInt))
 }
+
+type MempoolConfig struct {
+	TTLInt      int `json:"ttl"`      // seconds, 0 disables eviction
+	IntervalInt int `json:"interval"` // seconds between eviction runs
+}
+
+func (conf MempoolConfig) IsEvictionEnabled() bool {
+	return conf.TTLInt > 0
+}
+
+func (conf MempoolConfig) TTL() (time.Duration, error) {
+	return time.ParseDuration(fmt.Sprintf("%ds", conf.TTLInt))
+}
+
+func (conf MempoolConfig) Interval() (time.Duration, error) {
+	return time.ParseDuration(fmt.Sprintf("%ds", conf.IntervalInt))
+}
+
+type ConsistencyConfig struct {
+	IsStopWritesEnabled bool `json:"stopwrites"` // stop serving writes when the blocks drift from the root chain
+}
+
+// FollowerConfig enables the follower mode, in which the app replicates the blocks of the operator
+// and serves reads without holding the operator key.
+type FollowerConfig struct {
+	IsEnabled           bool   `json:"enabled"`
+	API                 string `json:"api"`      // API of the operator
+	Archive             string `json:"archive"`  // block archive to replicate from instead of the API, a URL or a directory
+	IntervalInt         int    `json:"interval"` // seconds between replication runs
+	IsForwardingEnabled bool   `json:"forward"`  // forward writes to the operator instead of rejecting them
+}
+
+func (conf FollowerConfig) Interval() (time.Duration, error) {
+	return time.ParseDuration(fmt.Sprintf("%ds", conf.IntervalInt))
+}
+
+// ArchiveConfig enables the block archive, which is served under /archive.
+type ArchiveConfig struct {
+	Dir string `json:"dir"` // empty disables the archive
+}
+
+func (conf ArchiveConfig) IsEnabled() bool {
+	return conf.Dir != ""
+}
+
+// AdminConfig enables the admin API, which requires the token in the X-Admin-Token header.
+type AdminConfig struct {
+	Token string `json:"token"` // empty disables the admin API
+}
+
+func (conf AdminConfig) IsEnabled() bool {
+	return conf.Token != ""
+}
+
+// PruneConfig enables pruning, which drops the bodies of the txes whose txouts were all spent
+// at least depth blocks ago and keeps only what challenges and exits still need.
+// The blocks not archived yet are never pruned. Pruned blocks are no longer served by GET /blocks/:blkNum,
+// so followers of a pruning node have to replicate from its archive.
+type PruneConfig struct {
+	Depth       uint64 `json:"depth"`    // blocks, 0 disables pruning
+	IntervalInt int    `json:"interval"` // seconds between pruning runs
+}
+
+func (conf PruneConfig) IsEnabled() bool {
+	return conf.Depth > 0
+}
+
+func (conf PruneConfig) Interval() (time.Duration, error) {
+	return time.ParseDuration(fmt.Sprintf("%ds", conf.IntervalInt))
+}
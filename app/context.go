@@ -7,6 +7,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/labstack/echo"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
 )
@@ -31,10 +32,18 @@ func (c *Context) GetTxPositionFromPath() (types.Position, error) {
 	return c.getPositionFromPath("txPos")
 }
 
+func (c *Context) GetTxOutPositionFromPath() (types.Position, error) {
+	return c.getPositionFromPath("txOutPos")
+}
+
 func (c *Context) GetTxInPositionFromPath() (types.Position, error) {
 	return c.getPositionFromPath("txInPos")
 }
 
+func (c *Context) GetTxHashFromPath() (common.Hash, error) {
+	return c.getHashFromPath("txHash")
+}
+
 func (c *Context) getAddressFromPath(key string) (common.Address, error) {
 	addrStr := c.getPathParam(key)
 	if !utils.IsHexAddress(addrStr) {
@@ -44,6 +53,15 @@ func (c *Context) getAddressFromPath(key string) (common.Address, error) {
 	return utils.HexToAddress(addrStr), nil
 }
 
+func (c *Context) getHashFromPath(key string) (common.Hash, error) {
+	hashStr := c.getPathParam(key)
+	if !utils.IsHexHash(hashStr) {
+		return types.NullHash, NewInvalidPathParamError(key)
+	}
+
+	return utils.HexToHash(hashStr), nil
+}
+
 func (c *Context) getUint64FromPath(key string) (uint64, error) {
 	return utils.StringToUint64(c.getPathParam(key))
 }
@@ -65,6 +83,10 @@ func (c *Context) GetConfirmationSignatureFromForm() (types.Signature, error) {
 	return c.getRequiredSignatureFromForm("confsig")
 }
 
+func (c *Context) GetSignatureFromForm() (types.Signature, error) {
+	return c.getRequiredSignatureFromForm("sig")
+}
+
 func (c *Context) GetTxFromForm() (*types.Tx, error) {
 	return c.getRequiredTxFromForm("tx")
 }
@@ -77,6 +99,45 @@ func (c *Context) GetAmountFromForm() (*big.Int, error) {
 	return c.getRequiredBigIntFromForm("amount")
 }
 
+func (c *Context) GetFromBlockNumberFromForm() (uint64, error) {
+	return c.getUint64FromForm("from")
+}
+
+func (c *Context) GetToBlockNumberFromForm() (uint64, error) {
+	return c.getUint64FromForm("to")
+}
+
+// GetAtBlockNumberFromForm returns the block number to query the state as of, which is 0 for the latest state.
+func (c *Context) GetAtBlockNumberFromForm() (uint64, error) {
+	return c.getUint64FromForm("at")
+}
+
+func (c *Context) GetDirectionFromForm() (string, error) {
+	dir := c.getFormParam("dir")
+	switch dir {
+	case "", core.AddressTxDirectionIn, core.AddressTxDirectionOut:
+		return dir, nil
+	default:
+		return "", NewInvalidFormParamError("dir")
+	}
+}
+
+func (c *Context) GetMinAmountFromForm() (*big.Int, error) {
+	return c.getBigIntFromForm("min")
+}
+
+func (c *Context) GetOffsetFromForm() (uint64, error) {
+	return c.getUint64FromForm("offset")
+}
+
+func (c *Context) GetLimitFromForm() (uint64, error) {
+	return c.getUint64FromForm("limit")
+}
+
+func (c *Context) GetSinceFromForm() (uint64, error) {
+	return c.getUint64FromForm("since")
+}
+
 func (c *Context) getRequiredSignatureFromForm(key string) (types.Signature, error) {
 	sigStr, err := c.getRequiredFormParam(key)
 	if err != nil {
@@ -132,6 +193,34 @@ func (c *Context) getRequiredBigIntFromForm(key string) (*big.Int, error) {
 	return utils.StringToBigInt(s)
 }
 
+// getUint64FromForm returns 0 if the optional param is missing.
+func (c *Context) getUint64FromForm(key string) (uint64, error) {
+	if !c.isExistFormParam(key) {
+		return 0, nil
+	}
+
+	i, err := utils.StringToUint64(c.getFormParam(key))
+	if err != nil {
+		return 0, NewInvalidFormParamError(key)
+	}
+
+	return i, nil
+}
+
+// getBigIntFromForm returns nil if the optional param is missing.
+func (c *Context) getBigIntFromForm(key string) (*big.Int, error) {
+	if !c.isExistFormParam(key) {
+		return nil, nil
+	}
+
+	i, err := utils.StringToBigInt(c.getFormParam(key))
+	if err != nil {
+		return nil, NewInvalidFormParamError(key)
+	}
+
+	return i, nil
+}
+
 func (c *Context) getRequiredFormParam(key string) (string, error) {
 	if !c.isExistFormParam(key) {
 		return "", NewRequiredFormParamError(key)
@@ -153,10 +242,8 @@ func (c *Context) JSONSuccess(result interface{}) error {
 }
 
 func (c *Context) JSONError(err error) error {
-	var appErr *Error
-	if e, ok := err.(*Error); ok {
-		appErr = e
-	} else {
+	appErr := ConvertError(err)
+	if appErr == nil {
 		c.Logger().Error(err)
 		appErr = ErrUnexpected
 	}
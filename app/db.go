@@ -1,20 +1,359 @@
 package app
 
-import "github.com/dgraph-io/badger"
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/dgraph-io/badger"
+	"github.com/dgraph-io/badger/options"
+	"github.com/dgraph-io/badger/protos"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	DBEngineBadger  = "badger"
+	DBEngineLevelDB = "leveldb"
+	DBEngineMemory  = "memory"
+)
+
+const (
+	defaultGCDiscardRatio = 0.5
+
+	// backupDeletedUserMeta marks the entries of deleted keys in a backup, which the store never sets itself
+	backupDeletedUserMeta byte = 0x01
+)
 
 type DB struct {
-	*badger.DB
+	core.KV
+	engine string
 }
 
 func NewDB(conf DBConfig) (*DB, error) {
+	switch conf.Engine {
+	case "", DBEngineBadger:
+		opts, err := badgerOptions(conf)
+		if err != nil {
+			return nil, err
+		}
+
+		db, err := badger.Open(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return &DB{
+			KV:     core.NewBadgerKV(db),
+			engine: DBEngineBadger,
+		}, nil
+
+	case DBEngineLevelDB:
+		db, err := leveldb.OpenFile(conf.Dir, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return &DB{
+			KV:     core.NewLevelDBKV(db),
+			engine: DBEngineLevelDB,
+		}, nil
+
+	case DBEngineMemory:
+		return &DB{
+			KV:     core.NewMemoryKV(),
+			engine: DBEngineMemory,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown db engine: %s", conf.Engine)
+	}
+}
+
+func badgerOptions(conf DBConfig) (badger.Options, error) {
 	opts := badger.DefaultOptions
 	opts.Dir = conf.Dir
 	opts.ValueDir = conf.Dir
+	opts.SyncWrites = !conf.IsSyncWritesDisabled
+
+	if conf.TableLoadingMode != "" {
+		mode, err := parseFileLoadingMode(conf.TableLoadingMode)
+		if err != nil {
+			return opts, err
+		}
+		opts.TableLoadingMode = mode
+	}
+	if conf.ValueLogLoadingMode != "" {
+		mode, err := parseFileLoadingMode(conf.ValueLogLoadingMode)
+		if err != nil {
+			return opts, err
+		}
+		if mode == options.LoadToRAM {
+			return opts, fmt.Errorf("value log cannot be loaded to RAM")
+		}
+		opts.ValueLogLoadingMode = mode
+	}
+	if conf.ValueThreshold > 0 {
+		opts.ValueThreshold = conf.ValueThreshold
+	}
+
+	return opts, nil
+}
+
+func parseFileLoadingMode(s string) (options.FileLoadingMode, error) {
+	switch s {
+	case "fileio":
+		return options.FileIO, nil
+	case "loadtoram":
+		return options.LoadToRAM, nil
+	case "mmap":
+		return options.MemoryMap, nil
+	default:
+		return 0, fmt.Errorf("unknown file loading mode: %s", s)
+	}
+}
+
+func (db *DB) Engine() string {
+	return db.engine
+}
+
+// View runs fn in a read-only txn.
+func (db *DB) View(fn func(txn core.Txn) error) error {
+	txn := db.NewTransaction(false)
+	defer txn.Discard()
+
+	return fn(txn)
+}
+
+func (db *DB) badger() (*badger.DB, error) {
+	kv, ok := db.KV.(*core.BadgerKV)
+	if !ok {
+		return nil, ErrUnsupportedDBEngine
+	}
+
+	return kv.DB(), nil
+}
+
+// Backup writes the changes since the version since to w, which only badger supports.
+// Badger writes the deletions of keys as entries with empty values, which are loaded as they are,
+// so they are marked to be deleted again by DeleteMarkedKeys once the backups are loaded.
+func (db *DB) Backup(w io.Writer, since uint64) (uint64, error) {
+	bdb, err := db.badger()
+	if err != nil {
+		return 0, err
+	}
+
+	pr, pw := io.Pipe()
+	var version uint64
+	errCh := make(chan error, 1)
+	go func() {
+		var err error
+		version, err = bdb.Backup(pw, since)
+		pw.CloseWithError(err)
+		errCh <- err
+	}()
+
+	if err := markDeletedBackupEntries(bdb, pr, w); err != nil {
+		pr.CloseWithError(err)
+		<-errCh
+		return 0, err
+	}
+	if err := <-errCh; err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// markDeletedBackupEntries copies the backup read from r to w, marking the entries of deleted keys.
+func markDeletedBackupEntries(bdb *badger.DB, r io.Reader, w io.Writer) error {
+	br := bufio.NewReader(r)
+	for {
+		var size uint64
+		if err := binary.Read(br, binary.LittleEndian, &size); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return err
+		}
 
-	db, err := badger.Open(opts)
+		entry := &protos.KVPair{}
+		if err := entry.Unmarshal(buf); err != nil {
+			return err
+		}
+		if len(entry.Value) == 0 {
+			isDeleted, err := isDeletedVersion(bdb, entry.Key, entry.Version)
+			if err != nil {
+				return err
+			}
+			if isDeleted {
+				entry.UserMeta = []byte{backupDeletedUserMeta}
+			}
+		}
+
+		buf, err := entry.Marshal()
+		if err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, uint64(len(buf))); err != nil {
+			return err
+		}
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+}
+
+// isDeletedVersion reports whether the version of key is a deletion.
+// A version no longer stored was discarded for a later version, which the backup holds too.
+func isDeletedVersion(bdb *badger.DB, key []byte, version uint64) (bool, error) {
+	isDeleted := true
+	err := bdb.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.AllVersions = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(key); it.Valid(); it.Next() {
+			item := it.Item()
+			if !bytes.Equal(item.Key(), key) {
+				break
+			}
+			if item.Version() == version {
+				isDeleted = item.IsDeletedOrExpired()
+				break
+			}
+		}
+
+		return nil
+	})
+
+	return isDeleted, err
+}
+
+// Load loads a backup taken with Backup, which only badger supports.
+func (db *DB) Load(r io.Reader) error {
+	bdb, err := db.badger()
 	if err != nil {
+		return err
+	}
+
+	return bdb.Load(r)
+}
+
+// DeleteMarkedKeys deletes the keys whose deletions were loaded from backups,
+// which has to be done once all the backups are loaded.
+func (db *DB) DeleteMarkedKeys() error {
+	bdb, err := db.badger()
+	if err != nil {
+		return err
+	}
+
+	keys := [][]byte{}
+	if err := bdb.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			if item := it.Item(); item.UserMeta() == backupDeletedUserMeta {
+				keys = append(keys, item.KeyCopy(nil))
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return bdb.Update(func(txn *badger.Txn) error {
+		for _, key := range keys {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RunValueLogGC rewrites value log files until no file has discardRatio of it discardable,
+// and returns the number of rewritten files. Only badger has a value log.
+func (db *DB) RunValueLogGC(discardRatio float64) (int, error) {
+	bdb, err := db.badger()
+	if err != nil {
+		return 0, err
+	}
+
+	if discardRatio <= 0 {
+		discardRatio = defaultGCDiscardRatio
+	}
+
+	n := 0
+	for {
+		if err := bdb.RunValueLogGC(discardRatio); err != nil {
+			if err == badger.ErrNoRewrite {
+				return n, nil
+			}
+			return n, err
+		}
+		n++
+	}
+}
+
+// DBStats reports the size of a store.
+type DBStats struct {
+	Engine       string         `json:"engine"`
+	LSMSize      int64          `json:"lsm"`  // bytes
+	ValueLogSize int64          `json:"vlog"` // bytes, only badger has a value log
+	KeyCounts    map[string]int `json:"keys"` // the number of keys of each kind
+}
+
+// Size returns the sizes on disk of the LSM tree and the value log in bytes.
+// The sizes of badger are only updated once a minute, and an in-memory store has no size on disk.
+func (db *DB) Size() (int64, int64, error) {
+	switch kv := db.KV.(type) {
+	case *core.BadgerKV:
+		lsmSize, vlogSize := kv.DB().Size()
+		return lsmSize, vlogSize, nil
+	case *core.LevelDBKV:
+		sizes, err := kv.DB().SizeOf([]util.Range{{}})
+		if err != nil {
+			return 0, 0, err
+		}
+		return sizes.Sum(), 0, nil
+	default:
+		return 0, 0, nil
+	}
+}
+
+// Stats reports the sizes on disk and counts the keys of each kind.
+func (db *DB) Stats() (*DBStats, error) {
+	lsmSize, vlogSize, err := db.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &DBStats{
+		Engine:       db.engine,
+		LSMSize:      lsmSize,
+		ValueLogSize: vlogSize,
+	}
+
+	if err := db.View(func(txn core.Txn) error {
+		stats.KeyCounts = core.CountKeys(txn)
+		return nil
+	}); err != nil {
 		return nil, err
 	}
 
-	return &DB{db}, nil
+	return stats, nil
 }
@@ -4,17 +4,26 @@ import (
 	"fmt"
 
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
 )
 
 const (
+	CoreErrorCode      = 11000 // core errors without their own API error
 	PathParamErrorCode = 20001
 	FormParamErrorCode = 20002
+
+	ErrorElementTxIn  = "txin"
+	ErrorElementTxOut = "txout"
 )
 
 var (
 	ErrUnexpected = NewError(10000, "unexpected error")
 
 	ErrBlockchainNotSynchronized = NewError(10001, "blockchain is not synchronized")
+	ErrBlockchainDrifted         = NewError(10002, "blockchain drifted from root chain")
+	ErrReadOnly                  = NewError(10003, "node is read-only")
+	ErrInvalidAdminToken         = NewError(10004, "admin token is invalid")
+	ErrUnsupportedDBEngine       = NewError(10005, "operation is not supported by db engine")
 
 	ErrMempoolFull                    = NewError(11001, core.ErrMempoolFull.Error())
 	ErrBlockNotFound                  = NewError(11002, core.ErrBlockNotFound.Error())
@@ -28,11 +37,56 @@ var (
 	ErrNullTxInConfirmation           = NewError(11010, core.ErrNullTxInConfirmation.Error())
 	ErrTxOutAlreadySpent              = NewError(11011, core.ErrTxOutAlreadySpent.Error())
 	ErrTxOutAlreadyExited             = NewError(11012, core.ErrTxOutAlreadyExited.Error())
+	ErrTxOutNotFound                  = NewError(11013, core.ErrTxOutNotFound.Error())
+	ErrNullConfirmationSignature      = NewError(11014, core.ErrNullConfirmationSignature.Error())
+	ErrMempoolTxNotFound              = NewError(11015, core.ErrMempoolTxNotFound.Error())
+	ErrInvalidTxCancellationSignature = NewError(11016, core.ErrInvalidTxCancellationSignature.Error())
+	ErrInvalidMempoolOrder            = NewError(11017, core.ErrInvalidMempoolOrder.Error())
+	ErrBlockNotCommitted              = NewError(11018, core.ErrBlockNotCommitted.Error())
+	ErrTxOutNotSpent                  = NewError(11019, core.ErrTxOutNotSpent.Error())
+	ErrArchivedBlockCorrupted         = NewError(11020, core.ErrArchivedBlockCorrupted.Error())
+	ErrTxPruned                       = NewError(11021, core.ErrTxPruned.Error())
+	ErrBlockPruned                    = NewError(11022, core.ErrBlockPruned.Error())
 )
 
+// coreErrors maps each core error to the API error it is reported as.
+var coreErrors = map[*core.Error]*Error{
+	core.ErrMempoolFull:                    ErrMempoolFull,
+	core.ErrBlockNotFound:                  ErrBlockNotFound,
+	core.ErrEmptyBlock:                     ErrEmptyBlock,
+	core.ErrTxNotFound:                     ErrTxNotFound,
+	core.ErrInvalidTxSignature:             ErrInvalidTxSignature,
+	core.ErrInvalidTxConfirmationSignature: ErrInvalidTxConfirmationSignature,
+	core.ErrInvalidTxBalance:               ErrInvalidTxBalance,
+	core.ErrTxInNotFound:                   ErrTxInNotFound,
+	core.ErrInvalidTxIn:                    ErrInvalidTxIn,
+	core.ErrNullTxInConfirmation:           ErrNullTxInConfirmation,
+	core.ErrTxOutAlreadySpent:              ErrTxOutAlreadySpent,
+	core.ErrTxOutAlreadyExited:             ErrTxOutAlreadyExited,
+	core.ErrTxOutNotFound:                  ErrTxOutNotFound,
+	core.ErrNullConfirmationSignature:      ErrNullConfirmationSignature,
+	core.ErrMempoolTxNotFound:              ErrMempoolTxNotFound,
+	core.ErrInvalidTxCancellationSignature: ErrInvalidTxCancellationSignature,
+	core.ErrInvalidMempoolOrder:            ErrInvalidMempoolOrder,
+	core.ErrBlockNotCommitted:              ErrBlockNotCommitted,
+	core.ErrTxOutNotSpent:                  ErrTxOutNotSpent,
+	core.ErrArchivedBlockCorrupted:         ErrArchivedBlockCorrupted,
+	core.ErrTxPruned:                       ErrTxPruned,
+	core.ErrBlockPruned:                    ErrBlockPruned,
+}
+
 type Error struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Detail  *ErrorDetail `json:"detail,omitempty"`
+}
+
+// ErrorDetail describes which part of a request caused an error.
+type ErrorDetail struct {
+	Reason   string         `json:"reason"`
+	Element  string         `json:"element,omitempty"`
+	Index    uint64         `json:"index"`
+	Position types.Position `json:"pos"`
 }
 
 func NewError(code int, msg string) *Error {
@@ -63,6 +117,69 @@ func NewInvalidFormParamError(key string) *Error {
 	)
 }
 
+// ConvertError converts err into an API error.
+// A core error without its own API error is reported with CoreErrorCode and its core code as the reason.
+// It returns nil if err is neither an API error nor a core error.
+func ConvertError(err error) *Error {
+	if appErr, ok := err.(*Error); ok {
+		return appErr
+	}
+
+	cause := core.Cause(err)
+	if cause == nil {
+		return nil
+	}
+
+	appErr, ok := coreErrors[cause]
+	if !ok {
+		appErr = NewError(CoreErrorCode, cause.Message).WithDetail(&ErrorDetail{
+			Reason: cause.Code,
+		})
+	}
+
+	switch e := err.(type) {
+	case *core.TxInError:
+		return appErr.WithDetail(&ErrorDetail{
+			Reason:   cause.Code,
+			Element:  ErrorElementTxIn,
+			Index:    e.Index,
+			Position: e.Position,
+		})
+	case *core.TxOutError:
+		return appErr.WithDetail(&ErrorDetail{
+			Reason:   cause.Code,
+			Element:  ErrorElementTxOut,
+			Index:    e.Index,
+			Position: e.Position,
+		})
+	default:
+		return appErr
+	}
+}
+
+// WithDetail returns a copy of err that carries detail.
+func (err *Error) WithDetail(detail *ErrorDetail) *Error {
+	return &Error{
+		Code:    err.Code,
+		Message: err.Message,
+		Detail:  detail,
+	}
+}
+
+// Is reports whether target is an API error with the same code as err.
+func (err *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+
+	return err.Code == t.Code
+}
+
 func (err *Error) Error() string {
+	if err.Detail != nil && err.Detail.Element != "" {
+		return fmt.Sprintf("%s [%d] (%s %d)", err.Message, err.Code, err.Detail.Element, err.Detail.Index)
+	}
+
 	return fmt.Sprintf("%s [%d]", err.Message, err.Code)
 }
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertError(t *testing.T) {
+	txOutPos := types.NewTxOutPosition(1, 0, 0)
+
+	appErr := ConvertError(core.NewTxInError(core.ErrTxOutAlreadySpent, 1, txOutPos))
+	require.Equal(t, ErrTxOutAlreadySpent.Code, appErr.Code)
+	require.Equal(t, &ErrorDetail{
+		Reason:   "txout_already_spent",
+		Element:  ErrorElementTxIn,
+		Index:    1,
+		Position: txOutPos,
+	}, appErr.Detail)
+
+	appErr = ConvertError(core.NewTxOutError(core.ErrTxOutNotFound, 0, txOutPos))
+	require.Equal(t, ErrTxOutNotFound.Code, appErr.Code)
+	require.Equal(t, ErrorElementTxOut, appErr.Detail.Element)
+
+	// the shared API errors are never modified
+	require.Nil(t, ErrTxOutAlreadySpent.Detail)
+	require.Equal(t, ErrTxNotFound, ConvertError(core.ErrTxNotFound))
+	require.Equal(t, ErrReadOnly, ConvertError(ErrReadOnly))
+}
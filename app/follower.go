@@ -0,0 +1,258 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/labstack/echo"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+// BlockSource serves the blocks a follower replicates.
+type BlockSource interface {
+	GetBlock(ctx context.Context, blkNum uint64) (*types.Block, error)
+}
+
+// apiBlockSource gets blocks from the API of the operator.
+type apiBlockSource struct {
+	baseURI string
+}
+
+func newAPIBlockSource(baseURI string) *apiBlockSource {
+	return &apiBlockSource{
+		baseURI: baseURI,
+	}
+}
+
+func (src *apiBlockSource) GetBlock(ctx context.Context, blkNum uint64) (*types.Block, error) {
+	u, err := url.Parse(src.baseURI)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, fmt.Sprintf("blocks/%d", blkNum))
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		State  string          `json:"state"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, err
+	}
+	if res.State == ResponseStateError {
+		var appErr Error
+		if err := json.Unmarshal(res.Result, &appErr); err != nil {
+			return nil, err
+		}
+		return nil, &appErr
+	}
+
+	var result struct {
+		BlockStr string `json:"blk"`
+	}
+	if err := json.Unmarshal(res.Result, &result); err != nil {
+		return nil, err
+	}
+
+	blkBytes, err := utils.DecodeHex(result.BlockStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var blk types.Block
+	if err := rlp.DecodeBytes(blkBytes, &blk); err != nil {
+		return nil, err
+	}
+
+	return &blk, nil
+}
+
+// SetBlockSource replaces the source of the blocks a follower replicates.
+func (p *Plasma) SetBlockSource(src BlockSource) {
+	p.blockSource = src
+}
+
+// syncBlocks replicates the blocks committed to the root chain that are not stored yet.
+// Each block is verified against the committed root and the operator of the root chain
+// before being stored, and replication stops at the first block that fails.
+func (p *Plasma) syncBlocks() error {
+	rootBlkNum, err := p.rootChain.CurrentPlasmaBlockNumber()
+	if err != nil {
+		return err
+	}
+	operatorAddr, err := p.rootChain.Operator()
+	if err != nil {
+		return err
+	}
+
+	for {
+		txn := p.db.NewTransaction(false)
+		blkNum, err := p.childChain.GetCurrentBlockNumber(txn)
+		txn.Discard()
+		if err != nil {
+			return err
+		}
+		if blkNum >= rootBlkNum {
+			return nil
+		}
+
+		blk, err := p.blockSource.GetBlock(context.Background(), blkNum)
+		if appErr, ok := err.(*Error); ok && appErr.Is(ErrBlockPruned) {
+			// a pruning node does not serve the bodies of its old blocks
+			return fmt.Errorf("block %d is pruned by the source, so it has to be replicated from its archive", blkNum)
+		}
+		if err != nil {
+			return err
+		}
+
+		// verify root
+		rootHash, err := blk.Root()
+		if err != nil {
+			return err
+		}
+		pb, err := p.rootChain.PlasmaBlocks(blkNum)
+		if err != nil {
+			return err
+		}
+		if rootHash != pb.RootHash() {
+			return fmt.Errorf(
+				"root of block %d is %s but %s is committed",
+				blkNum, utils.HashToHex(rootHash), utils.HashToHex(pb.RootHash()),
+			)
+		}
+
+		// verify signer
+		signerAddr, err := blk.SignerAddress()
+		if err != nil {
+			return err
+		}
+		if signerAddr != operatorAddr {
+			return fmt.Errorf("block %d is signed by %s", blkNum, utils.AddressToHex(signerAddr))
+		}
+
+		if err := p.update(func(txn core.Txn) error {
+			return p.childChain.AddReplicatedBlock(txn, blk)
+		}); err != nil {
+			return err
+		}
+
+		p.Logger().Infof("[FOLLOW] blkNum: %d, root: %s", blkNum, utils.HashToHex(rootHash))
+
+		if err := p.archiveBlocks(); err != nil {
+			return err
+		}
+	}
+}
+
+// followerMiddleware rejects writes or forwards them to the operator.
+// A confirmation signature is also applied locally once the operator accepted it,
+// since it does not come with a new block.
+func (p *Plasma) followerMiddleware(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		if req.Method == http.MethodGet {
+			return h(c)
+		}
+
+		if !p.config.Follower.IsForwardingEnabled {
+			return NewContext(c).JSONError(ErrReadOnly)
+		}
+
+		// the body is read here, so it is restored for the operator
+		if err := req.ParseForm(); err != nil {
+			return NewContext(c).JSONError(NewError(FormParamErrorCode, "form is invalid"))
+		}
+		body := req.PostForm.Encode()
+		req.Body = ioutil.NopCloser(strings.NewReader(body))
+		req.ContentLength = int64(len(body))
+
+		if c.Path() != "/txins/:txInPos" {
+			p.forwarder.ServeHTTP(c.Response(), req)
+			return nil
+		}
+
+		// the response of the operator is held until it is known to be a success
+		rec := httptest.NewRecorder()
+		p.forwarder.ServeHTTP(rec, req)
+
+		if isSuccessResponse(rec) {
+			if err := p.confirmTx(NewContext(c)); err != nil {
+				p.Logger().Warnf("[CONFIRM] failed to apply locally: %s", err)
+			}
+		}
+
+		res := c.Response()
+		for k, vs := range rec.Header() {
+			res.Header()[k] = vs
+		}
+		res.WriteHeader(rec.Code)
+		_, err := res.Write(rec.Body.Bytes())
+
+		return err
+	}
+}
+
+func isSuccessResponse(rec *httptest.ResponseRecorder) bool {
+	if rec.Code/100 != 2 {
+		return false
+	}
+
+	var res struct {
+		State string `json:"state"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		return false
+	}
+
+	return res.State == ResponseStateSuccess
+}
+
+func (p *Plasma) confirmTx(c *Context) error {
+	txInPos, err := c.GetTxInPositionFromPath()
+	if err != nil {
+		return err
+	}
+	confSig, err := c.GetConfirmationSignatureFromForm()
+	if err != nil {
+		return err
+	}
+
+	return p.update(func(txn core.Txn) error {
+		return p.childChain.ConfirmTx(txn, txInPos, confSig)
+	})
+}
+
+func newForwarder(baseURI string) (*httputil.ReverseProxy, error) {
+	u, err := url.Parse(baseURI)
+	if err != nil {
+		return nil, err
+	}
+
+	return httputil.NewSingleHostReverseProxy(u), nil
+}
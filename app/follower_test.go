@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFollower builds a follower forwarding writes to the operator op,
+// which is served by a test server.
+func newTestFollower(t *testing.T, op *Plasma) (*Plasma, func()) {
+	server := httptest.NewServer(op.server)
+
+	p, closePlasma := newTestPlasma(t, Config{
+		Follower: FollowerConfig{
+			IsEnabled:           true,
+			API:                 server.URL,
+			IsForwardingEnabled: true,
+		},
+	})
+	forwarder, err := newForwarder(server.URL)
+	require.NoError(t, err)
+	p.forwarder = forwarder
+
+	return p, func() {
+		closePlasma()
+		server.Close()
+	}
+}
+
+// replicateTestBlocks stores the blocks of the operator op the follower p does not have yet.
+func replicateTestBlocks(t *testing.T, p, op *Plasma) {
+	opTxn := op.db.NewTransaction(false)
+	defer opTxn.Discard()
+
+	opBlkNum, err := op.childChain.GetCurrentBlockNumber(opTxn)
+	require.NoError(t, err)
+
+	require.NoError(t, p.update(func(txn core.Txn) error {
+		blkNum, err := p.childChain.GetCurrentBlockNumber(txn)
+		if err != nil {
+			return err
+		}
+		for ; blkNum < opBlkNum; blkNum++ {
+			blk, err := op.childChain.GetBlock(opTxn, blkNum)
+			if err != nil {
+				return err
+			}
+			if err := p.childChain.AddReplicatedBlock(txn, blk); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func TestPlasma_FollowerMiddleware_ConfirmTx(t *testing.T) {
+	op, closeOp := newTestPlasma(t, Config{})
+	defer closeOp()
+	p, closeFollower := newTestFollower(t, op)
+	defer closeFollower()
+
+	a, b := newTestAccount(t), newTestAccount(t)
+	depositBlkNum := addTestDeposit(t, op, a, 100)
+	tx := newTestSpendingTx(t, types.NewTxOutPosition(depositBlkNum, 0, 0), a, types.NewTxOut(b.Address(), big.NewInt(100)))
+	blkNum := addTestBlock(t, op, a, tx)
+	replicateTestBlocks(t, p, op)
+
+	txInPos := types.NewTxInPosition(blkNum, 0, 0)
+	path := fmt.Sprintf("/txins/%d", txInPos)
+	getConfSig := func(p *Plasma) types.Signature {
+		txn := p.db.NewTransaction(false)
+		defer txn.Discard()
+
+		storedTx, err := p.childChain.GetTx(txn, types.NewTxPosition(blkNum, 0))
+		require.NoError(t, err)
+		return storedTx.GetInput(0).ConfirmationSignature
+	}
+
+	// rejected by the operator, so not applied locally either
+	require.NoError(t, tx.Confirm(0, b))
+	var appErr Error
+	require.Equal(t, ResponseStateError, doTestRequest(t, p, http.MethodPut, path, url.Values{
+		"confsig": {tx.GetInput(0).ConfirmationSignature.Hex()},
+	}, nil, &appErr))
+	require.Equal(t, ErrInvalidTxConfirmationSignature.Code, appErr.Code)
+	require.True(t, getConfSig(op).IsNull())
+	require.True(t, getConfSig(p).IsNull())
+
+	// accepted by the operator, then applied locally
+	require.NoError(t, tx.Confirm(0, a))
+	confSig := tx.GetInput(0).ConfirmationSignature
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodPut, path, url.Values{
+		"confsig": {confSig.Hex()},
+	}, nil, nil))
+	require.Equal(t, confSig, getConfSig(op))
+	require.Equal(t, confSig, getConfSig(p))
+}
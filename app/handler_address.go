@@ -1,31 +1,120 @@
 package app
 
-import (
-	"sort"
-
-	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
-)
+import "github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
 
 func (p *Plasma) GetAddressUTXOsHandler(c *Context) error {
+	c.Request().ParseForm()
+
 	addr, err := c.GetAddressFromPath()
 	if err != nil {
 		return c.JSONError(err)
 	}
+	minAmount, err := c.GetMinAmountFromForm()
+	if err != nil {
+		return c.JSONError(err)
+	}
+	limit, err := c.GetLimitFromForm()
+	if err != nil {
+		return c.JSONError(err)
+	}
+	atBlkNum, err := c.GetAtBlockNumberFromForm()
+	if err != nil {
+		return c.JSONError(err)
+	}
 
 	// BEGIN RO TXN
 	txn := p.db.NewTransaction(false)
 	defer txn.Discard()
 
-	utxoPoses, err := p.childChain.GetUTXOPositions(txn, addr)
+	var utxos []*core.UTXO
+	if atBlkNum > 0 {
+		utxos, err = p.childChain.GetUTXOsAt(txn, addr, atBlkNum, minAmount, limit)
+	} else {
+		utxos, err = p.childChain.GetUTXOs(txn, addr, minAmount, limit)
+	}
 	if err != nil {
 		return c.JSONError(err)
 	}
 
-	sort.Slice(utxoPoses, func(i, j int) bool {
-		return utxoPoses[i] < utxoPoses[j]
+	return c.JSONSuccess(map[string][]*core.UTXO{
+		"utxos": utxos,
 	})
+}
+
+func (p *Plasma) GetAddressBalanceHandler(c *Context) error {
+	c.Request().ParseForm()
+
+	addr, err := c.GetAddressFromPath()
+	if err != nil {
+		return c.JSONError(err)
+	}
+	atBlkNum, err := c.GetAtBlockNumberFromForm()
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	var balance *core.Balance
+	if atBlkNum > 0 {
+		balance, err = p.childChain.GetBalanceAt(txn, addr, atBlkNum)
+	} else {
+		balance, err = p.childChain.GetBalance(txn, addr)
+	}
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	return c.JSONSuccess(balance)
+}
+
+func (p *Plasma) GetAddressTxesHandler(c *Context) error {
+	c.Request().ParseForm()
+
+	addr, err := c.GetAddressFromPath()
+	if err != nil {
+		return c.JSONError(err)
+	}
+	fromBlkNum, err := c.GetFromBlockNumberFromForm()
+	if err != nil {
+		return c.JSONError(err)
+	}
+	toBlkNum, err := c.GetToBlockNumberFromForm()
+	if err != nil {
+		return c.JSONError(err)
+	}
+	dir, err := c.GetDirectionFromForm()
+	if err != nil {
+		return c.JSONError(err)
+	}
+	offset, err := c.GetOffsetFromForm()
+	if err != nil {
+		return c.JSONError(err)
+	}
+	limit, err := c.GetLimitFromForm()
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	atxes, total, err := p.childChain.GetAddressTxes(txn, addr, core.AddressTxFilter{
+		FromBlockNumber: fromBlkNum,
+		ToBlockNumber:   toBlkNum,
+		Direction:       dir,
+		Offset:          offset,
+		Limit:           limit,
+	})
+	if err != nil {
+		return c.JSONError(err)
+	}
 
-	return c.JSONSuccess(map[string][]types.Position{
-		"utxos": utxoPoses,
+	return c.JSONSuccess(map[string]interface{}{
+		"txes":  atxes,
+		"total": total,
 	})
 }
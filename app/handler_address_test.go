@@ -0,0 +1,88 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlasma_GetAddressTxesHandler(t *testing.T) {
+	p, closePlasma := newTestPlasma(t, Config{})
+	defer closePlasma()
+
+	a, b := newTestAccount(t), newTestAccount(t)
+	depositBlkNum := addTestDeposit(t, p, a, 100)
+	blkNum := addTestBlock(t, p, a,
+		newTestSpendingTx(t, types.NewTxOutPosition(depositBlkNum, 0, 0), a, types.NewTxOut(b.Address(), big.NewInt(100))),
+	)
+
+	path := fmt.Sprintf("/addresses/%s/txes", utils.AddressToHex(a.Address()))
+
+	var result struct {
+		Txes  []*core.AddressTx `json:"txes"`
+		Total uint64            `json:"total"`
+	}
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodGet, path+"?dir=in", nil, nil, &result))
+	require.Equal(t, uint64(1), result.Total)
+	require.Equal(t, types.NewTxPosition(depositBlkNum, 0), result.Txes[0].Position)
+
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodGet, path+"?dir=out", nil, nil, &result))
+	require.Equal(t, uint64(1), result.Total)
+	require.Equal(t, types.NewTxPosition(blkNum, 0), result.Txes[0].Position)
+
+	var appErr Error
+	require.Equal(t, ResponseStateError, doTestRequest(t, p, http.MethodGet, path+"?dir=both", nil, nil, &appErr))
+	require.Equal(t, FormParamErrorCode, appErr.Code)
+}
+
+func TestPlasma_GetAddressUTXOsHandler(t *testing.T) {
+	p, closePlasma := newTestPlasma(t, Config{})
+	defer closePlasma()
+
+	a := newTestAccount(t)
+	depositBlkNumA := addTestDeposit(t, p, a, 100)
+	depositBlkNumB := addTestDeposit(t, p, a, 10)
+
+	path := fmt.Sprintf("/addresses/%s/utxos", utils.AddressToHex(a.Address()))
+
+	var result struct {
+		UTXOs []*core.UTXO `json:"utxos"`
+	}
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodGet, path, nil, nil, &result))
+	require.Len(t, result.UTXOs, 2)
+
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodGet, path+"?min=20", nil, nil, &result))
+	require.Len(t, result.UTXOs, 1)
+	require.Equal(t, types.NewTxOutPosition(depositBlkNumA, 0, 0), result.UTXOs[0].Position)
+	require.Equal(t, big.NewInt(100), result.UTXOs[0].Amount)
+
+	// as of the first deposit block
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodGet, fmt.Sprintf("%s?at=%d", path, depositBlkNumA), nil, nil, &result))
+	require.Len(t, result.UTXOs, 1)
+
+	var appErr Error
+	require.Equal(t, ResponseStateError, doTestRequest(t, p, http.MethodGet, fmt.Sprintf("%s?at=%d", path, depositBlkNumB+1), nil, nil, &appErr))
+	require.Equal(t, ErrBlockNotFound.Code, appErr.Code)
+}
+
+func TestPlasma_GetAddressBalanceHandler(t *testing.T) {
+	p, closePlasma := newTestPlasma(t, Config{})
+	defer closePlasma()
+
+	a := newTestAccount(t)
+	addTestDeposit(t, p, a, 100)
+	addTestDeposit(t, p, a, 10)
+
+	var balance core.Balance
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodGet,
+		fmt.Sprintf("/addresses/%s/balance", utils.AddressToHex(a.Address())), nil, nil, &balance))
+	require.Equal(t, big.NewInt(110), balance.Total)
+	require.Equal(t, uint64(2), balance.Count)
+	require.Equal(t, big.NewInt(110), balance.Spendable.Amount)
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+const (
+	AdminTokenHeader = "X-Admin-Token"
+)
+
+func (p *Plasma) adminMiddleware(h echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := c.Request().Header.Get(AdminTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(p.config.Admin.Token)) != 1 {
+			return NewContext(c).JSONError(ErrInvalidAdminToken)
+		}
+
+		return h(c)
+	}
+}
+
+// GetBackupHandler streams an online backup of the store.
+// The version to take the next incremental backup since is sent in a trailer,
+// since it is only known once the backup is written.
+func (p *Plasma) GetBackupHandler(c *Context) error {
+	since, err := c.GetSinceFromForm()
+	if err != nil {
+		return c.JSONError(err)
+	}
+	if _, err := p.db.badger(); err != nil {
+		return c.JSONError(err)
+	}
+
+	res := c.Response()
+	res.Header().Set("Trailer", BackupVersionTrailer)
+	res.Header().Set(echo.HeaderContentType, echo.MIMEOctetStream)
+	res.WriteHeader(http.StatusOK)
+
+	version, err := p.db.Backup(res, since)
+	if err != nil {
+		// too late to report an error in the body
+		p.Logger().Error(err)
+		return nil
+	}
+
+	res.Header().Set(BackupVersionTrailer, fmt.Sprintf("%d", version))
+	p.Logger().Infof("[BACKUP] since: %d, version: %d", since, version)
+
+	return nil
+}
+
+// GetExportHandler streams the blocks and the utxo set as NDJSON.
+func (p *Plasma) GetExportHandler(c *Context) error {
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+	res.WriteHeader(http.StatusOK)
+
+	if err := exportChildChain(p.childChain, txn, res); err != nil {
+		p.Logger().Error(err)
+	}
+
+	return nil
+}
+
+// GetDBStatsHandler reports the sizes of the store and the number of keys of each kind.
+func (p *Plasma) GetDBStatsHandler(c *Context) error {
+	stats, err := p.db.Stats()
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	return c.JSONSuccess(stats)
+}
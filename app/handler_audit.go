@@ -0,0 +1,19 @@
+package app
+
+// GetAuditHandler reconciles the supply of the child chain with the funds of the root chain contract.
+// It is served to admins only, since it goes through every token and calls the root chain.
+func (p *Plasma) GetAuditHandler(c *Context) error {
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	report, err := p.childChain.Audit(txn, p.rootChain)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	return c.JSONSuccess(map[string]interface{}{
+		"sound":  report.IsSound(),
+		"report": report,
+	})
+}
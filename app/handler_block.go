@@ -9,55 +9,45 @@ import (
 func (p *Plasma) PostBlockHandler(c *Context) error {
 	c.Request().ParseForm()
 
-	rootBlkNum, err := p.rootChain.CurrentPlasmaBlockNumber()
-	if err != nil {
+	// send the root left unsent by a previous request first
+	if err := p.commitBlockRoot(); err != nil {
 		return c.JSONError(err)
 	}
 
-	// BEGIN TXN
-	txn := p.db.NewTransaction(true)
-	defer txn.Discard()
-
-	currentBlkNum, err := p.childChain.GetCurrentBlockNumber(txn)
+	rootBlkNum, err := p.rootChain.CurrentPlasmaBlockNumber()
 	if err != nil {
 		return c.JSONError(err)
 	}
-	if rootBlkNum != currentBlkNum {
-		return c.JSONError(ErrBlockchainNotSynchronized)
-	}
 
-	newBlkNum, err := p.childChain.AddBlock(txn, p.operator)
-	if err != nil {
-		if err == core.ErrEmptyBlock {
-			return c.JSONError(ErrEmptyBlock)
+	var newBlkNum uint64
+	if err := p.update(func(txn core.Txn) error {
+		currentBlkNum, err := p.childChain.GetCurrentBlockNumber(txn)
+		if err != nil {
+			return err
+		}
+		if rootBlkNum != currentBlkNum {
+			return ErrBlockchainNotSynchronized
 		}
-		return c.JSONError(err)
-	}
 
-	newBlk, err := p.childChain.GetBlock(txn, newBlkNum)
-	if err != nil {
-		if err == core.ErrBlockNotFound {
-			return c.JSONError(ErrBlockNotFound)
+		newBlkNum, err = p.childChain.AddBlock(txn, p.operator)
+		if err != nil {
+			return err
 		}
-		return c.JSONError(err)
-	}
 
-	newBlkRootHash, err := newBlk.Root()
-	if err != nil {
+		// the root is sent after the block is stored, and sent again until it is sent successfully
+		return p.childChain.SetUnsentRootBlockNumber(txn, newBlkNum)
+	}); err != nil {
 		return c.JSONError(err)
 	}
 
-	if _, err := p.rootChain.CommitPlasmaBlockRoot(p.operator, newBlkRootHash); err != nil {
+	if err := p.commitBlockRoot(); err != nil {
 		return c.JSONError(err)
 	}
 
-	// COMMIT TXN
-	if err := txn.Commit(nil); err != nil {
-		return c.JSONError(err)
+	if err := p.archiveBlocks(); err != nil {
+		p.Logger().Error(err)
 	}
 
-	p.Logger().Infof("[COMMIT] root: %s", utils.HashToHex(newBlkRootHash))
-
 	return c.JSONSuccess(map[string]uint64{
 		"blknum": newBlkNum,
 	})
@@ -75,9 +65,6 @@ func (p *Plasma) GetBlockHandler(c *Context) error {
 
 	blk, err := p.childChain.GetBlock(txn, blkNum)
 	if err != nil {
-		if err == core.ErrBlockNotFound {
-			return c.JSONError(ErrBlockNotFound)
-		}
 		return c.JSONError(err)
 	}
 
@@ -0,0 +1,15 @@
+package app
+
+// GetConsistencyHandler compares the stored blocks with the root chain.
+// It is served to admins only, since it calls the root chain once per stored block.
+func (p *Plasma) GetConsistencyHandler(c *Context) error {
+	report, err := p.checkConsistency()
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	return c.JSONSuccess(map[string]interface{}{
+		"consistent": report.IsConsistent(),
+		"report":     report,
+	})
+}
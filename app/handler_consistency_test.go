@@ -0,0 +1,60 @@
+package app
+
+import (
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlasma_GetConsistencyHandler(t *testing.T) {
+	p, closePlasma := newTestPlasma(t, Config{
+		Admin: AdminConfig{
+			Token: "token",
+		},
+	})
+	defer closePlasma()
+
+	// rejected before the root chain is called
+	for _, token := range []string{"", "wrong"} {
+		header := http.Header{}
+		header.Set(AdminTokenHeader, token)
+
+		var appErr Error
+		require.Equal(t, ResponseStateError, doTestRequest(t, p, http.MethodGet, "/consistency", nil, header, &appErr))
+		require.Equal(t, ErrInvalidAdminToken.Code, appErr.Code)
+	}
+}
+
+func TestPlasma_StopWrites(t *testing.T) {
+	p, closePlasma := newTestPlasma(t, Config{})
+	defer closePlasma()
+
+	a, b := newTestAccount(t), newTestAccount(t)
+	depositBlkNum := addTestDeposit(t, p, a, 100)
+
+	tx := newTestSpendingTx(t, types.NewTxOutPosition(depositBlkNum, 0, 0), a, types.NewTxOut(b.Address(), big.NewInt(100)))
+	txBytes, err := rlp.EncodeToBytes(tx)
+	require.NoError(t, err)
+	form := url.Values{
+		"tx": {utils.EncodeToHex(txBytes)},
+	}
+
+	// writes are refused while the blocks drift, but reads are still served
+	atomic.StoreInt32(&p.writesStopped, 1)
+
+	var appErr Error
+	require.Equal(t, ResponseStateError, doTestRequest(t, p, http.MethodPost, "/txes", form, nil, &appErr))
+	require.Equal(t, ErrBlockchainDrifted.Code, appErr.Code)
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodGet, "/mempool", nil, nil, nil))
+
+	atomic.StoreInt32(&p.writesStopped, 0)
+
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodPost, "/txes", form, nil, nil))
+}
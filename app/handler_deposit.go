@@ -1,5 +1,11 @@
 package app
 
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
 func (p *Plasma) PostDepositHandler(c *Context) error {
 	c.Request().ParseForm()
 
@@ -12,18 +18,18 @@ func (p *Plasma) PostDepositHandler(c *Context) error {
 		return c.JSONError(err)
 	}
 
-	// BEGIN TXN
-	txn := p.db.NewTransaction(true)
-	defer txn.Discard()
-
-	newBlkNum, err := p.childChain.AddDepositBlock(txn, ownerAddr, amount, p.operator)
-	if err != nil {
+	var newBlkNum uint64
+	var evictedTxHashes []common.Hash
+	if err := p.update(func(txn core.Txn) error {
+		var err error
+		newBlkNum, evictedTxHashes, err = p.childChain.AddDepositBlock(txn, ownerAddr, amount, p.operator)
+		return err
+	}); err != nil {
 		return c.JSONError(err)
 	}
 
-	// COMMIT TXN
-	if err := txn.Commit(nil); err != nil {
-		return c.JSONError(err)
+	for _, txHash := range evictedTxHashes {
+		p.Logger().Infof("[EVICT] txHash: %s", utils.HashToHex(txHash))
 	}
 
 	return c.JSONSuccess(map[string]uint64{
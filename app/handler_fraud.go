@@ -0,0 +1,19 @@
+package app
+
+// GetFraudHandler re-validates the txes of the blocks committed to the root chain.
+// It is served to admins only, since it goes through every stored block and calls the root chain.
+func (p *Plasma) GetFraudHandler(c *Context) error {
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	report, err := p.childChain.FindFraud(txn, p.rootChain)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	return c.JSONSuccess(map[string]interface{}{
+		"valid":  report.IsValid(),
+		"report": report,
+	})
+}
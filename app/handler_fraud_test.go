@@ -0,0 +1,27 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlasma_GetFraudHandler(t *testing.T) {
+	p, closePlasma := newTestPlasma(t, Config{
+		Admin: AdminConfig{
+			Token: "token",
+		},
+	})
+	defer closePlasma()
+
+	// rejected before the root chain is called
+	for _, token := range []string{"", "wrong"} {
+		header := http.Header{}
+		header.Set(AdminTokenHeader, token)
+
+		var appErr Error
+		require.Equal(t, ResponseStateError, doTestRequest(t, p, http.MethodGet, "/fraud", nil, header, &appErr))
+		require.Equal(t, ErrInvalidAdminToken.Code, appErr.Code)
+	}
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+func (p *Plasma) GetMempoolHandler(c *Context) error {
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	mtxes, err := p.childChain.GetMempoolTxes(txn)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	mtxMaps := make([]map[string]interface{}, len(mtxes))
+	for i, mtx := range mtxes {
+		mtxMap, err := p.mempoolTxToMap(mtx)
+		if err != nil {
+			return c.JSONError(err)
+		}
+		mtxMaps[i] = mtxMap
+	}
+
+	return c.JSONSuccess(map[string]interface{}{
+		"txes": mtxMaps,
+	})
+}
+
+func (p *Plasma) GetMempoolTxHandler(c *Context) error {
+	txHash, err := c.GetTxHashFromPath()
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	mtx, err := p.childChain.GetMempoolTx(txn, txHash)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	mtxMap, err := p.mempoolTxToMap(mtx)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	return c.JSONSuccess(mtxMap)
+}
+
+func (p *Plasma) DeleteMempoolTxHandler(c *Context) error {
+	c.Request().ParseForm()
+
+	txHash, err := c.GetTxHashFromPath()
+	if err != nil {
+		return c.JSONError(err)
+	}
+	cancelSig, err := c.GetSignatureFromForm()
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	var removedTxHashes []common.Hash
+	if err := p.update(func(txn core.Txn) error {
+		mtx, err := p.childChain.GetMempoolTx(txn, txHash)
+		if err != nil {
+			return err
+		}
+
+		// the operator can drop any tx, others must own one of its inputs
+		signerAddr, err := mtx.Tx.CancellationSignerAddress(cancelSig)
+		if err == nil && bytes.Equal(signerAddr.Bytes(), p.operator.Address().Bytes()) {
+			removedTxHashes, err = p.childChain.RemoveTxFromMempool(txn, txHash)
+		} else {
+			removedTxHashes, err = p.childChain.CancelTx(txn, txHash, cancelSig)
+		}
+		return err
+	}); err != nil {
+		return c.JSONError(err)
+	}
+
+	removedTxHashStrs := make([]string, len(removedTxHashes))
+	for i, removedTxHash := range removedTxHashes {
+		removedTxHashStrs[i] = utils.HashToHex(removedTxHash)
+		p.Logger().Infof("[CANCEL] txHash: %s", removedTxHashStrs[i])
+	}
+
+	return c.JSONSuccess(map[string][]string{
+		"removed": removedTxHashStrs,
+	})
+}
+
+func (p *Plasma) mempoolTxToMap(mtx *core.MempoolTx) (map[string]interface{}, error) {
+	txHash, err := mtx.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	txBytes, err := rlp.EncodeToBytes(mtx.Tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"hash":    utils.HashToHex(txHash),
+		"tx":      utils.EncodeToHex(txBytes),
+		"txindex": mtx.TxIndex,
+		"fee":     mtx.Fee,
+		"added":   mtx.AddedAt,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlasma_DeleteMempoolTxHandler(t *testing.T) {
+	operator, a, b := newTestAccount(t), newTestAccount(t), newTestAccount(t)
+
+	testCases := []struct {
+		name   string
+		signer *types.Account
+		err    *Error
+	}{
+		{"dropped by the operator", operator, nil},
+		{"canceled by the input owner", a, nil},
+		{"signed by another account", b, ErrInvalidTxCancellationSignature},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, closePlasma := newTestPlasma(t, Config{})
+			defer closePlasma()
+			p.operator = operator
+
+			depositBlkNum := addTestDeposit(t, p, a, 100)
+			tx := newTestSpendingTx(t, types.NewTxOutPosition(depositBlkNum, 0, 0), a, types.NewTxOut(b.Address(), big.NewInt(100)))
+			require.NoError(t, p.update(func(txn core.Txn) error {
+				return p.childChain.AddTxToMempool(txn, tx)
+			}))
+			txHash, err := tx.Hash()
+			require.NoError(t, err)
+
+			cancelSig, err := tx.SignCancellation(tc.signer)
+			require.NoError(t, err)
+
+			// the signature is sent in the query as the client does
+			path := "/mempool/" + utils.HashToHex(txHash)
+			deletePath := path + "?" + url.Values{
+				"sig": {utils.EncodeToHex(cancelSig.Bytes())},
+			}.Encode()
+
+			if tc.err != nil {
+				var appErr Error
+				require.Equal(t, ResponseStateError, doTestRequest(t, p, http.MethodDelete, deletePath, nil, nil, &appErr))
+				require.Equal(t, tc.err.Code, appErr.Code)
+				require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodGet, path, nil, nil, nil))
+				return
+			}
+
+			var result struct {
+				Removed []string `json:"removed"`
+			}
+			require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodDelete, deletePath, nil, nil, &result))
+			require.Equal(t, []string{utils.HashToHex(txHash)}, result.Removed)
+
+			var appErr Error
+			require.Equal(t, ResponseStateError, doTestRequest(t, p, http.MethodGet, path, nil, nil, &appErr))
+			require.Equal(t, ErrMempoolTxNotFound.Code, appErr.Code)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
 )
 
@@ -14,33 +15,69 @@ func (p *Plasma) PostTxHandler(c *Context) error {
 		return c.JSONError(err)
 	}
 
-	// BEGIN TXN
-	txn := p.db.NewTransaction(true)
-	defer txn.Discard()
+	txHash, err := tx.Hash()
+	if err != nil {
+		return c.JSONError(err)
+	}
 
-	if err := p.childChain.AddTxToMempool(txn, tx); err != nil {
-		if err == core.ErrMempoolFull {
-			return c.JSONError(ErrMempoolFull)
-		} else if err == core.ErrInvalidTxIn {
-			return c.JSONError(ErrInvalidTxIn)
-		} else if err == core.ErrTxOutAlreadySpent {
-			return c.JSONError(ErrTxOutAlreadySpent)
-		} else if err == core.ErrTxOutAlreadyExited {
-			return c.JSONError(ErrTxOutAlreadyExited)
-		} else if err == core.ErrInvalidTxSignature {
-			return c.JSONError(ErrInvalidTxSignature)
-		} else if err == core.ErrInvalidTxBalance {
-			return c.JSONError(ErrInvalidTxBalance)
-		}
+	if err := p.update(func(txn core.Txn) error {
+		return p.childChain.AddTxToMempool(txn, tx)
+	}); err != nil {
 		return c.JSONError(err)
 	}
 
-	// COMMIT TX
-	if err := txn.Commit(nil); err != nil {
+	return c.JSONSuccess(map[string]string{
+		"hash": utils.HashToHex(txHash),
+	})
+}
+
+func (p *Plasma) PostTxValidationHandler(c *Context) error {
+	c.Request().ParseForm()
+
+	tx, err := c.GetTxFromForm()
+	if err != nil {
 		return c.JSONError(err)
 	}
 
-	return c.JSONSuccess(nil)
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	res, err := p.childChain.CheckTx(txn, tx)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	sigErrs := make([]*Error, len(res.SignatureErrors))
+	for i, sigErr := range res.SignatureErrors {
+		sigErrs[i] = convertValidationError(c, sigErr)
+	}
+
+	errs := make([]*Error, len(res.Errors))
+	for i, err := range res.Errors {
+		errs[i] = convertValidationError(c, err)
+	}
+
+	return c.JSONSuccess(map[string]interface{}{
+		"valid":   res.IsValid(),
+		"in":      res.InputAmount,
+		"out":     res.OutputAmount,
+		"fee":     res.Fee,
+		"sigerrs": sigErrs,
+		"errors":  errs,
+	})
+}
+
+// convertValidationError converts a violation reported by CheckTx into an API error,
+// which is never nil so that the error arrays hold no null entries.
+func convertValidationError(c *Context, err error) *Error {
+	appErr := ConvertError(err)
+	if appErr == nil {
+		c.Logger().Error(err)
+		return ErrUnexpected
+	}
+
+	return appErr
 }
 
 func (p *Plasma) GetTxHandler(c *Context) error {
@@ -55,9 +92,6 @@ func (p *Plasma) GetTxHandler(c *Context) error {
 
 	tx, err := p.childChain.GetTx(txn, txPos)
 	if err != nil {
-		if err == core.ErrTxNotFound {
-			return c.JSONError(ErrTxNotFound)
-		}
 		return c.JSONError(err)
 	}
 
@@ -83,9 +117,6 @@ func (p *Plasma) GetTxProofHandler(c *Context) error {
 
 	txProofBytes, err := p.childChain.GetTxProof(txn, txPos)
 	if err != nil {
-		if err == core.ErrTxNotFound {
-			return c.JSONError(ErrTxNotFound)
-		}
 		return c.JSONError(err)
 	}
 
@@ -93,3 +124,117 @@ func (p *Plasma) GetTxProofHandler(c *Context) error {
 		"proof": utils.EncodeToHex(txProofBytes),
 	})
 }
+
+func (p *Plasma) GetTxStatusHandler(c *Context) error {
+	txHash, err := c.GetTxHashFromPath()
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	status, err := p.childChain.GetTxStatus(txn, txHash)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	if status.State == core.TxStateIncluded {
+		blkNum, _ := types.ParseTxPosition(status.Position)
+
+		pb, err := p.rootChain.PlasmaBlocks(blkNum)
+		if err != nil {
+			return c.JSONError(err)
+		}
+		if pb.IsCommitted() {
+			status.State = core.TxStateCommitted
+		}
+	}
+
+	return c.JSONSuccess(status)
+}
+
+func (p *Plasma) GetTxFinalityHandler(c *Context) error {
+	txPos, err := c.GetTxPositionFromPath()
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	tx, status, err := p.childChain.GetTxWithStatusByPosition(txn, txPos)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	f, err := p.txFinality(c, status, tx)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	return c.JSONSuccess(f)
+}
+
+func (p *Plasma) GetTxFinalityByHashHandler(c *Context) error {
+	txHash, err := c.GetTxHashFromPath()
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	tx, status, err := p.childChain.GetTxWithStatus(txn, txHash)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	f, err := p.txFinality(c, status, tx)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	return c.JSONSuccess(f)
+}
+
+// txFinality looks up whether the block of the tx is committed to the root chain
+// and how many root chain blocks have been mined on top of the commitment.
+func (p *Plasma) txFinality(c *Context, status *core.TxStatus, tx *types.Tx) (*core.TxFinality, error) {
+	f := core.NewTxFinality(status, tx)
+	if status.State != core.TxStateIncluded {
+		return f, nil
+	}
+
+	pb, err := p.rootChain.PlasmaBlocks(f.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !pb.IsCommitted() {
+		return f, nil
+	}
+
+	f.State = core.TxStateCommitted
+	f.IsCommitted = true
+
+	ctx := c.Request().Context()
+
+	commitRootBlkNum, err := p.rootChain.PlasmaBlockCommitmentBlockNumber(ctx, f.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	rootBlkNum, err := p.rootChain.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if rootBlkNum >= commitRootBlkNum {
+		f.Confirmations = rootBlkNum - commitRootBlkNum + 1
+	}
+
+	return f, nil
+}
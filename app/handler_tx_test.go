@@ -0,0 +1,121 @@
+package app
+
+import (
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlasma_PostTxValidationHandler(t *testing.T) {
+	p, closePlasma := newTestPlasma(t, Config{})
+	defer closePlasma()
+
+	a, b := newTestAccount(t), newTestAccount(t)
+	depositBlkNum := addTestDeposit(t, p, a, 100)
+
+	// the deposit of a signed by b and sending more than it holds
+	tx := newTestSpendingTx(t, types.NewTxOutPosition(depositBlkNum, 0, 0), b, types.NewTxOut(b.Address(), big.NewInt(101)))
+	txBytes, err := rlp.EncodeToBytes(tx)
+	require.NoError(t, err)
+
+	var result struct {
+		IsValid bool     `json:"valid"`
+		SigErrs []*Error `json:"sigerrs"`
+		Errs    []*Error `json:"errors"`
+	}
+	state := doTestRequest(t, p, http.MethodPost, "/txes/validate", url.Values{
+		"tx": {utils.EncodeToHex(txBytes)},
+	}, nil, &result)
+	require.Equal(t, ResponseStateSuccess, state)
+	require.False(t, result.IsValid)
+	require.Empty(t, result.SigErrs)
+	require.Len(t, result.Errs, 2)
+	for _, appErr := range result.Errs {
+		require.NotNil(t, appErr)
+	}
+	require.Equal(t, ErrInvalidTxSignature.Code, result.Errs[0].Code)
+	require.Equal(t, types.NewTxOutPosition(depositBlkNum, 0, 0), result.Errs[0].Detail.Position)
+	require.Equal(t, ErrInvalidTxBalance.Code, result.Errs[1].Code)
+}
+
+func TestConvertValidationError(t *testing.T) {
+	p, closePlasma := newTestPlasma(t, Config{})
+	defer closePlasma()
+
+	c := NewContext(p.server.NewContext(nil, nil))
+
+	// a core error without its own API error
+	appErr := convertValidationError(c, core.ErrStateMachineStopped)
+	require.Equal(t, CoreErrorCode, appErr.Code)
+	require.Equal(t, "state_machine_stopped", appErr.Detail.Reason)
+
+	// not a child chain error at all
+	require.Equal(t, ErrUnexpected, convertValidationError(c, rlp.ErrExpectedList))
+}
+
+func TestPlasma_PostTxHandler(t *testing.T) {
+	p, closePlasma := newTestPlasma(t, Config{})
+	defer closePlasma()
+
+	a, b := newTestAccount(t), newTestAccount(t)
+	depositBlkNum := addTestDeposit(t, p, a, 100)
+
+	tx := newTestSpendingTx(t, types.NewTxOutPosition(depositBlkNum, 0, 0), a, types.NewTxOut(b.Address(), big.NewInt(100)))
+	txBytes, err := rlp.EncodeToBytes(tx)
+	require.NoError(t, err)
+	txHash, err := tx.Hash()
+	require.NoError(t, err)
+
+	var result struct {
+		Hash string `json:"hash"`
+	}
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodPost, "/txes", url.Values{
+		"tx": {utils.EncodeToHex(txBytes)},
+	}, nil, &result))
+	require.Equal(t, utils.HashToHex(txHash), result.Hash)
+
+	// the returned hash finds the tx in the mempool
+	var status core.TxStatus
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodGet, "/txes/hash/"+result.Hash, nil, nil, &status))
+	require.Equal(t, core.TxStatePending, status.State)
+
+	// submitted twice
+	var appErr Error
+	require.Equal(t, ResponseStateError, doTestRequest(t, p, http.MethodPost, "/txes", url.Values{
+		"tx": {utils.EncodeToHex(txBytes)},
+	}, nil, &appErr))
+	require.Equal(t, ErrTxOutAlreadySpent.Code, appErr.Code)
+}
+
+func TestPlasma_GetTxFinalityByHashHandler(t *testing.T) {
+	p, closePlasma := newTestPlasma(t, Config{})
+	defer closePlasma()
+
+	a, b := newTestAccount(t), newTestAccount(t)
+	depositBlkNum := addTestDeposit(t, p, a, 100)
+
+	tx := newTestSpendingTx(t, types.NewTxOutPosition(depositBlkNum, 0, 0), a, types.NewTxOut(b.Address(), big.NewInt(100)))
+	txHash, err := tx.Hash()
+	require.NoError(t, err)
+	require.NoError(t, p.update(func(txn core.Txn) error {
+		return p.childChain.AddTxToMempool(txn, tx)
+	}))
+
+	// a pending tx is reported without asking the root chain
+	var f core.TxFinality
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodGet,
+		"/txes/hash/"+utils.HashToHex(txHash)+"/finality", nil, nil, &f))
+	require.Equal(t, core.TxStatePending, f.State)
+	require.False(t, f.IsCommitted)
+	require.Len(t, f.Inputs, 1)
+	require.False(t, f.Inputs[0].IsConfirmed)
+	require.Len(t, f.Outputs, 1)
+	require.Equal(t, types.Position(0), f.Outputs[0].Position)
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+func (p *Plasma) GetTxOutExitDataHandler(c *Context) error {
+	txOutPos, err := c.GetTxOutPositionFromPath()
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	exitData, err := p.childChain.GetExitData(txn, txOutPos)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	bond, err := p.rootChain.ExitBond()
+	if err != nil {
+		return c.JSONError(err)
+	}
+	exitData.Bond = bond
+
+	return c.JSONSuccess(exitData)
+}
+
+func (p *Plasma) GetTxOutSpenderHandler(c *Context) error {
+	txOutPos, err := c.GetTxOutPositionFromPath()
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	// BEGIN RO TXN
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	spender, err := p.childChain.GetSpender(txn, txOutPos)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	txBytes, err := rlp.EncodeToBytes(spender.Tx)
+	if err != nil {
+		return c.JSONError(err)
+	}
+
+	return c.JSONSuccess(map[string]interface{}{
+		"pos":     spender.TxOutPosition,
+		"vspos":   spender.TxInPosition,
+		"txpos":   spender.TxPosition,
+		"index":   spender.InputIndex,
+		"tx":      utils.EncodeToHex(txBytes),
+		"confsig": spender.ConfirmationSignature,
+	})
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlasma_GetTxOutExitDataHandler(t *testing.T) {
+	p, closePlasma := newTestPlasma(t, Config{})
+	defer closePlasma()
+
+	a := newTestAccount(t)
+	depositBlkNum := addTestDeposit(t, p, a, 100)
+
+	// the null output of the deposit tx cannot be exited
+	nullTxOutPos := types.NewTxOutPosition(depositBlkNum, 0, 1)
+	var appErr Error
+	require.Equal(t, ResponseStateError, doTestRequest(t, p, http.MethodGet,
+		fmt.Sprintf("/txouts/%d/exitdata", nullTxOutPos), nil, nil, &appErr))
+	require.Equal(t, ErrTxOutNotFound.Code, appErr.Code)
+	require.Equal(t, nullTxOutPos, appErr.Detail.Position)
+}
+
+func TestPlasma_GetTxOutSpenderHandler(t *testing.T) {
+	p, closePlasma := newTestPlasma(t, Config{})
+	defer closePlasma()
+
+	a, b := newTestAccount(t), newTestAccount(t)
+	depositBlkNum := addTestDeposit(t, p, a, 100)
+	depositTxOutPos := types.NewTxOutPosition(depositBlkNum, 0, 0)
+	path := fmt.Sprintf("/txouts/%d/spender", depositTxOutPos)
+
+	var appErr Error
+	require.Equal(t, ResponseStateError, doTestRequest(t, p, http.MethodGet, path, nil, nil, &appErr))
+	require.Equal(t, ErrTxOutNotSpent.Code, appErr.Code)
+
+	blkNum := addTestBlock(t, p, a,
+		newTestSpendingTx(t, depositTxOutPos, a, types.NewTxOut(b.Address(), big.NewInt(100))),
+	)
+
+	var result struct {
+		TxInPosition types.Position `json:"vspos"`
+		TxPosition   types.Position `json:"txpos"`
+		InputIndex   uint64         `json:"index"`
+	}
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodGet, path, nil, nil, &result))
+	require.Equal(t, types.NewTxInPosition(blkNum, 0, 0), result.TxInPosition)
+	require.Equal(t, types.NewTxPosition(blkNum, 0), result.TxPosition)
+	require.Equal(t, uint64(0), result.InputIndex)
+}
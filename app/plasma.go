@@ -3,10 +3,14 @@ package app
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
+	"net/http/httputil"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/dgraph-io/badger"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
 	"github.com/labstack/gommon/log"
@@ -24,8 +28,23 @@ type Plasma struct {
 	operator          *types.Account
 	rootChain         *core.RootChain
 	childChain        *core.ChildChain
+	stateMachine      *core.StateMachine
+	rootMu            sync.Mutex // serializes sending block roots
 	heartbeater       *Heartbeater
 	heartbeatInterval time.Duration
+	mempoolEvicter    *Heartbeater
+	mempoolTTL        time.Duration
+	mempoolInterval   time.Duration
+	writesStopped     int32 // accessed atomically
+	blockSource       BlockSource
+	forwarder         *httputil.ReverseProxy
+	follower          *Heartbeater
+	followerInterval  time.Duration
+	archiver          *core.BlockArchiver
+	dbCollector       *Heartbeater
+	dbGCInterval      time.Duration
+	pruner            *Heartbeater
+	pruneInterval     time.Duration
 }
 
 func NewPlasma(conf Config) (*Plasma, error) {
@@ -37,13 +56,24 @@ func NewPlasma(conf Config) (*Plasma, error) {
 	if err := p.initDB(); err != nil {
 		return nil, err
 	}
+	if conf.Archive.IsEnabled() {
+		if err := p.initArchiver(); err != nil {
+			return nil, err
+		}
+	}
 	if err := p.initRootChain(); err != nil {
 		return nil, err
 	}
-	if err := p.initOperator(); err != nil {
+	// a follower does not hold the operator key
+	if !conf.Follower.IsEnabled {
+		if err := p.initOperator(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.initChildChain(); err != nil {
 		return nil, err
 	}
-	p.initChildChain()
+	p.initStateMachine()
 
 	if conf.Heartbeat.IsEnabled {
 		if err := p.initHeartbeater(); err != nil {
@@ -54,6 +84,49 @@ func NewPlasma(conf Config) (*Plasma, error) {
 		}
 	}
 
+	if conf.Follower.IsEnabled {
+		if err := p.initFollower(); err != nil {
+			return nil, err
+		}
+		if err := p.initFollowerInterval(); err != nil {
+			return nil, err
+		}
+		if err := p.initForwarder(); err != nil {
+			return nil, err
+		}
+		p.initBlockSource()
+	}
+
+	if conf.Mempool.IsEvictionEnabled() {
+		if err := p.initMempoolEvicter(); err != nil {
+			return nil, err
+		}
+		if err := p.initMempoolTTL(); err != nil {
+			return nil, err
+		}
+		if err := p.initMempoolInterval(); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.DB.IsGCEnabled() {
+		if err := p.initDBCollector(); err != nil {
+			return nil, err
+		}
+		if err := p.initDBGCInterval(); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.Prune.IsEnabled() {
+		if err := p.initPruner(); err != nil {
+			return nil, err
+		}
+		if err := p.initPruneInterval(); err != nil {
+			return nil, err
+		}
+	}
+
 	return p, nil
 }
 
@@ -66,9 +139,24 @@ func (p *Plasma) initServer() {
 			return h(&Context{c})
 		}
 	})
+	p.server.Use(func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Method != http.MethodGet && p.isWritesStopped() {
+				return NewContext(c).JSONError(ErrBlockchainDrifted)
+			}
+			return h(c)
+		}
+	})
+	if p.config.Follower.IsEnabled {
+		p.server.Use(p.followerMiddleware)
+	}
 	p.server.HTTPErrorHandler = p.httpErrorHandler
 	p.server.Logger.SetLevel(log.INFO)
 	p.initRoutes()
+
+	if p.config.Archive.IsEnabled() {
+		p.server.Static("/archive", p.config.Archive.Dir)
+	}
 }
 
 func (p *Plasma) initDB() error {
@@ -80,16 +168,45 @@ func (p *Plasma) initDB() error {
 	return nil
 }
 
+func (p *Plasma) initArchiver() error {
+	archiver, err := core.NewBlockArchiver(p.config.Archive.Dir)
+	if err != nil {
+		return err
+	}
+	p.archiver = archiver
+	return nil
+}
+
 func (p *Plasma) initRoutes() {
 	p.GET("/ping", p.PingHandler)
+	p.GET("/addresses/:address/balance", p.GetAddressBalanceHandler)
 	p.GET("/addresses/:address/utxos", p.GetAddressUTXOsHandler)
+	p.GET("/addresses/:address/txes", p.GetAddressTxesHandler)
 	p.POST("/blocks", p.PostBlockHandler)
 	p.GET("/blocks/:blkNum", p.GetBlockHandler)
 	p.POST("/txes", p.PostTxHandler)
+	p.POST("/txes/validate", p.PostTxValidationHandler)
+	p.GET("/txes/hash/:txHash", p.GetTxStatusHandler)
+	p.GET("/txes/hash/:txHash/finality", p.GetTxFinalityByHashHandler)
 	p.GET("/txes/:txPos", p.GetTxHandler)
+	p.GET("/txes/:txPos/finality", p.GetTxFinalityHandler)
 	p.GET("/txes/:txPos/proof", p.GetTxProofHandler)
+	p.GET("/txouts/:txOutPos/exitdata", p.GetTxOutExitDataHandler)
+	p.GET("/txouts/:txOutPos/spender", p.GetTxOutSpenderHandler)
 	p.PUT("/txins/:txInPos", p.PutTxInHandler)
 	p.POST("/deposits", p.PostDepositHandler)
+	p.GET("/mempool", p.GetMempoolHandler)
+	p.GET("/mempool/:txHash", p.GetMempoolTxHandler)
+	p.DELETE("/mempool/:txHash", p.DeleteMempoolTxHandler)
+
+	if p.config.Admin.IsEnabled() {
+		p.GET("/admin/backup", p.GetBackupHandler, p.adminMiddleware)
+		p.GET("/admin/export", p.GetExportHandler, p.adminMiddleware)
+		p.GET("/admin/db", p.GetDBStatsHandler, p.adminMiddleware)
+		p.GET("/consistency", p.GetConsistencyHandler, p.adminMiddleware)
+		p.GET("/admin/audit", p.GetAuditHandler, p.adminMiddleware)
+		p.GET("/fraud", p.GetFraudHandler, p.adminMiddleware)
+	}
 }
 
 func (p *Plasma) initRootChain() error {
@@ -111,14 +228,24 @@ func (p *Plasma) initOperator() error {
 }
 
 func (p *Plasma) initChildChain() error {
-	return p.db.Update(func(txn *badger.Txn) error {
-		cc, err := core.NewChildChain(txn)
-		if err != nil {
-			return err
-		}
-		p.childChain = cc
-		return nil
-	})
+	txn := p.db.NewTransaction(true)
+
+	cc, err := core.NewChildChain(txn)
+	if err != nil {
+		txn.Discard()
+		return err
+	}
+	if err := cc.Commit(txn); err != nil {
+		return err
+	}
+
+	p.childChain = cc
+
+	return nil
+}
+
+func (p *Plasma) initStateMachine() {
+	p.stateMachine = core.NewStateMachine(p.db, p.childChain)
 }
 
 func (p *Plasma) initHeartbeater() error {
@@ -139,6 +266,109 @@ func (p *Plasma) initHeartbeatInterval() error {
 	return nil
 }
 
+func (p *Plasma) initMempoolEvicter() error {
+	evicter, err := NewHeartbeater(p.evictExpiredMempoolTxes)
+	if err != nil {
+		return err
+	}
+	p.mempoolEvicter = evicter
+	return nil
+}
+
+func (p *Plasma) initMempoolTTL() error {
+	ttl, err := p.config.Mempool.TTL()
+	if err != nil {
+		return err
+	}
+	p.mempoolTTL = ttl
+	return nil
+}
+
+func (p *Plasma) initMempoolInterval() error {
+	interval, err := p.config.Mempool.Interval()
+	if err != nil {
+		return err
+	}
+	p.mempoolInterval = interval
+	return nil
+}
+
+func (p *Plasma) initDBCollector() error {
+	// only badger has a value log
+	if p.db.Engine() != DBEngineBadger {
+		return ErrUnsupportedDBEngine
+	}
+
+	collector, err := NewHeartbeater(p.runValueLogGC)
+	if err != nil {
+		return err
+	}
+	p.dbCollector = collector
+	return nil
+}
+
+func (p *Plasma) initDBGCInterval() error {
+	interval, err := p.config.DB.GCInterval()
+	if err != nil {
+		return err
+	}
+	p.dbGCInterval = interval
+	return nil
+}
+
+func (p *Plasma) initPruner() error {
+	pruner, err := NewHeartbeater(p.pruneTxes)
+	if err != nil {
+		return err
+	}
+	p.pruner = pruner
+	return nil
+}
+
+func (p *Plasma) initPruneInterval() error {
+	interval, err := p.config.Prune.Interval()
+	if err != nil {
+		return err
+	}
+	p.pruneInterval = interval
+	return nil
+}
+
+func (p *Plasma) initFollower() error {
+	follower, err := NewHeartbeater(p.syncBlocks)
+	if err != nil {
+		return err
+	}
+	p.follower = follower
+	return nil
+}
+
+func (p *Plasma) initFollowerInterval() error {
+	interval, err := p.config.Follower.Interval()
+	if err != nil {
+		return err
+	}
+	p.followerInterval = interval
+	return nil
+}
+
+func (p *Plasma) initForwarder() error {
+	forwarder, err := newForwarder(p.config.Follower.API)
+	if err != nil {
+		return err
+	}
+	p.forwarder = forwarder
+	return nil
+}
+
+func (p *Plasma) initBlockSource() {
+	if p.config.Follower.Archive != "" {
+		p.blockSource = core.NewBlockArchiveReader(p.config.Follower.Archive)
+	} else {
+		p.blockSource = newAPIBlockSource(p.config.Follower.API)
+	}
+}
+
 func (p *Plasma) GET(path string, h HandlerFunc, m ...echo.MiddlewareFunc) {
 	p.Add(http.MethodGet, path, h, m...)
 }
@@ -151,10 +381,14 @@ func (p *Plasma) PUT(path string, h HandlerFunc, m ...echo.MiddlewareFunc) {
 	p.Add(http.MethodPut, path, h, m...)
 }
 
+func (p *Plasma) DELETE(path string, h HandlerFunc, m ...echo.MiddlewareFunc) {
+	p.Add(http.MethodDelete, path, h, m...)
+}
+
 func (p *Plasma) Add(method, path string, h HandlerFunc, m ...echo.MiddlewareFunc) {
 	p.server.Add(method, path, func(c echo.Context) error {
 		return h(NewContext(c))
-	})
+	}, m...)
 }
 
 func (p *Plasma) Logger() echo.Logger {
@@ -162,9 +396,33 @@ func (p *Plasma) Logger() echo.Logger {
 }
 
 func (p *Plasma) Start() error {
-	// watch DepositCreated events
-	if err := p.watchDepositCreated(); err != nil {
-		return err
+	if p.config.Archive.IsEnabled() {
+		// archive blocks stored before the archive was enabled
+		if err := p.archiveBlocks(); err != nil {
+			return err
+		}
+	}
+
+	if p.config.Follower.IsEnabled {
+		// replicate blocks of operator
+		if err := p.follow(); err != nil {
+			return err
+		}
+	} else {
+		// send the root left unsent before the restart
+		if err := p.commitBlockRoot(); err != nil {
+			return err
+		}
+
+		// compare stored blocks with root chain
+		if _, err := p.checkConsistency(); err != nil {
+			return err
+		}
+
+		// watch DepositCreated events
+		if err := p.watchDepositCreated(); err != nil {
+			return err
+		}
 	}
 
 	// watch ExitStarted events
@@ -179,6 +437,27 @@ func (p *Plasma) Start() error {
 		}
 	}
 
+	if p.config.Mempool.IsEvictionEnabled() {
+		// evict expired txes from mempool
+		if err := p.evictMempool(); err != nil {
+			return err
+		}
+	}
+
+	if p.config.DB.IsGCEnabled() {
+		// reclaim space of value log
+		if err := p.collectDBGarbage(); err != nil {
+			return err
+		}
+	}
+
+	if p.config.Prune.IsEnabled() {
+		// drop bodies of old spent txes
+		if err := p.prune(); err != nil {
+			return err
+		}
+	}
+
 	// start HTTP server
 	return p.server.Start(fmt.Sprintf(":%d", p.config.Port))
 }
@@ -188,11 +467,160 @@ func (p *Plasma) Shutdown(ctx context.Context) error {
 }
 
 func (p *Plasma) Finalize() {
-	p.db.Close()
-
 	if p.config.Heartbeat.IsEnabled {
 		p.heartbeater.Stop()
 	}
+
+	if p.config.Mempool.IsEvictionEnabled() {
+		p.mempoolEvicter.Stop()
+	}
+
+	if p.config.Follower.IsEnabled {
+		p.follower.Stop()
+	}
+
+	if p.config.DB.IsGCEnabled() {
+		p.dbCollector.Stop()
+	}
+
+	if p.config.Prune.IsEnabled() {
+		p.pruner.Stop()
+	}
+
+	p.stateMachine.Stop()
+	p.db.Close()
+}
+
+// commitBlockRoot sends the root of the block whose root is yet to be sent, if any, to the root chain.
+// A root the root chain already has, such as one sent by a request whose response was lost, is not sent again.
+func (p *Plasma) commitBlockRoot() error {
+	p.rootMu.Lock()
+	defer p.rootMu.Unlock()
+
+	var blkNum uint64
+	var blkRootHash common.Hash
+	if err := p.db.View(func(txn core.Txn) error {
+		var err error
+		blkNum, err = p.childChain.GetUnsentRootBlockNumber(txn)
+		if err != nil || blkNum == 0 {
+			return err
+		}
+
+		blkRootHash, err = p.childChain.GetBlockRoot(txn, blkNum)
+		return err
+	}); err != nil {
+		return err
+	}
+	if blkNum == 0 {
+		return nil
+	}
+
+	rootBlkNum, err := p.rootChain.CurrentPlasmaBlockNumber()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case rootBlkNum == blkNum:
+		if _, err := p.rootChain.CommitPlasmaBlockRoot(p.operator, blkRootHash); err != nil {
+			return err
+		}
+		p.Logger().Infof("[COMMIT] root: %s", utils.HashToHex(blkRootHash))
+	case rootBlkNum > blkNum:
+		pb, err := p.rootChain.PlasmaBlocks(blkNum)
+		if err != nil {
+			return err
+		}
+		if pb.RootHash() != blkRootHash {
+			return ErrBlockchainNotSynchronized
+		}
+	default:
+		return ErrBlockchainNotSynchronized
+	}
+
+	return p.update(func(txn core.Txn) error {
+		return p.childChain.ClearUnsentRootBlockNumber(txn, blkNum)
+	})
+}
+
+// checkConsistency compares the stored blocks with the root chain and logs the drift if any.
+// Writes are stopped on drift if configured so, and resumed once the blocks are consistent again.
+func (p *Plasma) checkConsistency() (*core.ConsistencyReport, error) {
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	report, err := p.childChain.CheckConsistency(txn, p.rootChain)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.IsConsistent() {
+		atomic.StoreInt32(&p.writesStopped, 0)
+		return report, nil
+	}
+
+	if report.BlockNumber != report.RootBlockNumber {
+		p.Logger().Warnf(
+			"[DRIFT] blkNum: %d, rootBlkNum: %d",
+			report.BlockNumber,
+			report.RootBlockNumber,
+		)
+	}
+	for _, drift := range report.Drifts {
+		p.Logger().Warnf(
+			"[DRIFT] blkNum: %d, root: %s, committed: %s",
+			drift.BlockNumber,
+			utils.HashToHex(drift.Root),
+			utils.HashToHex(drift.CommittedRoot),
+		)
+	}
+
+	if p.config.Consistency.IsStopWritesEnabled {
+		atomic.StoreInt32(&p.writesStopped, 1)
+	}
+
+	return report, nil
+}
+
+func (p *Plasma) isWritesStopped() bool {
+	return atomic.LoadInt32(&p.writesStopped) == 1
+}
+
+// archiveBlocks archives the stored blocks that are not archived yet.
+func (p *Plasma) archiveBlocks() error {
+	if p.archiver == nil {
+		return nil
+	}
+
+	txn := p.db.NewTransaction(false)
+	defer txn.Discard()
+
+	currentBlkNum, err := p.childChain.GetCurrentBlockNumber(txn)
+	if err != nil {
+		return err
+	}
+
+	blkNum := p.archiver.LastBlockNumber() + 1
+	if blkNum < core.FirstBlockNumber {
+		blkNum = core.FirstBlockNumber
+	}
+	for ; blkNum < currentBlkNum; blkNum++ {
+		blk, err := p.childChain.GetBlock(txn, blkNum)
+		if err == core.ErrBlockPruned {
+			// only possible if the store was pruned before the archive was enabled
+			return fmt.Errorf("block %d is pruned before being archived, so the archive has to be copied from another node", blkNum)
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.archiver.Put(blk); err != nil {
+			return err
+		}
+
+		p.Logger().Infof("[ARCHIVE] blkNum: %d", blkNum)
+	}
+
+	return nil
 }
 
 func (p *Plasma) watchDepositCreated() error {
@@ -203,22 +631,31 @@ func (p *Plasma) watchDepositCreated() error {
 
 	go func() {
 		for log := range sink {
-			if err := p.db.Update(func(txn *badger.Txn) error {
-				newBlkNum, err := p.childChain.AddDepositBlock(txn, log.Owner, log.Amount, p.operator)
-				if err != nil {
-					p.Logger().Error(err)
-				} else {
-					p.Logger().Infof(
-						"[DEPOSIT] owner: %s, amount: %d, blkNum: %d, txPos: %d",
-						utils.AddressToHex(log.Owner),
-						log.Amount,
-						newBlkNum,
-						types.NewTxPosition(newBlkNum, 0),
-					)
-				}
-				return nil
+			var newBlkNum uint64
+			var evictedTxHashes []common.Hash
+			if err := p.update(func(txn core.Txn) error {
+				var err error
+				newBlkNum, evictedTxHashes, err = p.childChain.AddDepositBlock(txn, log.Owner, log.Amount, p.operator)
+				return err
 			}); err != nil {
 				p.Logger().Error(err)
+				continue
+			}
+
+			for _, txHash := range evictedTxHashes {
+				p.Logger().Infof("[EVICT] txHash: %s", utils.HashToHex(txHash))
+			}
+
+			p.Logger().Infof(
+				"[DEPOSIT] owner: %s, amount: %d, blkNum: %d, txPos: %d",
+				utils.AddressToHex(log.Owner),
+				log.Amount,
+				newBlkNum,
+				types.NewTxPosition(newBlkNum, 0),
+			)
+
+			if err := p.archiveBlocks(); err != nil {
+				p.Logger().Error(err)
 			}
 		}
 	}()
@@ -234,22 +671,20 @@ func (p *Plasma) watchExitStarted() error {
 
 	go func() {
 		for log := range sink {
-			if err := p.db.Update(func(txn *badger.Txn) error {
-				txOutPos := types.Position(log.UtxoPosition.Uint64())
-				if err := p.childChain.ExitTxOut(txn, txOutPos); err != nil {
-					p.Logger().Error(err)
-				} else {
-					p.Logger().Infof(
-						"[EXIT] owner: %s, amount: %d, txOutPos: %d",
-						utils.AddressToHex(log.Owner),
-						log.Amount,
-						txOutPos,
-					)
-				}
-				return nil
+			txOutPos := types.Position(log.UtxoPosition.Uint64())
+			if err := p.update(func(txn core.Txn) error {
+				return p.childChain.ExitTxOut(txn, txOutPos)
 			}); err != nil {
 				p.Logger().Error(err)
+				continue
 			}
+
+			p.Logger().Infof(
+				"[EXIT] owner: %s, amount: %d, txOutPos: %d",
+				utils.AddressToHex(log.Owner),
+				log.Amount,
+				txOutPos,
+			)
 		}
 	}()
 
@@ -274,6 +709,135 @@ func (p *Plasma) heartbeat() error {
 	return nil
 }
 
+func (p *Plasma) follow() error {
+	go func() {
+		for {
+			ok, err := p.follower.Beat()
+			if err != nil {
+				p.Logger().Error(err)
+			}
+			if !ok {
+				return
+			}
+
+			time.Sleep(p.followerInterval)
+		}
+	}()
+
+	return nil
+}
+
+func (p *Plasma) evictMempool() error {
+	go func() {
+		for {
+			ok, err := p.mempoolEvicter.Beat()
+			if err != nil {
+				p.Logger().Error(err)
+			}
+			if !ok {
+				return
+			}
+
+			time.Sleep(p.mempoolInterval)
+		}
+	}()
+
+	return nil
+}
+
+func (p *Plasma) collectDBGarbage() error {
+	go func() {
+		for {
+			ok, err := p.dbCollector.Beat()
+			if err != nil {
+				p.Logger().Error(err)
+			}
+			if !ok {
+				return
+			}
+
+			time.Sleep(p.dbGCInterval)
+		}
+	}()
+
+	return nil
+}
+
+func (p *Plasma) runValueLogGC() error {
+	n, err := p.db.RunValueLogGC(p.config.DB.GCDiscardRatio)
+	if err != nil {
+		return err
+	}
+
+	if n > 0 {
+		lsmSize, vlogSize, err := p.db.Size()
+		if err != nil {
+			return err
+		}
+		p.Logger().Infof("[GC] rewritten: %d, lsm: %d, vlog: %d", n, lsmSize, vlogSize)
+	}
+
+	return nil
+}
+
+func (p *Plasma) prune() error {
+	go func() {
+		for {
+			ok, err := p.pruner.Beat()
+			if err != nil {
+				p.Logger().Error(err)
+			}
+			if !ok {
+				return
+			}
+
+			time.Sleep(p.pruneInterval)
+		}
+	}()
+
+	return nil
+}
+
+func (p *Plasma) pruneTxes() error {
+	// the blocks not archived yet are kept whole, so that they can still be archived
+	lastBlkNum := uint64(math.MaxUint64)
+	if p.archiver != nil {
+		lastBlkNum = p.archiver.LastBlockNumber()
+	}
+
+	var n int
+	if err := p.update(func(txn core.Txn) error {
+		var err error
+		n, err = p.childChain.Prune(txn, p.config.Prune.Depth, lastBlkNum)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if n > 0 {
+		p.Logger().Infof("[PRUNE] txes: %d", n)
+	}
+
+	return nil
+}
+
+func (p *Plasma) evictExpiredMempoolTxes() error {
+	var txHashes []common.Hash
+	if err := p.update(func(txn core.Txn) error {
+		var err error
+		txHashes, err = p.childChain.EvictExpiredTxesFromMempool(txn, time.Now().Add(-p.mempoolTTL))
+		return err
+	}); err != nil {
+		return err
+	}
+
+	for _, txHash := range txHashes {
+		p.Logger().Infof("[EVICT] txHash: %s", utils.HashToHex(txHash))
+	}
+
+	return nil
+}
+
 func (p *Plasma) httpErrorHandler(err error, c echo.Context) {
 	p.Logger().Error(err)
 
@@ -291,3 +855,9 @@ func (p *Plasma) httpErrorHandler(err error, c echo.Context) {
 		p.Logger().Error(err)
 	}
 }
+
+// update applies fn to the child chain through the state machine,
+// which serializes all mutations so that they never conflict.
+func (p *Plasma) update(fn core.Command) error {
+	return p.stateMachine.Apply(fn)
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPlasma builds a node on an in-memory store without connecting to the root chain,
+// so only the handlers that do not use the root chain can be tested with it.
+func newTestPlasma(t *testing.T, conf Config) (*Plasma, func()) {
+	if conf.DB.Engine == "" {
+		conf.DB.Engine = DBEngineMemory
+	}
+
+	p := &Plasma{
+		config: conf,
+	}
+	require.NoError(t, p.initDB())
+	require.NoError(t, p.initChildChain())
+	p.initStateMachine()
+	p.initServer()
+
+	return p, func() {
+		p.stateMachine.Stop()
+		p.db.Close()
+	}
+}
+
+// doTestRequest serves the request and decodes the result of the response into result.
+func doTestRequest(t *testing.T, p *Plasma, method, path string, form url.Values, header http.Header, result interface{}) string {
+	req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	p.server.ServeHTTP(rec, req)
+
+	var resp struct {
+		State  string          `json:"state"`
+		Result json.RawMessage `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	if result != nil {
+		require.NoError(t, json.Unmarshal(resp.Result, result))
+	}
+
+	return resp.State
+}
+
+func newTestAccount(t *testing.T) *types.Account {
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	return types.NewAccount(privKey)
+}
+
+// addTestDeposit adds a deposit block of amount to owner signed by the owner.
+func addTestDeposit(t *testing.T, p *Plasma, owner *types.Account, amount int64) uint64 {
+	var blkNum uint64
+	require.NoError(t, p.update(func(txn core.Txn) error {
+		var err error
+		blkNum, _, err = p.childChain.AddDepositBlock(txn, owner.Address(), big.NewInt(amount), owner)
+		return err
+	}))
+
+	return blkNum
+}
+
+// addTestBlock adds txes to the mempool and includes them in a block signed by signer.
+func addTestBlock(t *testing.T, p *Plasma, signer *types.Account, txes ...*types.Tx) uint64 {
+	var blkNum uint64
+	require.NoError(t, p.update(func(txn core.Txn) error {
+		for _, tx := range txes {
+			if err := p.childChain.AddTxToMempool(txn, tx); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		blkNum, err = p.childChain.AddBlock(txn, signer)
+		return err
+	}))
+
+	return blkNum
+}
+
+// newTestSpendingTx spends the txout at txOutPos signed by signer.
+func newTestSpendingTx(t *testing.T, txOutPos types.Position, signer *types.Account, txOuts ...*types.TxOut) *types.Tx {
+	tx := types.NewTx()
+	require.NoError(t, tx.SetInput(0, types.NewTxIn(types.ParseTxOutPosition(txOutPos))))
+	for i, txOut := range txOuts {
+		require.NoError(t, tx.SetOutput(uint64(i), txOut))
+	}
+	require.NoError(t, tx.Sign(0, signer))
+
+	return tx
+}
+
+func TestPlasma_PruneTxes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mmpc-archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	p, closePlasma := newTestPlasma(t, Config{
+		Archive: ArchiveConfig{
+			Dir: dir,
+		},
+		Prune: PruneConfig{
+			Depth: 1,
+		},
+	})
+	defer closePlasma()
+	require.NoError(t, p.initArchiver())
+
+	a, b := newTestAccount(t), newTestAccount(t)
+	depositBlkNum := addTestDeposit(t, p, a, 100)
+	addTestBlock(t, p, a, newTestSpendingTx(t, types.NewTxOutPosition(depositBlkNum, 0, 0), a, types.NewTxOut(b.Address(), big.NewInt(100))))
+	addTestDeposit(t, p, b, 1)
+	addTestDeposit(t, p, b, 1)
+
+	path := fmt.Sprintf("/blocks/%d", depositBlkNum)
+
+	// nothing is archived yet
+	require.NoError(t, p.pruneTxes())
+	require.Equal(t, ResponseStateSuccess, doTestRequest(t, p, http.MethodGet, path, nil, nil, nil))
+
+	require.NoError(t, p.archiveBlocks())
+	require.NoError(t, p.pruneTxes())
+
+	var appErr Error
+	require.Equal(t, ResponseStateError, doTestRequest(t, p, http.MethodGet, path, nil, nil, &appErr))
+	require.Equal(t, ErrBlockPruned.Code, appErr.Code)
+
+	// the archive still has the block
+	blk, err := core.NewBlockArchiveReader(dir).GetBlock(context.Background(), depositBlkNum)
+	require.NoError(t, err)
+	require.Equal(t, depositBlkNum, blk.Number)
+}
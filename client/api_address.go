@@ -3,27 +3,79 @@ package client
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"net/http"
+	"net/url"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
 )
 
+type GetAddressBalanceResponse struct {
+	*ResponseBase
+	Result *core.Balance `json:"result"`
+}
+
+func (c *Client) GetAddressBalance(ctx context.Context, addr common.Address) (*core.Balance, error) {
+	return c.GetAddressBalanceAt(ctx, addr, 0)
+}
+
+// GetAddressBalanceAt returns the balance of addr right after the block at blkNum,
+// or the latest balance if blkNum is 0.
+func (c *Client) GetAddressBalanceAt(ctx context.Context, addr common.Address, blkNum uint64) (*core.Balance, error) {
+	v := url.Values{}
+	if blkNum > 0 {
+		v.Set("at", utils.Uint64ToString(blkNum))
+	}
+
+	var resp GetAddressBalanceResponse
+	if err := c.doAPI(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("/addresses/%s/balance", utils.AddressToHex(addr)),
+		v,
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	return resp.Result, nil
+}
+
 type GetAddressUTXOsResponse struct {
 	*ResponseBase
 	Result struct {
-		UTXOs []types.Position `json:"utxos"`
+		UTXOs []*core.UTXO `json:"utxos"`
 	} `json:"result"`
 }
 
-func (c *Client) GetAddressUTXOs(ctx context.Context, addr common.Address) ([]types.Position, error) {
+// GetAddressUTXOs returns the spendable txouts of addr in position order.
+// minAmount and limit are ignored if they are nil and 0 respectively.
+func (c *Client) GetAddressUTXOs(ctx context.Context, addr common.Address, minAmount *big.Int, limit uint64) ([]*core.UTXO, error) {
+	return c.GetAddressUTXOsAt(ctx, addr, 0, minAmount, limit)
+}
+
+// GetAddressUTXOsAt returns the txouts of addr that were unspent right after the block at blkNum,
+// or the spendable txouts if blkNum is 0, filtered like GetAddressUTXOs.
+func (c *Client) GetAddressUTXOsAt(ctx context.Context, addr common.Address, blkNum uint64, minAmount *big.Int, limit uint64) ([]*core.UTXO, error) {
+	v := url.Values{}
+	if blkNum > 0 {
+		v.Set("at", utils.Uint64ToString(blkNum))
+	}
+	if minAmount != nil {
+		v.Set("min", minAmount.String())
+	}
+	if limit > 0 {
+		v.Set("limit", utils.Uint64ToString(limit))
+	}
+
 	var resp GetAddressUTXOsResponse
 	if err := c.doAPI(
 		ctx,
 		http.MethodGet,
 		fmt.Sprintf("/addresses/%s/utxos", utils.AddressToHex(addr)),
-		nil,
+		v,
 		&resp,
 	); err != nil {
 		return nil, err
@@ -31,3 +83,45 @@ func (c *Client) GetAddressUTXOs(ctx context.Context, addr common.Address) ([]ty
 
 	return resp.Result.UTXOs, nil
 }
+
+type GetAddressTxesResponse struct {
+	*ResponseBase
+	Result struct {
+		Txes  []*core.AddressTx `json:"txes"`
+		Total uint64            `json:"total"`
+	} `json:"result"`
+}
+
+// GetAddressTxes returns a page of the tx history of addr, newest first,
+// together with the number of txes matching f before pagination.
+func (c *Client) GetAddressTxes(ctx context.Context, addr common.Address, f core.AddressTxFilter) ([]*core.AddressTx, uint64, error) {
+	v := url.Values{}
+	if f.FromBlockNumber > 0 {
+		v.Set("from", utils.Uint64ToString(f.FromBlockNumber))
+	}
+	if f.ToBlockNumber > 0 {
+		v.Set("to", utils.Uint64ToString(f.ToBlockNumber))
+	}
+	if f.Direction != "" {
+		v.Set("dir", f.Direction)
+	}
+	if f.Offset > 0 {
+		v.Set("offset", utils.Uint64ToString(f.Offset))
+	}
+	if f.Limit > 0 {
+		v.Set("limit", utils.Uint64ToString(f.Limit))
+	}
+
+	var resp GetAddressTxesResponse
+	if err := c.doAPI(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("/addresses/%s/txes", utils.AddressToHex(addr)),
+		v,
+		&resp,
+	); err != nil {
+		return nil, 0, err
+	}
+
+	return resp.Result.Txes, resp.Result.Total, nil
+}
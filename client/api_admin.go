@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+// Backup writes an online backup of the child chain to w and returns the version
+// to take the next incremental backup since. A since of 0 takes a full backup.
+func (c *Client) Backup(ctx context.Context, w io.Writer, since uint64, token string) (uint64, error) {
+	resp, err := c.doAdminStream(ctx, "admin/backup", url.Values{
+		"since": {fmt.Sprintf("%d", since)},
+	}, token)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return 0, err
+	}
+
+	// the trailer is only available once the body is read
+	versionStr := resp.Trailer.Get(app.BackupVersionTrailer)
+	if versionStr == "" {
+		return 0, fmt.Errorf("backup is incomplete")
+	}
+
+	return utils.StringToUint64(versionStr)
+}
+
+// Export writes the blocks and the utxo set of the child chain to w as NDJSON.
+func (c *Client) Export(ctx context.Context, w io.Writer, token string) error {
+	resp, err := c.doAdminStream(ctx, "admin/export", nil, token)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+
+	return err
+}
+
+type GetDBStatsResponse struct {
+	*ResponseBase
+	Result *app.DBStats `json:"result"`
+}
+
+// GetDBStats gets the sizes of the store of the child chain and the number of keys of each kind.
+func (c *Client) GetDBStats(ctx context.Context, token string) (*app.DBStats, error) {
+	var resp GetDBStatsResponse
+	if err := c.doAPIWithHeader(
+		ctx,
+		http.MethodGet,
+		"admin/db",
+		nil,
+		adminHeader(token),
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	return resp.Result, nil
+}
+
+func adminHeader(token string) http.Header {
+	header := http.Header{}
+	header.Set(app.AdminTokenHeader, token)
+
+	return header
+}
+
+// doAdminStream requests a streamed response from the admin API.
+// A JSON response is an error, since streams are never sent as JSON.
+func (c *Client) doAdminStream(ctx context.Context, uri string, params url.Values, token string) (*http.Response, error) {
+	u, err := url.Parse(c.baseURI)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, uri)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = adminHeader(token)
+
+	resp, err := c.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(resp.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		defer resp.Body.Close()
+
+		var resErr ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&resErr); err != nil {
+			return nil, err
+		}
+		if resErr.Result == nil {
+			return nil, fmt.Errorf("unexpected response")
+		}
+		return nil, resErr.Result
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	return resp, nil
+}
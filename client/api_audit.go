@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+)
+
+type GetAuditResponse struct {
+	*ResponseBase
+	Result struct {
+		IsSound bool              `json:"sound"`
+		Report  *core.AuditReport `json:"report"`
+	} `json:"result"`
+}
+
+// GetAudit makes the child chain reconcile its supply with the funds of the root chain contract.
+func (c *Client) GetAudit(ctx context.Context, token string) (*core.AuditReport, error) {
+	var resp GetAuditResponse
+	if err := c.doAPIWithHeader(
+		ctx,
+		http.MethodGet,
+		"admin/audit",
+		nil,
+		adminHeader(token),
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	return resp.Result.Report, nil
+}
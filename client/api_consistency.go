@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+)
+
+type GetConsistencyResponse struct {
+	*ResponseBase
+	Result struct {
+		IsConsistent bool                    `json:"consistent"`
+		Report       *core.ConsistencyReport `json:"report"`
+	} `json:"result"`
+}
+
+// GetConsistency makes the child chain compare its blocks with the root chain.
+func (c *Client) GetConsistency(ctx context.Context, token string) (*core.ConsistencyReport, error) {
+	var resp GetConsistencyResponse
+	if err := c.doAPIWithHeader(
+		ctx,
+		http.MethodGet,
+		"consistency",
+		nil,
+		adminHeader(token),
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	return resp.Result.Report, nil
+}
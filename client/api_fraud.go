@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+)
+
+type GetFraudResponse struct {
+	*ResponseBase
+	Result struct {
+		IsValid bool              `json:"valid"`
+		Report  *core.FraudReport `json:"report"`
+	} `json:"result"`
+}
+
+// GetFraud makes the child chain re-validate the txes of the committed blocks and return evidence of invalid ones.
+func (c *Client) GetFraud(ctx context.Context, token string) (*core.FraudReport, error) {
+	var resp GetFraudResponse
+	if err := c.doAPIWithHeader(
+		ctx,
+		http.MethodGet,
+		"fraud",
+		nil,
+		adminHeader(token),
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	return resp.Result.Report, nil
+}
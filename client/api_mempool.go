@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+type MempoolTxResult struct {
+	HashStr string   `json:"hash"`
+	TxStr   string   `json:"tx"`
+	TxIndex uint64   `json:"txindex"`
+	Fee     *big.Int `json:"fee"`
+	AddedAt uint64   `json:"added"`
+}
+
+func (res MempoolTxResult) MempoolTx() (*core.MempoolTx, error) {
+	txBytes, err := utils.DecodeHex(res.TxStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx types.Tx
+	if err := rlp.DecodeBytes(txBytes, &tx); err != nil {
+		return nil, err
+	}
+
+	return &core.MempoolTx{
+		Tx:      &tx,
+		TxIndex: res.TxIndex,
+		Fee:     res.Fee,
+		AddedAt: res.AddedAt,
+	}, nil
+}
+
+type GetMempoolResponse struct {
+	*ResponseBase
+	Result struct {
+		Txes []MempoolTxResult `json:"txes"`
+	} `json:"result"`
+}
+
+func (c *Client) GetMempool(ctx context.Context) ([]*core.MempoolTx, error) {
+	var resp GetMempoolResponse
+	if err := c.doAPI(
+		ctx,
+		http.MethodGet,
+		"mempool",
+		nil,
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	mtxes := make([]*core.MempoolTx, len(resp.Result.Txes))
+	for i, res := range resp.Result.Txes {
+		mtx, err := res.MempoolTx()
+		if err != nil {
+			return nil, err
+		}
+		mtxes[i] = mtx
+	}
+
+	return mtxes, nil
+}
+
+type GetMempoolTxResponse struct {
+	*ResponseBase
+	Result MempoolTxResult `json:"result"`
+}
+
+func (c *Client) GetMempoolTx(ctx context.Context, txHash common.Hash) (*core.MempoolTx, error) {
+	var resp GetMempoolTxResponse
+	if err := c.doAPI(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("mempool/%s", utils.HashToHex(txHash)),
+		nil,
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	return resp.Result.MempoolTx()
+}
+
+type DeleteMempoolTxResponse struct {
+	*ResponseBase
+	Result struct {
+		RemovedTxHashes []common.Hash `json:"removed"`
+	} `json:"result"`
+}
+
+// DeleteMempoolTx cancels the tx and returns the hashes of all removed txes,
+// which include the txes spending its outputs.
+func (c *Client) DeleteMempoolTx(ctx context.Context, txHash common.Hash, cancelSig types.Signature) ([]common.Hash, error) {
+	v := url.Values{}
+	v.Set("sig", cancelSig.Hex())
+
+	var resp DeleteMempoolTxResponse
+	if err := c.doAPI(
+		ctx,
+		http.MethodDelete,
+		fmt.Sprintf("mempool/%s", utils.HashToHex(txHash)),
+		v,
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	return resp.Result.RemovedTxHashes, nil
+}
@@ -3,23 +3,30 @@ package client
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/url"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
 )
 
 type PostTxResponse struct {
 	*ResponseBase
-	Result struct{} `json:"result"`
+	Result struct {
+		HashStr string `json:"hash"`
+	} `json:"result"`
 }
 
-func (c *Client) PostTx(ctx context.Context, tx *types.Tx) error {
+// PostTx submits tx and returns its hash, by which it can be followed with GetTxStatus.
+func (c *Client) PostTx(ctx context.Context, tx *types.Tx) (common.Hash, error) {
 	txBytes, err := rlp.EncodeToBytes(tx)
 	if err != nil {
-		return err
+		return types.NullHash, err
 	}
 
 	v := url.Values{}
@@ -33,10 +40,47 @@ func (c *Client) PostTx(ctx context.Context, tx *types.Tx) error {
 		v,
 		&resp,
 	); err != nil {
-		return err
+		return types.NullHash, err
 	}
 
-	return nil
+	return utils.HexToHash(resp.Result.HashStr), nil
+}
+
+type ValidateTxResponse struct {
+	*ResponseBase
+	Result *TxValidation `json:"result"`
+}
+
+type TxValidation struct {
+	IsValid         bool         `json:"valid"`
+	InputAmount     *big.Int     `json:"in"`
+	OutputAmount    *big.Int     `json:"out"`
+	Fee             *big.Int     `json:"fee"`
+	SignatureErrors []*app.Error `json:"sigerrs"`
+	Errors          []*app.Error `json:"errors"`
+}
+
+func (c *Client) ValidateTx(ctx context.Context, tx *types.Tx) (*TxValidation, error) {
+	txBytes, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	v.Set("tx", utils.EncodeToHex(txBytes))
+
+	var resp ValidateTxResponse
+	if err := c.doAPI(
+		ctx,
+		http.MethodPost,
+		"txes/validate",
+		v,
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	return resp.Result, nil
 }
 
 type GetTxResponse struct {
@@ -92,3 +136,51 @@ func (c *Client) GetTxProof(ctx context.Context, txPos types.Position) ([]byte,
 
 	return utils.DecodeHex(resp.Result.ProofStr)
 }
+
+type GetTxStatusResponse struct {
+	*ResponseBase
+	Result *core.TxStatus `json:"result"`
+}
+
+func (c *Client) GetTxStatus(ctx context.Context, txHash common.Hash) (*core.TxStatus, error) {
+	var resp GetTxStatusResponse
+	if err := c.doAPI(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("txes/hash/%s", utils.HashToHex(txHash)),
+		nil,
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	return resp.Result, nil
+}
+
+type GetTxFinalityResponse struct {
+	*ResponseBase
+	Result *core.TxFinality `json:"result"`
+}
+
+func (c *Client) GetTxFinality(ctx context.Context, txPos types.Position) (*core.TxFinality, error) {
+	return c.getTxFinality(ctx, fmt.Sprintf("txes/%d/finality", txPos))
+}
+
+func (c *Client) GetTxFinalityByHash(ctx context.Context, txHash common.Hash) (*core.TxFinality, error) {
+	return c.getTxFinality(ctx, fmt.Sprintf("txes/hash/%s/finality", utils.HashToHex(txHash)))
+}
+
+func (c *Client) getTxFinality(ctx context.Context, uri string) (*core.TxFinality, error) {
+	var resp GetTxFinalityResponse
+	if err := c.doAPI(
+		ctx,
+		http.MethodGet,
+		uri,
+		nil,
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	return resp.Result, nil
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+type GetTxOutExitDataResponse struct {
+	*ResponseBase
+	Result *core.ExitData `json:"result"`
+}
+
+func (c *Client) GetTxOutExitData(ctx context.Context, txOutPos types.Position) (*core.ExitData, error) {
+	var resp GetTxOutExitDataResponse
+	if err := c.doAPI(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("txouts/%d/exitdata", txOutPos),
+		nil,
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	return resp.Result, nil
+}
+
+type SpenderResult struct {
+	TxOutPosition            types.Position `json:"pos"`
+	TxInPosition             types.Position `json:"vspos"`
+	TxPosition               types.Position `json:"txpos"`
+	InputIndex               uint64         `json:"index"`
+	TxStr                    string         `json:"tx"`
+	ConfirmationSignatureStr string         `json:"confsig"`
+}
+
+func (res SpenderResult) Spender() (*core.Spender, error) {
+	txBytes, err := utils.DecodeHex(res.TxStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx types.Tx
+	if err := rlp.DecodeBytes(txBytes, &tx); err != nil {
+		return nil, err
+	}
+
+	confSig, err := types.HexToSignature(res.ConfirmationSignatureStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.Spender{
+		TxOutPosition:         res.TxOutPosition,
+		TxInPosition:          res.TxInPosition,
+		TxPosition:            res.TxPosition,
+		InputIndex:            res.InputIndex,
+		Tx:                    &tx,
+		ConfirmationSignature: confSig,
+	}, nil
+}
+
+type GetTxOutSpenderResponse struct {
+	*ResponseBase
+	Result SpenderResult `json:"result"`
+}
+
+// GetTxOutSpender returns the input of a tx in a block that spends the txout at txOutPos.
+func (c *Client) GetTxOutSpender(ctx context.Context, txOutPos types.Position) (*core.Spender, error) {
+	var resp GetTxOutSpenderResponse
+	if err := c.doAPI(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("txouts/%d/spender", txOutPos),
+		nil,
+		&resp,
+	); err != nil {
+		return nil, err
+	}
+
+	return resp.Result.Spender()
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+)
+
+// Archive reads blocks, txes and tx proofs from a block archive instead of the child chain API,
+// so that they are available even while the operator is not.
+type Archive struct {
+	*core.BlockArchiveReader
+}
+
+// NewArchive creates a reader of the archive at base, which is an HTTP URL or a directory.
+func NewArchive(base string) *Archive {
+	return &Archive{
+		BlockArchiveReader: core.NewBlockArchiveReader(base),
+	}
+}
+
+func (a *Archive) GetTx(ctx context.Context, txPos types.Position) (*types.Tx, error) {
+	blkNum, txIndex := types.ParseTxPosition(txPos)
+
+	blk, err := a.GetBlock(ctx, blkNum)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := blk.GetTx(txIndex)
+	if tx == nil {
+		return nil, core.ErrTxNotFound
+	}
+
+	return tx, nil
+}
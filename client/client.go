@@ -3,7 +3,6 @@ package client
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -27,6 +26,10 @@ func New(baseURI string) *Client {
 }
 
 func (c *Client) doAPI(ctx context.Context, method, uri string, params url.Values, res interface{}) error {
+	return c.doAPIWithHeader(ctx, method, uri, params, nil, res)
+}
+
+func (c *Client) doAPIWithHeader(ctx context.Context, method, uri string, params url.Values, header http.Header, res interface{}) error {
 	u, err := url.Parse(c.baseURI)
 	if err != nil {
 		return err
@@ -35,7 +38,7 @@ func (c *Client) doAPI(ctx context.Context, method, uri string, params url.Value
 
 	var body io.Reader
 	switch method {
-	case http.MethodGet:
+	case http.MethodGet, http.MethodDelete:
 		u.RawQuery = params.Encode()
 	default:
 		body = strings.NewReader(params.Encode())
@@ -47,6 +50,9 @@ func (c *Client) doAPI(ctx context.Context, method, uri string, params url.Value
 	}
 	req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for key, vals := range header {
+		req.Header[key] = vals
+	}
 
 	resp, err := c.Do(req)
 	if err != nil {
@@ -64,10 +70,7 @@ func (c *Client) doAPI(ctx context.Context, method, uri string, params url.Value
 		return err
 	}
 	if resErr.State == app.ResponseStateError {
-		return fmt.Errorf(
-			"API error : %s [%d]",
-			resErr.Result.Message, resErr.Result.Code,
-		)
+		return resErr.Result
 	}
 
 	return json.Unmarshal(b, &res)
@@ -0,0 +1,16 @@
+package client
+
+import "github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+
+// API errors are returned to callers as *app.Error,
+// so they can be compared with errors.Is against the app.Err* values.
+
+// ErrorDetail returns the detail of err if err is an API error that carries one.
+func ErrorDetail(err error) (*app.ErrorDetail, bool) {
+	appErr, ok := err.(*app.Error)
+	if !ok || appErr.Detail == nil {
+		return nil, false
+	}
+
+	return appErr.Detail, true
+}
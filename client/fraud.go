@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+// FindFraud replicates the blocks committed to the root chain, read from src, into a child chain in memory
+// and re-validates their txes there, so that evidence can be found without the operator reporting it.
+// Like the light client, each block is checked against the committed root and the operator of the root chain
+// before being trusted, and a block that fails the check stops the search, since no evidence can be built on it.
+func FindFraud(ctx context.Context, src app.BlockSource, rc *core.RootChain) (*core.FraudReport, error) {
+	operatorAddr, err := rc.Operator()
+	if err != nil {
+		return nil, err
+	}
+
+	// the current plasma block number is the number of the next block
+	rootBlkNum, err := rc.CurrentPlasmaBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	db := core.NewMemoryKV()
+	defer db.Close()
+
+	txn := db.NewTransaction(true)
+	defer txn.Discard()
+
+	cc, err := core.NewChildChain(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	for blkNum := uint64(core.FirstBlockNumber); blkNum < rootBlkNum; blkNum++ {
+		blk, err := src.GetBlock(ctx, blkNum)
+		if appErr, ok := err.(*app.Error); ok && appErr.Is(app.ErrBlockPruned) {
+			// a pruning node does not serve the bodies of its old blocks
+			return nil, fmt.Errorf("block %d is pruned by the API, so it has to be read from its archive", blkNum)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// verify root
+		rootHash, err := blk.Root()
+		if err != nil {
+			return nil, err
+		}
+		pb, err := rc.PlasmaBlocks(blkNum)
+		if err != nil {
+			return nil, err
+		}
+		if rootHash != pb.RootHash() {
+			return nil, fmt.Errorf(
+				"root of block %d is %s but %s is committed",
+				blkNum, utils.HashToHex(rootHash), utils.HashToHex(pb.RootHash()),
+			)
+		}
+
+		// verify signer
+		signerAddr, err := blk.SignerAddress()
+		if err != nil {
+			return nil, err
+		}
+		if signerAddr != operatorAddr {
+			return nil, fmt.Errorf("block %d is signed by %s", blkNum, utils.AddressToHex(signerAddr))
+		}
+
+		if err := cc.AddReplicatedBlock(txn, blk); err != nil {
+			return nil, err
+		}
+	}
+
+	return cc.FindFraud(txn, rc)
+}
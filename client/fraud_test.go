@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testBlockSource serves blocks as a public API or an archive does.
+type testBlockSource map[uint64]*types.Block
+
+func (src testBlockSource) GetBlock(ctx context.Context, blkNum uint64) (*types.Block, error) {
+	blk, ok := src[blkNum]
+	if !ok {
+		return nil, app.ErrBlockNotFound
+	}
+
+	return blk, nil
+}
+
+// newTestFraudRootChain serves the operator, the roots committed for blks and the deposits of deposits by deposit block number.
+func newTestFraudRootChain(t *testing.T, operator *types.Account, blks testBlockSource, deposits map[uint64]*types.TxOut) (*core.RootChain, func()) {
+	rootChainABI, err := abi.JSON(strings.NewReader(core.RootChainABI))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var result interface{}
+		switch req.Method {
+		case "eth_getBlockByNumber":
+			result = &gethtypes.Header{
+				Number:     big.NewInt(10),
+				Difficulty: big.NewInt(0),
+				Time:       big.NewInt(0),
+			}
+		case "eth_getLogs":
+			event := rootChainABI.Events["DepositCreated"]
+			logs := []gethtypes.Log{}
+			for blkNum, txOut := range deposits {
+				data, _ := event.Inputs.NonIndexed().Pack(txOut.Amount, new(big.Int).SetUint64(blkNum))
+				logs = append(logs, gethtypes.Log{
+					Topics:      []common.Hash{event.Id(), txOut.OwnerAddress.Hash()},
+					Data:        data,
+					BlockNumber: 1,
+				})
+			}
+			result = logs
+		case "eth_call":
+			var msg struct {
+				Data hexutil.Bytes `json:"data"`
+			}
+			json.Unmarshal(req.Params[0], &msg)
+			method, err := rootChainABI.MethodById(msg.Data[:4])
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			var out []byte
+			switch method.Name {
+			case "operator":
+				out, err = method.Outputs.Pack(operator.Address())
+			case "currentPlasmaBlockNumber":
+				out, err = method.Outputs.Pack(big.NewInt(int64(len(blks) + 1)))
+			case "plasmaBlocks":
+				var root [32]byte
+				if blk, ok := blks[new(big.Int).SetBytes(msg.Data[4:36]).Uint64()]; ok {
+					rootHash, _ := blk.Root()
+					copy(root[:], rootHash.Bytes())
+				}
+				out, err = method.Outputs.Pack(root, big.NewInt(0))
+			default:
+				http.Error(w, method.Name, http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			result = hexutil.Bytes(out)
+		default:
+			http.Error(w, req.Method, http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  result,
+		})
+	}))
+
+	rc, err := core.NewRootChain(core.RootChainConfig{
+		RPC:        server.URL,
+		WS:         server.URL,
+		AddressStr: "0x0000000000000000000000000000000000000001",
+	})
+	require.NoError(t, err)
+
+	return rc, server.Close
+}
+
+func newTestSignedBlock(t *testing.T, signer *types.Account, blkNum uint64, txes ...*types.Tx) *types.Block {
+	blk := newTestBlock(t, blkNum, txes...)
+	require.NoError(t, blk.Sign(signer))
+	return blk
+}
+
+func TestFindFraud(t *testing.T) {
+	operator, a, b := newTestAccount(t), newTestAccount(t), newTestAccount(t)
+
+	depositTx := newTestDepositTx(t, a, 100)
+
+	// the deposit of a spent with the signature of b
+	invalidTx := types.NewTx()
+	require.NoError(t, invalidTx.SetInput(0, types.NewTxIn(1, 0, 0)))
+	require.NoError(t, invalidTx.SetOutput(0, types.NewTxOut(b.Address(), big.NewInt(100))))
+	require.NoError(t, invalidTx.Sign(0, b))
+
+	validTx := types.NewTx()
+	require.NoError(t, validTx.SetInput(0, types.NewTxIn(1, 0, 0)))
+	require.NoError(t, validTx.SetOutput(0, types.NewTxOut(b.Address(), big.NewInt(100))))
+	require.NoError(t, validTx.Sign(0, a))
+
+	testCases := []struct {
+		name      string
+		blks      testBlockSource
+		committed testBlockSource // the blocks whose roots are committed, blks if nil
+		evidence  []types.Position
+		err       string
+	}{
+		{
+			"valid blocks",
+			testBlockSource{
+				1: newTestSignedBlock(t, operator, 1, depositTx),
+				2: newTestSignedBlock(t, operator, 2, validTx),
+			},
+			nil,
+			[]types.Position{},
+			"",
+		},
+		{
+			"invalid tx in a committed block",
+			testBlockSource{
+				1: newTestSignedBlock(t, operator, 1, depositTx),
+				2: newTestSignedBlock(t, operator, 2, invalidTx),
+			},
+			nil,
+			[]types.Position{types.NewTxPosition(2, 0)},
+			"",
+		},
+		{
+			"block not signed by the operator",
+			testBlockSource{
+				1: newTestSignedBlock(t, operator, 1, depositTx),
+				2: newTestSignedBlock(t, b, 2, invalidTx),
+			},
+			nil,
+			nil,
+			"block 2 is signed by",
+		},
+		{
+			"block not matching the committed root",
+			testBlockSource{
+				1: newTestSignedBlock(t, operator, 1, depositTx),
+				2: newTestSignedBlock(t, operator, 2, invalidTx),
+			},
+			testBlockSource{
+				1: newTestSignedBlock(t, operator, 1, depositTx),
+				2: newTestSignedBlock(t, operator, 2, validTx),
+			},
+			nil,
+			"root of block 2 is",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			committed := tc.committed
+			if committed == nil {
+				committed = tc.blks
+			}
+			rc, closeRC := newTestFraudRootChain(t, operator, committed, map[uint64]*types.TxOut{
+				1: depositTx.GetOutput(0),
+			})
+			defer closeRC()
+
+			report, err := FindFraud(context.Background(), tc.blks, rc)
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, uint64(3), report.BlockNumber)
+			poses := []types.Position{}
+			for _, evidence := range report.Evidence {
+				poses = append(poses, evidence.TxPosition)
+			}
+			require.Equal(t, tc.evidence, poses)
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+const (
+	LightClientAlertMissingBlock  = "missing_block"
+	LightClientAlertRootMismatch  = "root_mismatch"
+	LightClientAlertInvalidSigner = "invalid_signer"
+	LightClientAlertInvalidTx     = "invalid_tx"
+)
+
+// LightClientAlert reports something served by the operator that does not match the root chain
+// or breaks the rules of the child chain. Position is only set for invalid txes.
+type LightClientAlert struct {
+	Type        string         `json:"type"`
+	BlockNumber uint64         `json:"blknum"`
+	Position    types.Position `json:"txpos,omitempty"`
+	Reason      string         `json:"reason"`
+}
+
+// LightClientState is what the light client keeps in its state file:
+// the last verified block and the txouts left unspent by the verified blocks.
+type LightClientState struct {
+	BlockNumber uint64                              `json:"blknum"`
+	TxOuts      map[types.Position]*types.TxOutCore `json:"txouts"`
+}
+
+func newLightClientState() *LightClientState {
+	return &LightClientState{
+		TxOuts: map[types.Position]*types.TxOutCore{},
+	}
+}
+
+// LightClient verifies the blocks served by the child chain API against the roots committed to the root chain.
+// It validates the txes touching the followed addresses, so it keeps the whole utxo set of the verified blocks.
+type LightClient struct {
+	clnt  *Client
+	rc    *core.RootChain
+	addrs map[common.Address]bool
+	path  string
+	state *LightClientState
+
+	// deposits caches the DepositCreated events by deposit block number
+	deposits map[uint64]*core.RootChainDepositCreated
+}
+
+// NewLightClient creates a light client that keeps its state at path, which does not have to exist yet.
+func NewLightClient(clnt *Client, rc *core.RootChain, addrs []common.Address, path string) (*LightClient, error) {
+	lc := &LightClient{
+		clnt:     clnt,
+		rc:       rc,
+		addrs:    map[common.Address]bool{},
+		path:     path,
+		state:    newLightClientState(),
+		deposits: map[uint64]*core.RootChainDepositCreated{},
+	}
+	for _, addr := range addrs {
+		lc.addrs[addr] = true
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lc, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, lc.state); err != nil {
+		return nil, err
+	}
+
+	return lc, nil
+}
+
+// BlockNumber returns the number of the last verified block.
+func (lc *LightClient) BlockNumber() uint64 {
+	return lc.state.BlockNumber
+}
+
+// Sync verifies the committed blocks following the last verified one and calls alertFn for each alert.
+// It stops at the first block that is missing or does not match the root chain,
+// since no later block can be trusted, and verifies it again on the next call.
+func (lc *LightClient) Sync(ctx context.Context, alertFn func(*LightClientAlert)) error {
+	operatorAddr, err := lc.rc.Operator()
+	if err != nil {
+		return err
+	}
+
+	// the current plasma block number is the number of the next block
+	rootBlkNum, err := lc.rc.CurrentPlasmaBlockNumber()
+	if err != nil {
+		return err
+	}
+
+	for blkNum := lc.state.BlockNumber + 1; blkNum < rootBlkNum; blkNum++ {
+		alert, err := lc.verifyBlock(ctx, blkNum, operatorAddr, alertFn)
+		if err != nil {
+			return err
+		}
+		if alert != nil {
+			alertFn(alert)
+			break
+		}
+
+		lc.state.BlockNumber = blkNum
+	}
+
+	return lc.save()
+}
+
+func (lc *LightClient) verifyBlock(ctx context.Context, blkNum uint64, operatorAddr common.Address, alertFn func(*LightClientAlert)) (*LightClientAlert, error) {
+	newAlert := func(alertType, reason string) *LightClientAlert {
+		return &LightClientAlert{
+			Type:        alertType,
+			BlockNumber: blkNum,
+			Reason:      reason,
+		}
+	}
+
+	// get block
+	blk, err := lc.clnt.GetBlock(ctx, blkNum)
+	if err != nil {
+		if appErr, ok := err.(*app.Error); ok && appErr.Is(app.ErrBlockNotFound) {
+			return newAlert(LightClientAlertMissingBlock, err.Error()), nil
+		}
+		return nil, err
+	}
+
+	// verify root
+	rootHash, err := blk.Root()
+	if err != nil {
+		return nil, err
+	}
+	pb, err := lc.rc.PlasmaBlocks(blkNum)
+	if err != nil {
+		return nil, err
+	}
+	if rootHash != pb.RootHash() {
+		return newAlert(LightClientAlertRootMismatch, fmt.Sprintf(
+			"root is %s but %s is committed", utils.HashToHex(rootHash), utils.HashToHex(pb.RootHash()),
+		)), nil
+	}
+
+	// verify signer
+	signerAddr, err := blk.SignerAddress()
+	if err != nil {
+		return newAlert(LightClientAlertInvalidSigner, err.Error()), nil
+	}
+	if signerAddr != operatorAddr {
+		return newAlert(LightClientAlertInvalidSigner, fmt.Sprintf(
+			"block is signed by %s", utils.AddressToHex(signerAddr),
+		)), nil
+	}
+
+	// the block matches the root chain, so an invalid tx in it is reported without stopping.
+	// deposit txes are checked even if not followed, since a forged one can be spent to a followed address,
+	// and invalid txes are not applied, so that txes spending their outputs are reported as well.
+	for i, tx := range blk.Txes {
+		// null txes pad blocks and have no effect
+		if tx.IsNull() {
+			continue
+		}
+
+		txPos := types.NewTxPosition(blkNum, uint64(i))
+
+		if !lc.isFollowed(tx) && !isDepositTx(tx) {
+			lc.applyTx(txPos, tx)
+			continue
+		}
+
+		var deposit *core.RootChainDepositCreated
+		if isDepositTx(tx) {
+			if deposit, err = lc.depositCreated(ctx, blkNum); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := lc.validateTx(blk, tx, deposit); err != nil {
+			alert := newAlert(LightClientAlertInvalidTx, err.Error())
+			alert.Position = txPos
+			alertFn(alert)
+			continue
+		}
+		lc.applyTx(txPos, tx)
+	}
+
+	return nil, nil
+}
+
+// depositCreated returns the DepositCreated event of the deposit block blkNum,
+// or nil if no deposit was made for it. The events are filtered again only on a cache miss.
+func (lc *LightClient) depositCreated(ctx context.Context, blkNum uint64) (*core.RootChainDepositCreated, error) {
+	if event, ok := lc.deposits[blkNum]; ok {
+		return event, nil
+	}
+
+	events, err := lc.rc.FilterDepositCreated(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		lc.deposits[event.DepositBlock.Uint64()] = event
+	}
+
+	return lc.deposits[blkNum], nil
+}
+
+func isDepositTx(tx *types.Tx) bool {
+	for _, txIn := range tx.Inputs {
+		if !txIn.IsNull() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (lc *LightClient) isFollowed(tx *types.Tx) bool {
+	for _, txOut := range tx.Outputs {
+		if lc.addrs[txOut.OwnerAddress] {
+			return true
+		}
+	}
+	for _, txIn := range tx.Inputs {
+		if txIn.IsNull() {
+			continue
+		}
+		inTxOut, ok := lc.state.TxOuts[types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)]
+		if ok && lc.addrs[inTxOut.OwnerAddress] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateTx checks tx in blk against the utxo set of the verified blocks in the same way the child chain does.
+// A tx with no inputs is only valid as the deposit tx created by deposit, the DepositCreated event of the block.
+func (lc *LightClient) validateTx(blk *types.Block, tx *types.Tx, deposit *core.RootChainDepositCreated) error {
+	inAmount, outAmount := big.NewInt(0), big.NewInt(0)
+	nullTxInNum := 0
+
+	for _, txOut := range tx.Outputs {
+		outAmount.Add(outAmount, txOut.Amount)
+	}
+
+	for i, txIn := range tx.Inputs {
+		if txIn.IsNull() {
+			nullTxInNum++
+			continue
+		}
+
+		inTxOutPos := types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)
+
+		inTxOut, ok := lc.state.TxOuts[inTxOutPos]
+		if !ok {
+			return core.NewTxInError(core.ErrInvalidTxIn, uint64(i), inTxOutPos)
+		}
+
+		signerAddr, err := tx.SignerAddress(uint64(i))
+		if err != nil || signerAddr != inTxOut.OwnerAddress {
+			return core.NewTxInError(core.ErrInvalidTxSignature, uint64(i), inTxOutPos)
+		}
+
+		inAmount.Add(inAmount, inTxOut.Amount)
+	}
+
+	// deposit txes have no inputs and are signed off by the root chain
+	if nullTxInNum == len(tx.Inputs) {
+		return core.VerifyDepositBlock(blk, deposit)
+	}
+
+	if outAmount.Cmp(inAmount) > 0 {
+		return core.ErrInvalidTxBalance
+	}
+
+	return nil
+}
+
+func (lc *LightClient) applyTx(txPos types.Position, tx *types.Tx) {
+	for _, txIn := range tx.Inputs {
+		if txIn.IsNull() {
+			continue
+		}
+		delete(lc.state.TxOuts, types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex))
+	}
+
+	blkNum, txIndex := types.ParseTxPosition(txPos)
+	for i, txOut := range tx.Outputs {
+		if txOut.OwnerAddress == types.NullAddress {
+			continue
+		}
+		lc.state.TxOuts[types.NewTxOutPosition(blkNum, txIndex, uint64(i))] = txOut.TxOutCore
+	}
+}
+
+// save writes the state to a temporary file first so that the state file is never left half written.
+func (lc *LightClient) save() error {
+	b, err := json.Marshal(lc.state)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := lc.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, lc.path)
+}
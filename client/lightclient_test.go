@@ -0,0 +1,104 @@
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLightClient() *LightClient {
+	return &LightClient{
+		addrs:    map[common.Address]bool{},
+		state:    newLightClientState(),
+		deposits: map[uint64]*core.RootChainDepositCreated{},
+	}
+}
+
+func newTestBlock(t *testing.T, blkNum uint64, txes ...*types.Tx) *types.Block {
+	blk, err := types.NewBlock(txes, blkNum)
+	require.NoError(t, err)
+	return blk
+}
+
+func newTestDepositTx(t *testing.T, a *types.Account, amount int64) *types.Tx {
+	tx := types.NewTx()
+	require.NoError(t, tx.SetOutput(0, types.NewTxOut(a.Address(), big.NewInt(amount))))
+	return tx
+}
+
+func TestLightClient_ValidateTx_Deposit(t *testing.T) {
+	a, b := newTestAccount(t), newTestAccount(t)
+	lc := newTestLightClient()
+
+	deposit := &core.RootChainDepositCreated{
+		Owner:        a.Address(),
+		Amount:       big.NewInt(100),
+		DepositBlock: big.NewInt(1),
+	}
+
+	// backed by the deposit
+	tx := newTestDepositTx(t, a, 100)
+	require.NoError(t, lc.validateTx(newTestBlock(t, 1, tx), tx, deposit))
+
+	// no deposit was made for the block
+	require.Equal(t, core.ErrInvalidDepositTx, lc.validateTx(newTestBlock(t, 1, tx), tx, nil))
+
+	// the deposit is for another block
+	require.Equal(t, core.ErrInvalidDepositTx, lc.validateTx(newTestBlock(t, 2, tx), tx, deposit))
+
+	// the deposit tx is not the only tx of the block
+	otherTx := newTestDepositTx(t, b, 100)
+	require.Equal(t, core.ErrInvalidDepositTx, lc.validateTx(newTestBlock(t, 1, tx, otherTx), tx, deposit))
+
+	// the owner does not match
+	require.Equal(t, core.ErrInvalidDepositTx, lc.validateTx(newTestBlock(t, 1, otherTx), otherTx, deposit))
+
+	// the amount does not match
+	tx = newTestDepositTx(t, a, 1000)
+	require.Equal(t, core.ErrInvalidDepositTx, lc.validateTx(newTestBlock(t, 1, tx), tx, deposit))
+
+	// the deposit is paid to another output as well
+	tx = newTestDepositTx(t, a, 100)
+	require.NoError(t, tx.SetOutput(1, types.NewTxOut(b.Address(), big.NewInt(100))))
+	require.Equal(t, core.ErrInvalidDepositTx, lc.validateTx(newTestBlock(t, 1, tx), tx, deposit))
+}
+
+func TestLightClient_ValidateTx(t *testing.T) {
+	a, b := newTestAccount(t), newTestAccount(t)
+	lc := newTestLightClient()
+
+	depositTx := newTestDepositTx(t, a, 100)
+	lc.applyTx(types.NewTxPosition(1, 0), depositTx)
+
+	newSpendingTx := func(signer *types.Account, amount int64) *types.Tx {
+		tx := types.NewTx()
+		require.NoError(t, tx.SetInput(0, types.NewTxIn(1, 0, 0)))
+		require.NoError(t, tx.SetOutput(0, types.NewTxOut(b.Address(), big.NewInt(amount))))
+		require.NoError(t, tx.Sign(0, signer))
+		return tx
+	}
+
+	tx := newSpendingTx(a, 100)
+	require.NoError(t, lc.validateTx(newTestBlock(t, 2, tx), tx, nil))
+
+	// signed by another account
+	tx = newSpendingTx(b, 100)
+	err := lc.validateTx(newTestBlock(t, 2, tx), tx, nil)
+	require.IsType(t, &core.TxInError{}, err)
+	require.Equal(t, core.ErrInvalidTxSignature, err.(*core.TxInError).Cause)
+
+	// spending more than the input
+	tx = newSpendingTx(a, 101)
+	require.Equal(t, core.ErrInvalidTxBalance, lc.validateTx(newTestBlock(t, 2, tx), tx, nil))
+
+	// the input is not in the utxo set
+	lc.applyTx(types.NewTxPosition(2, 0), newSpendingTx(a, 100))
+	tx = newSpendingTx(a, 100)
+	err = lc.validateTx(newTestBlock(t, 3, tx), tx, nil)
+	require.IsType(t, &core.TxInError{}, err)
+	require.Equal(t, core.ErrInvalidTxIn, err.(*core.TxInError).Cause)
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+)
+
+// WalletEntry is an owned txout together with everything needed to exit it.
+type WalletEntry struct {
+	*core.ExitData
+	Owner     common.Address `json:"owner"`
+	IsExiting bool           `json:"exiting"`
+}
+
+// Wallet is a local store of owned txouts, so that they can be exited
+// from local data alone when the child chain API is not available.
+type Wallet struct {
+	path    string
+	entries map[types.Position]*WalletEntry
+}
+
+// LoadWallet loads the wallet stored at path, which does not have to exist yet.
+func LoadWallet(path string) (*Wallet, error) {
+	w := &Wallet{
+		path:    path,
+		entries: map[types.Position]*WalletEntry{},
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return w, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &w.entries); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Save writes the wallet to a temporary file first so that the wallet is never left half written.
+func (w *Wallet) Save() error {
+	b, err := json.MarshalIndent(w.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := w.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, w.path)
+}
+
+func (w *Wallet) Get(txOutPos types.Position) *WalletEntry {
+	return w.entries[txOutPos]
+}
+
+// Put stores exitData of the txout of owner, unless the txout is already exiting,
+// in which case the stored entry, with the exit data the exit was started with, is kept.
+func (w *Wallet) Put(owner common.Address, exitData *core.ExitData) {
+	if entry := w.Get(exitData.TxOutPosition); entry != nil && entry.IsExiting {
+		return
+	}
+
+	w.entries[exitData.TxOutPosition] = &WalletEntry{
+		ExitData: exitData,
+		Owner:    owner,
+	}
+}
+
+// PutTx stores the outputs of owners of tx at txPos, whose Merkle proof is txProofBytes,
+// except for the outputs already exiting. It returns the number of stored entries.
+func (w *Wallet) PutTx(txPos types.Position, tx *types.Tx, txProofBytes []byte, owners []common.Address) (int, error) {
+	blkNum, txIndex := types.ParseTxPosition(txPos)
+
+	isOwner := map[common.Address]bool{}
+	for _, owner := range owners {
+		isOwner[owner] = true
+	}
+
+	n := 0
+	for i, txOut := range tx.Outputs {
+		if !isOwner[txOut.OwnerAddress] {
+			continue
+		}
+
+		txOutPos := types.NewTxOutPosition(blkNum, txIndex, uint64(i))
+		if entry := w.Get(txOutPos); entry != nil && entry.IsExiting {
+			continue
+		}
+
+		exitData, err := core.NewExitData(txOutPos, tx, txProofBytes)
+		if err != nil {
+			return 0, err
+		}
+		w.Put(txOut.OwnerAddress, exitData)
+		n++
+	}
+
+	return n, nil
+}
+
+func (w *Wallet) Delete(txOutPos types.Position) {
+	delete(w.entries, txOutPos)
+}
+
+// Entries returns the entries of owner in exit priority order, that is, older txouts first.
+func (w *Wallet) Entries(owner common.Address) []*WalletEntry {
+	entries := []*WalletEntry{}
+	for _, entry := range w.entries {
+		if entry.Owner == owner {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].TxOutPosition < entries[j].TxOutPosition
+	})
+
+	return entries
+}
+
+// SyncWallet stores the exit data of the utxos of owner in w and drops the entries
+// of txouts spent in a block. It returns the numbers of stored and dropped entries.
+// Txouts spent only in the mempool are kept, since the spending tx may never be included.
+func (c *Client) SyncWallet(ctx context.Context, w *Wallet, owner common.Address) (int, int, error) {
+	utxos, err := c.GetAddressUTXOs(ctx, owner, nil, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	isUTXO := map[types.Position]bool{}
+	for _, utxo := range utxos {
+		exitData, err := c.GetTxOutExitData(ctx, utxo.Position)
+		if err != nil {
+			return 0, 0, err
+		}
+		w.Put(owner, exitData)
+		isUTXO[utxo.Position] = true
+	}
+
+	dropped := 0
+	for _, entry := range w.Entries(owner) {
+		if isUTXO[entry.TxOutPosition] {
+			continue
+		}
+
+		// only a spender in a block is found
+		if _, err := c.GetTxOutSpender(ctx, entry.TxOutPosition); err != nil {
+			if appErr, ok := err.(*app.Error); ok && appErr.Is(app.ErrTxOutNotSpent) {
+				continue
+			}
+			return 0, 0, err
+		}
+		w.Delete(entry.TxOutPosition)
+		dropped++
+	}
+
+	return len(utxos), dropped, nil
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWallet(t *testing.T) (*Wallet, func()) {
+	dir, err := ioutil.TempDir("", "mmpc-wallet")
+	require.NoError(t, err)
+
+	w, err := LoadWallet(filepath.Join(dir, "wallet.json"))
+	require.NoError(t, err)
+
+	return w, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func newTestAccount(t *testing.T) *types.Account {
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return types.NewAccount(privKey)
+}
+
+func TestWallet_PutTx(t *testing.T) {
+	a, b := newTestAccount(t), newTestAccount(t)
+
+	w, closeWallet := newTestWallet(t)
+	defer closeWallet()
+
+	// a deposit has a null output
+	depositTx := types.NewTx()
+	require.NoError(t, depositTx.SetOutput(0, types.NewTxOut(a.Address(), big.NewInt(100))))
+
+	n, err := w.PutTx(types.NewTxPosition(1, 0), depositTx, nil, []common.Address{a.Address()})
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	entry := w.Get(types.NewTxOutPosition(1, 0, 0))
+	require.NotNil(t, entry)
+	require.Equal(t, a.Address(), entry.Owner)
+	require.Nil(t, w.Get(types.NewTxOutPosition(1, 0, 1)))
+
+	// an entry already exiting is kept
+	entry.IsExiting = true
+
+	tx := types.NewTx()
+	require.NoError(t, tx.SetInput(0, types.NewTxIn(1, 0, 0)))
+	require.NoError(t, tx.SetOutput(0, types.NewTxOut(b.Address(), big.NewInt(60))))
+	require.NoError(t, tx.SetOutput(1, types.NewTxOut(a.Address(), big.NewInt(40))))
+	require.NoError(t, tx.Sign(0, a))
+
+	// only the outputs of the given owners are stored
+	n, err = w.PutTx(types.NewTxPosition(2, 0), tx, []byte{0x01}, []common.Address{a.Address()})
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Nil(t, w.Get(types.NewTxOutPosition(2, 0, 0)))
+
+	n, err = w.PutTx(types.NewTxPosition(2, 0), tx, []byte{0x01}, []common.Address{a.Address(), b.Address()})
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	n, err = w.PutTx(types.NewTxPosition(1, 0), depositTx, nil, []common.Address{a.Address()})
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+	require.True(t, w.Get(types.NewTxOutPosition(1, 0, 0)).IsExiting)
+
+	// entries of a are ordered by position and survive a reload
+	require.NoError(t, w.Save())
+
+	loaded, err := LoadWallet(w.path)
+	require.NoError(t, err)
+
+	entries := loaded.Entries(a.Address())
+	require.Len(t, entries, 2)
+	require.Equal(t, types.NewTxOutPosition(1, 0, 0), entries[0].TxOutPosition)
+	require.True(t, entries[0].IsExiting)
+	require.Equal(t, types.NewTxOutPosition(2, 0, 1), entries[1].TxOutPosition)
+	require.Equal(t, []byte{0x01}, []byte(entries[1].TxProof))
+
+	entries = loaded.Entries(b.Address())
+	require.Len(t, entries, 1)
+	require.Equal(t, types.NewTxOutPosition(2, 0, 0), entries[0].TxOutPosition)
+}
+
+func TestClient_SyncWallet_Exiting(t *testing.T) {
+	a := newTestAccount(t)
+
+	w, closeWallet := newTestWallet(t)
+	defer closeWallet()
+
+	txOutPos := types.NewTxOutPosition(1, 0, 0)
+
+	// the operator serves a deposit of a as an utxo, whose exit data has a new proof on every call
+	proofs := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var result interface{}
+		switch r.URL.Path {
+		case fmt.Sprintf("/addresses/%s/utxos", utils.AddressToHex(a.Address())):
+			result = map[string]interface{}{
+				"utxos": []*core.UTXO{core.NewUTXO(txOutPos, big.NewInt(100))},
+			}
+		case fmt.Sprintf("/txouts/%d/exitdata", txOutPos):
+			proofs++
+			result = &core.ExitData{
+				TxOutPosition: txOutPos,
+				TxProof:       []byte{byte(proofs)},
+			}
+		default:
+			http.NotFound(rw, r)
+			return
+		}
+
+		require.NoError(t, json.NewEncoder(rw).Encode(map[string]interface{}{
+			"state":  app.ResponseStateSuccess,
+			"result": result,
+		}))
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+
+	stored, dropped, err := c.SyncWallet(context.Background(), w, a.Address())
+	require.NoError(t, err)
+	require.Equal(t, 1, stored)
+	require.Equal(t, 0, dropped)
+
+	// an exit is started with the stored exit data
+	entry := w.Get(txOutPos)
+	entry.IsExiting = true
+	entry.Bond = big.NewInt(1)
+
+	// re-syncing keeps the entry, so that the exit is not started again
+	_, _, err = c.SyncWallet(context.Background(), w, a.Address())
+	require.NoError(t, err)
+	require.Equal(t, 2, proofs)
+
+	entry = w.Get(txOutPos)
+	require.True(t, entry.IsExiting)
+	require.Equal(t, big.NewInt(1), entry.Bond)
+	require.Equal(t, []byte{0x01}, []byte(entry.TxProof))
+}
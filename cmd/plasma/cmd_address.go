@@ -6,6 +6,8 @@ var cmdAddress = cli.Command{
 	Name:  "address",
 	Usage: "commands for address",
 	Subcommands: []cli.Command{
+		cmdAddressBalance,
+		cmdAddressHistory,
 		cmdAddressUTXOs,
 	},
 }
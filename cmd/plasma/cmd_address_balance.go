@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+
+	"github.com/urfave/cli"
+)
+
+var cmdAddressBalance = cli.Command{
+	Name:  "balance",
+	Usage: "get balance of address",
+	Flags: flags(
+		addressFlag,
+		atFlag,
+	),
+	Action: func(c *cli.Context) error {
+		addr, err := getAddress(c, addressFlag)
+		if err != nil {
+			return err
+		}
+
+		atBlkNum, err := getUint64(c, atFlag)
+		if err != nil {
+			return err
+		}
+
+		balance, err := newClient().GetAddressBalanceAt(context.Background(), addr, atBlkNum)
+		if err != nil {
+			return err
+		}
+
+		return printlnJSON(balance)
+	},
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/urfave/cli"
+)
+
+var cmdAddressHistory = cli.Command{
+	Name:  "history",
+	Usage: "get tx history of address",
+	Flags: flags(
+		addressFlag,
+		fromFlag,
+		toFlag,
+		dirFlag,
+		offsetFlag,
+		limitFlag,
+	),
+	Action: func(c *cli.Context) error {
+		addr, err := getAddress(c, addressFlag)
+		if err != nil {
+			return err
+		}
+		fromBlkNum, err := getUint64(c, fromFlag)
+		if err != nil {
+			return err
+		}
+		toBlkNum, err := getUint64(c, toFlag)
+		if err != nil {
+			return err
+		}
+		offset, err := getUint64(c, offsetFlag)
+		if err != nil {
+			return err
+		}
+		limit, err := getUint64(c, limitFlag)
+		if err != nil {
+			return err
+		}
+
+		atxes, total, err := newClient().GetAddressTxes(context.Background(), addr, core.AddressTxFilter{
+			FromBlockNumber: fromBlkNum,
+			ToBlockNumber:   toBlkNum,
+			Direction:       getString(c, dirFlag),
+			Offset:          offset,
+			Limit:           limit,
+		})
+		if err != nil {
+			return err
+		}
+
+		return printlnJSON(map[string]interface{}{
+			"txes":  atxes,
+			"total": total,
+		})
+	},
+}
@@ -11,18 +11,34 @@ var cmdAddressUTXOs = cli.Command{
 	Usage: "get utxos in address",
 	Flags: flags(
 		addressFlag,
+		minFlag,
+		limitFlag,
+		atFlag,
 	),
 	Action: func(c *cli.Context) error {
 		addr, err := getAddress(c, addressFlag)
 		if err != nil {
 			return err
 		}
+		minAmount, err := getBigInt(c, minFlag)
+		if err != nil {
+			return err
+		}
+		limit, err := getUint64(c, limitFlag)
+		if err != nil {
+			return err
+		}
+
+		atBlkNum, err := getUint64(c, atFlag)
+		if err != nil {
+			return err
+		}
 
-		utxoPoses, err := newClient().GetAddressUTXOs(context.Background(), addr)
+		utxos, err := newClient().GetAddressUTXOsAt(context.Background(), addr, atBlkNum, minAmount, limit)
 		if err != nil {
 			return err
 		}
 
-		return printlnJSON(utxoPoses)
+		return printlnJSON(utxos)
 	},
 }
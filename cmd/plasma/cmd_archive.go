@@ -0,0 +1,11 @@
+package main
+
+import "github.com/urfave/cli"
+
+var cmdArchive = cli.Command{
+	Name:  "archive",
+	Usage: "commands for block archive",
+	Subcommands: []cli.Command{
+		cmdArchiveServe,
+	},
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/urfave/cli"
+)
+
+var cmdArchiveServe = cli.Command{
+	Name:  "serve",
+	Usage: "serve block archive as static files",
+	Flags: flags(
+		archiveFlag,
+		portFlag,
+	),
+	Action: func(c *cli.Context) error {
+		dir := getString(c, archiveFlag)
+		if dir == "" {
+			return fmt.Errorf("invalid archive")
+		}
+		port, err := getUint64(c, portFlag)
+		if err != nil {
+			return err
+		}
+
+		return http.ListenAndServe(fmt.Sprintf(":%d", port), http.FileServer(http.Dir(dir)))
+	},
+}
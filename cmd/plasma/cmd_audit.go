@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/urfave/cli"
+)
+
+var cmdAudit = cli.Command{
+	Name:  "audit",
+	Usage: "reconcile child chain supply with root chain funds",
+	Flags: flags(
+		dbFlag,
+		engineFlag,
+	),
+	Action: func(c *cli.Context) error {
+		var report *core.AuditReport
+
+		// an offline database directory, or the child chain API
+		if dir := getString(c, dbFlag); dir != "" {
+			db, err := openDB(c, dir)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			rc, err := newRootChain()
+			if err != nil {
+				return err
+			}
+
+			report, err = app.AuditDB(db, rc)
+			if err != nil {
+				return err
+			}
+		} else {
+			var err error
+			report, err = newClient().GetAudit(context.Background(), conf.ChildChain.AdminToken)
+			if err != nil {
+				return err
+			}
+		}
+
+		return printlnJSON(map[string]interface{}{
+			"sound":  report.IsSound(),
+			"report": report,
+		})
+	},
+}
@@ -8,8 +8,10 @@ var cmdBlock = cli.Command{
 	Name:  "block",
 	Usage: "commands for block",
 	Subcommands: []cli.Command{
+		cmdBlockCheck,
 		cmdBlockCommit,
 		cmdBlockFix,
+		cmdBlockFraud,
 		cmdBlockGet,
 	},
 }
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+
+	"github.com/urfave/cli"
+)
+
+var cmdBlockCheck = cli.Command{
+	Name:  "check",
+	Usage: "compare blocks with roots committed to root chain",
+	Flags: flags(),
+	Action: func(c *cli.Context) error {
+		report, err := newClient().GetConsistency(context.Background(), conf.ChildChain.AdminToken)
+		if err != nil {
+			return err
+		}
+
+		return printlnJSON(map[string]interface{}{
+			"consistent": report.IsConsistent(),
+			"report":     report,
+		})
+	},
+}
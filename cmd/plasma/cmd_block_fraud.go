@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/client"
+	"github.com/urfave/cli"
+)
+
+var cmdBlockFraud = cli.Command{
+	Name:  "fraud",
+	Usage: "find evidence of invalid txes in committed blocks by re-validating them locally",
+	Flags: flags(
+		archiveFlag,
+	),
+	Action: func(c *cli.Context) error {
+		rc, err := newRootChain()
+		if err != nil {
+			return err
+		}
+
+		// the blocks are checked against the root chain, so they can be read from any API or archive
+		report, err := client.FindFraud(context.Background(), newBlockReader(c), rc)
+		if err != nil {
+			return err
+		}
+
+		return printlnJSON(map[string]interface{}{
+			"valid":  report.IsValid(),
+			"report": report,
+		})
+	},
+}
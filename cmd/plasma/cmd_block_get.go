@@ -12,6 +12,7 @@ var cmdBlockGet = cli.Command{
 	Flags: flags(
 		numFlag,
 		encodedFlag,
+		archiveFlag,
 	),
 	Action: func(c *cli.Context) error {
 		blkNum, err := getUint64(c, numFlag)
@@ -19,7 +20,7 @@ var cmdBlockGet = cli.Command{
 			return err
 		}
 
-		blk, err := newClient().GetBlock(context.Background(), blkNum)
+		blk, err := newBlockReader(c).GetBlock(context.Background(), blkNum)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+var cmdDB = cli.Command{
+	Name:  "db",
+	Usage: "commands for child chain database",
+	Subcommands: []cli.Command{
+		cmdDBBackup,
+		cmdDBExport,
+		cmdDBRestore,
+		cmdDBStats,
+		cmdDBVerify,
+	},
+}
+
+// openBackups opens a comma-separated list of backup files in the order they were taken.
+func openBackups(c *cli.Context, f cli.Flag) ([]io.Reader, func(), error) {
+	pathsStr := getString(c, f)
+	if pathsStr == "" {
+		return nil, nil, fmt.Errorf("invalid %s", f.GetName())
+	}
+
+	files := []*os.File{}
+	closeFiles := func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}
+
+	rs := []io.Reader{}
+	for _, p := range strings.Split(pathsStr, ",") {
+		file, err := os.Open(p)
+		if err != nil {
+			closeFiles()
+			return nil, nil, err
+		}
+		files = append(files, file)
+		rs = append(rs, file)
+	}
+
+	return rs, closeFiles, nil
+}
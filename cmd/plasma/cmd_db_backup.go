@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli"
+)
+
+var cmdDBBackup = cli.Command{
+	Name:  "backup",
+	Usage: "take online backup of child chain database",
+	Flags: flags(
+		outFlag,
+		sinceFlag,
+	),
+	Action: func(c *cli.Context) error {
+		out := getString(c, outFlag)
+		if out == "" {
+			return fmt.Errorf("invalid out")
+		}
+		since, err := getUint64(c, sinceFlag)
+		if err != nil {
+			return err
+		}
+
+		// a failed backup is never left as a complete-looking file
+		tmpPath := out + ".tmp"
+		file, err := os.Create(tmpPath)
+		if err != nil {
+			return err
+		}
+
+		version, err := newClient().Backup(context.Background(), file, since, conf.ChildChain.AdminToken)
+		file.Close()
+		if err != nil {
+			os.Remove(tmpPath)
+			return err
+		}
+		if err := os.Rename(tmpPath, out); err != nil {
+			return err
+		}
+
+		return printlnJSON(map[string]interface{}{
+			"out":     out,
+			"since":   since,
+			"version": version,
+		})
+	},
+}
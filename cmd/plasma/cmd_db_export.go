@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/urfave/cli"
+)
+
+var cmdDBExport = cli.Command{
+	Name:  "export",
+	Usage: "export blocks and utxo set as NDJSON",
+	Flags: flags(
+		dbFlag,
+		engineFlag,
+	),
+	Action: func(c *cli.Context) error {
+		// an offline database directory, or the child chain API
+		if dir := getString(c, dbFlag); dir != "" {
+			db, err := openDB(c, dir)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			return app.ExportDB(db, os.Stdout)
+		}
+
+		return newClient().Export(context.Background(), os.Stdout, conf.ChildChain.AdminToken)
+	},
+}
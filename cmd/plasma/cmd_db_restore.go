@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/urfave/cli"
+)
+
+var cmdDBRestore = cli.Command{
+	Name:  "restore",
+	Usage: "restore backups into empty database directory",
+	Flags: flags(
+		dbFlag,
+		inFlag,
+	),
+	Action: func(c *cli.Context) error {
+		dir := getString(c, dbFlag)
+		if dir == "" {
+			return fmt.Errorf("invalid db")
+		}
+		rs, closeBackups, err := openBackups(c, inFlag)
+		if err != nil {
+			return err
+		}
+		defer closeBackups()
+
+		if err := app.RestoreDB(dir, rs...); err != nil {
+			return err
+		}
+
+		return printlnJSON(map[string]interface{}{
+			"db": dir,
+		})
+	},
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+
+	"github.com/urfave/cli"
+)
+
+var cmdDBStats = cli.Command{
+	Name:  "stats",
+	Usage: "report database sizes and key counts",
+	Flags: flags(
+		dbFlag,
+		engineFlag,
+	),
+	Action: func(c *cli.Context) error {
+		// an offline database directory, or the child chain API
+		if dir := getString(c, dbFlag); dir != "" {
+			db, err := openDB(c, dir)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			stats, err := db.Stats()
+			if err != nil {
+				return err
+			}
+
+			return printlnJSON(stats)
+		}
+
+		stats, err := newClient().GetDBStats(context.Background(), conf.ChildChain.AdminToken)
+		if err != nil {
+			return err
+		}
+
+		return printlnJSON(stats)
+	},
+}
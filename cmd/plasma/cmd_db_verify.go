@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/urfave/cli"
+)
+
+var cmdDBVerify = cli.Command{
+	Name:  "verify",
+	Usage: "replay backups and compare blocks with roots committed to root chain",
+	Flags: flags(
+		inFlag,
+	),
+	Action: func(c *cli.Context) error {
+		rs, closeBackups, err := openBackups(c, inFlag)
+		if err != nil {
+			return err
+		}
+		defer closeBackups()
+
+		rc, err := newRootChain()
+		if err != nil {
+			return err
+		}
+
+		report, err := app.VerifyBackup(rc, rs...)
+		if err != nil {
+			return err
+		}
+
+		return printlnJSON(map[string]interface{}{
+			"ok":     len(report.Drifts) == 0,
+			"report": report,
+		})
+	},
+}
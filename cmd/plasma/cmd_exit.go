@@ -8,6 +8,7 @@ var cmdExit = cli.Command{
 	Name:  "exit",
 	Usage: "commands for exit",
 	Subcommands: []cli.Command{
+		cmdExitAll,
 		cmdExitChallenge,
 		cmdExitGet,
 		cmdExitProcess,
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/urfave/cli"
+)
+
+var cmdExitAll = cli.Command{
+	Name:  "all",
+	Usage: "start exits of all owned txouts in local wallet",
+	Flags: flags(
+		privKeyFlag,
+		walletFlag,
+	),
+	Action: func(c *cli.Context) error {
+		privKey, err := getPrivateKey(c, privKeyFlag)
+		if err != nil {
+			return err
+		}
+
+		w, err := loadWallet(c)
+		if err != nil {
+			return err
+		}
+
+		rc, err := newRootChain()
+		if err != nil {
+			return err
+		}
+
+		bond, err := rc.ExitBond()
+		if err != nil {
+			return err
+		}
+
+		a := types.NewAccount(privKey)
+		clnt := newClient()
+
+		// the child chain API is only used to skip spent txouts, so that exits can be started under operator withholding
+		for _, entry := range w.Entries(a.Address()) {
+			if entry.IsExiting {
+				continue
+			}
+
+			// an exit of a txout whose spend is confirmed would be challenged
+			if spender, err := clnt.GetTxOutSpender(context.Background(), entry.TxOutPosition); err == nil && !spender.ConfirmationSignature.IsNull() {
+				w.Delete(entry.TxOutPosition)
+				printlnJSON(map[string]interface{}{
+					"pos":   entry.TxOutPosition,
+					"error": "txout is spent",
+				})
+				continue
+			}
+
+			exit, err := rc.PlasmaExits(entry.TxOutPosition)
+			if err != nil {
+				return err
+			}
+			if exit.IsStarted {
+				entry.IsExiting = true
+				continue
+			}
+
+			entry.Bond = bond
+
+			// start exit
+			rctx, err := rc.StartExitWithData(a, entry.ExitData)
+			if err != nil {
+				printlnJSON(map[string]interface{}{
+					"pos":   entry.TxOutPosition,
+					"error": err.Error(),
+				})
+				continue
+			}
+			entry.IsExiting = true
+
+			printlnJSON(map[string]interface{}{
+				"pos":  entry.TxOutPosition,
+				"hash": rctx.Hash(),
+			})
+		}
+
+		return w.Save()
+	},
+}
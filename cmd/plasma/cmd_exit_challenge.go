@@ -29,18 +29,33 @@ var cmdExitChallenge = cli.Command{
 			return err
 		}
 
+		clnt := newClient()
+		ctx := context.Background()
+
 		rc, err := newRootChain()
 		if err != nil {
 			return err
 		}
 
-		spendingBlkNum, spendingTxIndex, spendingInIndex := types.ParseTxInPosition(spendingTxInPos)
-		spendingTxPos := types.NewTxPosition(spendingBlkNum, spendingTxIndex)
+		var spendingTx *types.Tx
+		var spendingInIndex uint64
+		if spendingTxInPos > 0 {
+			spendingBlkNum, spendingTxIndex, inIndex := types.ParseTxInPosition(spendingTxInPos)
+			spendingTxPos := types.NewTxPosition(spendingBlkNum, spendingTxIndex)
 
-		// get spending tx
-		spendingTx, err := newClient().GetTx(context.Background(), spendingTxPos)
-		if err != nil {
-			return err
+			// get spending tx
+			spendingTx, err = clnt.GetTx(ctx, spendingTxPos)
+			if err != nil {
+				return err
+			}
+			spendingInIndex = inIndex
+		} else {
+			// find spending tx
+			spender, err := clnt.GetTxOutSpender(ctx, txOutPos)
+			if err != nil {
+				return err
+			}
+			spendingTx, spendingInIndex = spender.Tx, spender.InputIndex
 		}
 
 		// challenge exit
@@ -13,6 +13,7 @@ var cmdExitStart = cli.Command{
 	Flags: flags(
 		posFlag,
 		privKeyFlag,
+		walletFlag,
 	),
 	Action: func(c *cli.Context) error {
 		txOutPos, err := getPosition(c, posFlag)
@@ -24,31 +25,32 @@ var cmdExitStart = cli.Command{
 			return err
 		}
 
-		clnt := newClient()
-		ctx := context.Background()
-
 		rc, err := newRootChain()
 		if err != nil {
 			return err
 		}
 
-		blkNum, txIndex, _ := types.ParseTxOutPosition(txOutPos)
-		txPos := types.NewTxPosition(blkNum, txIndex)
-
-		// get tx
-		tx, err := clnt.GetTx(ctx, txPos)
+		// get exit data, from local wallet if the child chain API is not available
+		exitData, err := newClient().GetTxOutExitData(context.Background(), txOutPos)
 		if err != nil {
-			return err
+			w, werr := loadWallet(c)
+			if werr != nil {
+				return werr
+			}
+			entry := w.Get(txOutPos)
+			if entry == nil {
+				return err
+			}
+			exitData = entry.ExitData
 		}
 
-		// get tx proof
-		txProofBytes, err := clnt.GetTxProof(ctx, txPos)
-		if err != nil {
+		// the bond is required by the root chain, whatever the child chain says
+		if exitData.Bond, err = rc.ExitBond(); err != nil {
 			return err
 		}
 
 		// start exit
-		rctx, err := rc.StartExit(types.NewAccount(privKey), txOutPos, tx, txProofBytes)
+		rctx, err := rc.StartExitWithData(types.NewAccount(privKey), exitData)
 		if err != nil {
 			return err
 		}
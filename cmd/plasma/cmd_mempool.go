@@ -0,0 +1,13 @@
+package main
+
+import "github.com/urfave/cli"
+
+var cmdMempool = cli.Command{
+	Name:  "mempool",
+	Usage: "commands for mempool",
+	Subcommands: []cli.Command{
+		cmdMempoolCancel,
+		cmdMempoolGet,
+		cmdMempoolList,
+	},
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/urfave/cli"
+)
+
+var cmdMempoolCancel = cli.Command{
+	Name:  "cancel",
+	Usage: "cancel tx in mempool (as input owner or operator)",
+	Flags: flags(
+		hashFlag,
+		privKeyFlag,
+	),
+	Action: func(c *cli.Context) error {
+		txHash, err := getHash(c, hashFlag)
+		if err != nil {
+			return err
+		}
+		privKey, err := getPrivateKey(c, privKeyFlag)
+		if err != nil {
+			return err
+		}
+
+		clnt := newClient()
+		ctx := context.Background()
+
+		// get mempool tx
+		mtx, err := clnt.GetMempoolTx(ctx, txHash)
+		if err != nil {
+			return err
+		}
+
+		// sign cancellation
+		cancelSig, err := mtx.Tx.SignCancellation(types.NewAccount(privKey))
+		if err != nil {
+			return err
+		}
+
+		// cancel tx
+		removedTxHashes, err := clnt.DeleteMempoolTx(ctx, txHash, cancelSig)
+		if err != nil {
+			return err
+		}
+
+		return printlnJSON(map[string][]common.Hash{
+			"removed": removedTxHashes,
+		})
+	},
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+
+	"github.com/urfave/cli"
+)
+
+var cmdMempoolGet = cli.Command{
+	Name:  "get",
+	Usage: "get tx in mempool",
+	Flags: flags(
+		hashFlag,
+		encodedFlag,
+	),
+	Action: func(c *cli.Context) error {
+		txHash, err := getHash(c, hashFlag)
+		if err != nil {
+			return err
+		}
+
+		mtx, err := newClient().GetMempoolTx(context.Background(), txHash)
+		if err != nil {
+			return err
+		}
+
+		if getBool(c, encodedFlag) {
+			return printlnEncodedTx(mtx.Tx)
+		}
+		return printlnJSON(mtx)
+	},
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+
+	"github.com/urfave/cli"
+)
+
+var cmdMempoolList = cli.Command{
+	Name:  "list",
+	Usage: "list txes in mempool",
+	Flags: flags(),
+	Action: func(c *cli.Context) error {
+		mtxes, err := newClient().GetMempool(context.Background())
+		if err != nil {
+			return err
+		}
+
+		return printlnJSON(mtxes)
+	},
+}
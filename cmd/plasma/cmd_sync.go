@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/client"
+	"github.com/urfave/cli"
+)
+
+var cmdSync = cli.Command{
+	Name:  "sync",
+	Usage: "verify blocks against roots committed to root chain",
+	Flags: flags(
+		addressFlag,
+		syncStateFlag,
+	),
+	Action: func(c *cli.Context) error {
+		addrs, err := getAddresses(c, addressFlag)
+		if err != nil {
+			return err
+		}
+
+		rc, err := newRootChain()
+		if err != nil {
+			return err
+		}
+
+		lc, err := client.NewLightClient(newClient(), rc, addrs, getString(c, syncStateFlag))
+		if err != nil {
+			return err
+		}
+
+		alerts := 0
+		if err := lc.Sync(context.Background(), func(alert *client.LightClientAlert) {
+			alerts++
+			printlnJSON(map[string]interface{}{
+				"alert": alert,
+			})
+		}); err != nil {
+			return err
+		}
+
+		return printlnJSON(map[string]interface{}{
+			"blknum": lc.BlockNumber(),
+			"alerts": alerts,
+		})
+	},
+}
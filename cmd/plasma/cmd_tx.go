@@ -12,5 +12,6 @@ var cmdTx = cli.Command{
 		cmdTxGet,
 		cmdTxPost,
 		cmdTxProof,
+		cmdTxStatus,
 	},
 }
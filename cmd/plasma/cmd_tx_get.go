@@ -12,6 +12,9 @@ var cmdTxGet = cli.Command{
 	Flags: flags(
 		posFlag,
 		encodedFlag,
+		archiveFlag,
+		walletFlag,
+		addressFlag,
 	),
 	Action: func(c *cli.Context) error {
 		txPos, err := getPosition(c, posFlag)
@@ -19,11 +22,24 @@ var cmdTxGet = cli.Command{
 			return err
 		}
 
-		tx, err := newClient().GetTx(context.Background(), txPos)
+		reader := newBlockReader(c)
+
+		tx, err := reader.GetTx(context.Background(), txPos)
 		if err != nil {
 			return err
 		}
 
+		// keep the outputs of the given addresses in the local wallet, only if asked to
+		if c.IsSet(walletFlag.GetName()) {
+			addrs, err := getAddresses(c, addressFlag)
+			if err != nil {
+				return err
+			}
+			if err := saveTxToWallet(c, reader, txPos, tx, addrs); err != nil {
+				return err
+			}
+		}
+
 		if getBool(c, encodedFlag) {
 			return printlnEncodedTx(tx)
 		}
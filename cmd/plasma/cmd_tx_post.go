@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
 	"github.com/urfave/cli"
 )
@@ -17,6 +18,7 @@ var cmdTxPost = cli.Command{
 		addressFlag,
 		amountFlag,
 		privKeyFlag,
+		walletFlag,
 	),
 	Action: func(c *cli.Context) error {
 		txOutPos, err := getPosition(c, posFlag)
@@ -48,6 +50,13 @@ var cmdTxPost = cli.Command{
 			return err
 		}
 
+		a := types.NewAccount(privKey)
+
+		// keep the input in the local wallet, which is exited if the block spending it is withheld
+		if err := saveTxToWallet(c, clnt, txPos, inTx, []common.Address{a.Address()}); err != nil {
+			return err
+		}
+
 		// get input UTXO
 		inTxOut := inTx.GetOutput(outIndex)
 
@@ -74,12 +83,12 @@ var cmdTxPost = cli.Command{
 		}
 
 		// sign tx
-		if err := tx.Sign(0, types.NewAccount(privKey)); err != nil {
+		if err := tx.Sign(0, a); err != nil {
 			return err
 		}
 
 		// post tx
-		if err := clnt.PostTx(ctx, tx); err != nil {
+		if _, err := clnt.PostTx(ctx, tx); err != nil {
 			return err
 		}
 
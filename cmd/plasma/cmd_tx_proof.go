@@ -12,6 +12,7 @@ var cmdTxProof = cli.Command{
 	Usage: "get tx proof",
 	Flags: flags(
 		posFlag,
+		archiveFlag,
 	),
 	Action: func(c *cli.Context) error {
 		txPos, err := getPosition(c, posFlag)
@@ -19,7 +20,7 @@ var cmdTxProof = cli.Command{
 			return err
 		}
 
-		txProofBytes, err := newClient().GetTxProof(context.Background(), txPos)
+		txProofBytes, err := newBlockReader(c).GetTxProof(context.Background(), txPos)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/urfave/cli"
+)
+
+var cmdTxStatus = cli.Command{
+	Name:  "status",
+	Usage: "get finality status of tx by position or hash",
+	Flags: flags(
+		posFlag,
+		hashFlag,
+	),
+	Action: func(c *cli.Context) error {
+		txPos, err := getPosition(c, posFlag)
+		if err != nil {
+			return err
+		}
+		txHash, err := getHash(c, hashFlag)
+		if err != nil {
+			return err
+		}
+
+		clnt := newClient()
+		ctx := context.Background()
+
+		var f *core.TxFinality
+		if txHash != types.NullHash {
+			f, err = clnt.GetTxFinalityByHash(ctx, txHash)
+		} else {
+			f, err = clnt.GetTxFinality(ctx, txPos)
+		}
+		if err != nil {
+			return err
+		}
+
+		return printlnJSON(f)
+	},
+}
@@ -13,6 +13,7 @@ var cmdTxInConfirm = cli.Command{
 	Flags: flags(
 		posFlag,
 		privKeyFlag,
+		walletFlag,
 	),
 	Action: func(c *cli.Context) error {
 		txInPos, err := getPosition(c, posFlag)
@@ -42,7 +43,13 @@ var cmdTxInConfirm = cli.Command{
 		}
 
 		// update confirmation signature
-		if err := clnt.PutTxIn(ctx, txInPos, tx.GetInput(inIndex).ConfirmationSignature); err != nil {
+		txIn := tx.GetInput(inIndex)
+		if err := clnt.PutTxIn(ctx, txInPos, txIn.ConfirmationSignature); err != nil {
+			return err
+		}
+
+		// the confirmed input is no longer to be exited
+		if err := dropTxOutFromWallet(c, types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)); err != nil {
 			return err
 		}
 
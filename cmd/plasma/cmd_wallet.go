@@ -0,0 +1,12 @@
+package main
+
+import "github.com/urfave/cli"
+
+var cmdWallet = cli.Command{
+	Name:  "wallet",
+	Usage: "commands for local wallet",
+	Subcommands: []cli.Command{
+		cmdWalletList,
+		cmdWalletSync,
+	},
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/urfave/cli"
+)
+
+var cmdWalletList = cli.Command{
+	Name:  "list",
+	Usage: "list owned txouts in local wallet",
+	Flags: flags(
+		addressFlag,
+		walletFlag,
+	),
+	Action: func(c *cli.Context) error {
+		addr, err := getAddress(c, addressFlag)
+		if err != nil {
+			return err
+		}
+
+		w, err := loadWallet(c)
+		if err != nil {
+			return err
+		}
+
+		return printlnJSON(w.Entries(addr))
+	},
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+	"github.com/urfave/cli"
+)
+
+var cmdWalletSync = cli.Command{
+	Name:  "sync",
+	Usage: "store exit data of owned utxos in local wallet",
+	Flags: flags(
+		addressFlag,
+		walletFlag,
+	),
+	Action: func(c *cli.Context) error {
+		addr, err := getAddress(c, addressFlag)
+		if err != nil {
+			return err
+		}
+
+		w, err := loadWallet(c)
+		if err != nil {
+			return err
+		}
+
+		stored, dropped, err := newClient().SyncWallet(context.Background(), w, addr)
+		if err != nil {
+			return err
+		}
+
+		if err := w.Save(); err != nil {
+			return err
+		}
+
+		return printlnJSON(map[string]interface{}{
+			"address": utils.AddressToHex(addr),
+			"stored":  stored,
+			"dropped": dropped,
+		})
+	},
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/urfave/cli"
+)
+
+var cmdWatch = cli.Command{
+	Name:  "watch",
+	Usage: "watch exits and challenge the ones against followed addresses",
+	Flags: flags(
+		addressFlag,
+		intervalFlag,
+		privKeyFlag,
+		stateFlag,
+	),
+	Action: func(c *cli.Context) error {
+		addrs, err := getAddresses(c, addressFlag)
+		if err != nil {
+			return err
+		}
+		interval, err := getUint64(c, intervalFlag)
+		if err != nil {
+			return err
+		}
+		if interval == 0 {
+			return fmt.Errorf("invalid interval")
+		}
+		privKey, err := getPrivateKey(c, privKeyFlag)
+		if err != nil {
+			return err
+		}
+
+		rc, err := newRootChain()
+		if err != nil {
+			return err
+		}
+
+		w, err := newWatcher(newClient(), rc, types.NewAccount(privKey), addrs, getString(c, stateFlag))
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+
+		if err := w.sync(ctx); err != nil {
+			return err
+		}
+
+		sink := make(chan *core.RootChainExitStarted)
+		sub, err := w.subscribe(ctx, sink)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if sub != nil {
+				sub.Unsubscribe()
+			}
+		}()
+		subErr := sub.Err()
+
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case log := <-sink:
+				if err := w.onExitStarted(ctx, log); err != nil {
+					return err
+				}
+			case <-ticker.C:
+				// resubscribe if the last attempt failed
+				if sub == nil {
+					if sub, err = w.subscribe(ctx, sink); err != nil {
+						w.reportError("subscribe", 0, err)
+					} else {
+						subErr = sub.Err()
+					}
+				}
+				if err := w.sync(ctx); err != nil {
+					return err
+				}
+			case err := <-subErr:
+				w.reportError("subscribe", 0, err)
+
+				// the events emitted until the subscription is restored are fetched by subscribe
+				sub.Unsubscribe()
+				if sub, err = w.subscribe(ctx, sink); err != nil {
+					w.reportError("subscribe", 0, err)
+					subErr = nil
+				} else {
+					subErr = sub.Err()
+				}
+			}
+		}
+	},
+}
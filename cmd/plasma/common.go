@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"os"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/client"
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
@@ -20,6 +23,72 @@ func newClient() *client.Client {
 	return client.New(conf.ChildChain.API)
 }
 
+// openDB opens the offline database directory dir, which has to exist,
+// so that a mistyped directory is not created as an empty database.
+func openDB(c *cli.Context, dir string) (*app.DB, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+
+	return app.NewDB(app.DBConfig{
+		Engine: getString(c, engineFlag),
+		Dir:    dir,
+	})
+}
+
+func loadWallet(c *cli.Context) (*client.Wallet, error) {
+	return client.LoadWallet(getString(c, walletFlag))
+}
+
+// saveTxToWallet stores the outputs of owners of the tx at txPos in the local wallet,
+// so that they can be exited even if the child chain API is not available later.
+func saveTxToWallet(c *cli.Context, reader blockReader, txPos types.Position, tx *types.Tx, owners []common.Address) error {
+	txProofBytes, err := reader.GetTxProof(context.Background(), txPos)
+	if err != nil {
+		return err
+	}
+
+	w, err := loadWallet(c)
+	if err != nil {
+		return err
+	}
+	if _, err := w.PutTx(txPos, tx, txProofBytes, owners); err != nil {
+		return err
+	}
+
+	return w.Save()
+}
+
+// dropTxOutFromWallet drops the txout at txOutPos, whose spend is confirmed, from the local wallet if stored,
+// since an exit of it would be challenged with the spending tx.
+func dropTxOutFromWallet(c *cli.Context, txOutPos types.Position) error {
+	w, err := loadWallet(c)
+	if err != nil {
+		return err
+	}
+	if w.Get(txOutPos) == nil {
+		return nil
+	}
+	w.Delete(txOutPos)
+
+	return w.Save()
+}
+
+// blockReader reads blocks from either the child chain API or a block archive.
+type blockReader interface {
+	GetBlock(ctx context.Context, blkNum uint64) (*types.Block, error)
+	GetTx(ctx context.Context, txPos types.Position) (*types.Tx, error)
+	GetTxProof(ctx context.Context, txPos types.Position) ([]byte, error)
+}
+
+func newBlockReader(c *cli.Context) blockReader {
+	if base := getString(c, archiveFlag); base != "" {
+		return client.NewArchive(base)
+	}
+
+	return newClient()
+}
+
 func newRootChain() (*core.RootChain, error) {
 	return core.NewRootChain(conf.RootChain)
 }
@@ -63,6 +132,29 @@ func getAddress(c *cli.Context, f cli.Flag) (common.Address, error) {
 	return utils.HexToAddress(addrStr), nil
 }
 
+// getAddresses parses a comma-separated list of addresses.
+func getAddresses(c *cli.Context, f cli.Flag) ([]common.Address, error) {
+	addrs := []common.Address{}
+	for _, addrStr := range strings.Split(getString(c, f), ",") {
+		if !utils.IsHexAddress(addrStr) {
+			return nil, fmt.Errorf("invalid address hex")
+		}
+		addrs = append(addrs, utils.HexToAddress(addrStr))
+	}
+
+	return addrs, nil
+}
+
+func getHash(c *cli.Context, f cli.Flag) (common.Hash, error) {
+	hashStr := getString(c, f)
+
+	if !utils.IsHexHash(hashStr) {
+		return types.NullHash, fmt.Errorf("invalid hash hex")
+	}
+
+	return utils.HexToHash(hashStr), nil
+}
+
 func getPrivateKey(c *cli.Context, f cli.Flag) (*ecdsa.PrivateKey, error) {
 	return utils.HexToPrivateKey(getString(c, f))
 }
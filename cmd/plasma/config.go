@@ -8,5 +8,6 @@ type Config struct {
 }
 
 type ChildChainConfig struct {
-	API string `json:"api"`
+	API        string `json:"api"`
+	AdminToken string `json:"admintoken"`
 }
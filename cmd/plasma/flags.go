@@ -12,16 +12,35 @@ var (
 	confFlag = cli.StringFlag{Name: "conf", Value: "config.json", EnvVar: "PLASMA_CLI_CONFIG"}
 
 	// command options
-	addressFlag = cli.StringFlag{Name: "address", Value: nullAddressStr}
-	amountFlag  = cli.StringFlag{Name: "amount", Value: "0"}
-	directFlag  = cli.BoolFlag{Name: "direct"}
-	encodedFlag = cli.BoolFlag{Name: "encoded"}
-	indexFlag   = cli.StringFlag{Name: "index", Value: "0"}
-	numFlag     = cli.StringFlag{Name: "num", Value: "0"}
-	posFlag     = cli.StringFlag{Name: "pos", Value: "0"}
-	privKeyFlag = cli.StringFlag{Name: "privkey", Value: ""}
-	txFlag      = cli.StringFlag{Name: "tx", Value: ""}
-	vsPosFlag   = cli.StringFlag{Name: "vspos", Value: "0"}
+	addressFlag   = cli.StringFlag{Name: "address", Value: nullAddressStr}
+	amountFlag    = cli.StringFlag{Name: "amount", Value: "0"}
+	archiveFlag   = cli.StringFlag{Name: "archive", Value: ""}
+	atFlag        = cli.StringFlag{Name: "at", Value: "0"}
+	dbFlag        = cli.StringFlag{Name: "db", Value: ""}
+	dirFlag       = cli.StringFlag{Name: "dir", Value: ""}
+	directFlag    = cli.BoolFlag{Name: "direct"}
+	encodedFlag   = cli.BoolFlag{Name: "encoded"}
+	engineFlag    = cli.StringFlag{Name: "engine", Value: "badger"}
+	fromFlag      = cli.StringFlag{Name: "from", Value: "0"}
+	hashFlag      = cli.StringFlag{Name: "hash", Value: nullHashStr}
+	inFlag        = cli.StringFlag{Name: "in", Value: ""}
+	indexFlag     = cli.StringFlag{Name: "index", Value: "0"}
+	intervalFlag  = cli.StringFlag{Name: "interval", Value: "10"}
+	limitFlag     = cli.StringFlag{Name: "limit", Value: "0"}
+	minFlag       = cli.StringFlag{Name: "min", Value: "0"}
+	numFlag       = cli.StringFlag{Name: "num", Value: "0"}
+	offsetFlag    = cli.StringFlag{Name: "offset", Value: "0"}
+	outFlag       = cli.StringFlag{Name: "out", Value: ""}
+	portFlag      = cli.StringFlag{Name: "port", Value: "8080"}
+	posFlag       = cli.StringFlag{Name: "pos", Value: "0"}
+	privKeyFlag   = cli.StringFlag{Name: "privkey", Value: ""}
+	sinceFlag     = cli.StringFlag{Name: "since", Value: "0"}
+	stateFlag     = cli.StringFlag{Name: "state", Value: "watch.json"}
+	syncStateFlag = cli.StringFlag{Name: "sync-state", Value: "sync.json"}
+	toFlag        = cli.StringFlag{Name: "to", Value: "0"}
+	txFlag        = cli.StringFlag{Name: "tx", Value: ""}
+	vsPosFlag     = cli.StringFlag{Name: "vspos", Value: "0"}
+	walletFlag    = cli.StringFlag{Name: "wallet", Value: "wallet.json"}
 )
 
 func flags(fs ...cli.Flag) []cli.Flag {
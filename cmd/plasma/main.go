@@ -24,12 +24,19 @@ func main() {
 	app := cli.NewApp()
 	app.Commands = []cli.Command{
 		cmdAddress,
+		cmdArchive,
+		cmdAudit,
 		cmdBlock,
+		cmdDB,
 		cmdDeploy,
 		cmdDeposit,
 		cmdExit,
+		cmdMempool,
+		cmdSync,
 		cmdTx,
 		cmdTxIn,
+		cmdWallet,
+		cmdWatch,
 	}
 	app.Flags = []cli.Flag{
 		confFlag,
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/client"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+const (
+	watchExitStatusIgnored    = "ignored"    // the txout is not spent to a followed address
+	watchExitStatusPending    = "pending"    // to be retried at the next sync
+	watchExitStatusChallenged = "challenged" // a challenge was sent
+	watchExitStatusClosed     = "closed"     // the exit is no longer valid
+)
+
+// watchTx is a tx received by a followed address, kept so that exits can be challenged
+// even when the child chain API is not available.
+type watchTx struct {
+	Tx          string `json:"tx"`
+	IsConfirmed bool   `json:"confirmed"`
+}
+
+type watchExit struct {
+	Owner     common.Address `json:"owner"`
+	Amount    *big.Int       `json:"amount"`
+	Status    string         `json:"status"`
+	Challenge *common.Hash   `json:"challenge,omitempty"`
+}
+
+// watchState is what the watcher keeps in its state file.
+// The subscription to ExitStarted events does not deliver the events emitted before it started,
+// so the events since LastBlockNumber are fetched on every (re)subscription,
+// and exits that are already handled are skipped by their status.
+type watchState struct {
+	LastBlockNumber uint64                        `json:"last_block"` // the latest root chain block whose events are handled
+	Txes            map[types.Position]*watchTx   `json:"txes"`
+	Exits           map[types.Position]*watchExit `json:"exits"`
+}
+
+func newWatchState() *watchState {
+	return &watchState{
+		Txes:  map[types.Position]*watchTx{},
+		Exits: map[types.Position]*watchExit{},
+	}
+}
+
+func loadWatchState(path string) (*watchState, error) {
+	state := newWatchState()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// save writes the state to a temporary file first so that the state file is never left half written.
+func (state *watchState) save(path string) error {
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// watchReport is a line of the watcher output.
+type watchReport struct {
+	Event     string          `json:"event"`
+	Address   *common.Address `json:"address,omitempty"`
+	Position  types.Position  `json:"pos,omitempty"`
+	Owner     *common.Address `json:"owner,omitempty"`
+	Amount    *big.Int        `json:"amount,omitempty"`
+	Status    string          `json:"status,omitempty"`
+	Source    string          `json:"source,omitempty"`
+	Challenge *common.Hash    `json:"challenge,omitempty"`
+	Txes      int             `json:"txes,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// watcher challenges exits of txouts that were spent to the followed addresses.
+type watcher struct {
+	clnt      *client.Client
+	rc        *core.RootChain
+	account   *types.Account
+	addrs     map[common.Address]bool
+	state     *watchState
+	statePath string
+}
+
+func newWatcher(clnt *client.Client, rc *core.RootChain, account *types.Account, addrs []common.Address, statePath string) (*watcher, error) {
+	state, err := loadWatchState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &watcher{
+		clnt:      clnt,
+		rc:        rc,
+		account:   account,
+		addrs:     map[common.Address]bool{},
+		state:     state,
+		statePath: statePath,
+	}
+	for _, addr := range addrs {
+		w.addrs[addr] = true
+	}
+
+	return w, nil
+}
+
+func (w *watcher) report(r *watchReport) {
+	printlnJSON(r)
+}
+
+func (w *watcher) reportError(event string, pos types.Position, err error) {
+	w.report(&watchReport{
+		Event:    event,
+		Position: pos,
+		Error:    err.Error(),
+	})
+}
+
+// sync stores the txes received by the followed addresses and retries pending exits.
+func (w *watcher) sync(ctx context.Context) error {
+	for addr := range w.addrs {
+		addr := addr
+
+		atxes, _, err := w.clnt.GetAddressTxes(ctx, addr, core.AddressTxFilter{
+			Direction: core.AddressTxDirectionIn,
+		})
+		if err != nil {
+			w.reportError("sync", 0, err)
+			continue
+		}
+
+		n := 0
+		for _, atx := range atxes {
+			if wtx, ok := w.state.Txes[atx.Position]; ok && wtx.IsConfirmed {
+				continue
+			}
+
+			tx, err := w.clnt.GetTx(ctx, atx.Position)
+			if err != nil {
+				w.reportError("sync", atx.Position, err)
+				continue
+			}
+			if err := w.storeTx(atx.Position, tx); err != nil {
+				return err
+			}
+			n++
+		}
+
+		if n > 0 {
+			w.report(&watchReport{
+				Event:   "sync",
+				Address: &addr,
+				Txes:    n,
+			})
+		}
+	}
+
+	for txOutPos, exit := range w.state.Exits {
+		if exit.Status != watchExitStatusPending {
+			continue
+		}
+		if err := w.handleExit(ctx, txOutPos, exit); err != nil {
+			return err
+		}
+	}
+
+	return w.state.save(w.statePath)
+}
+
+func (w *watcher) storeTx(txPos types.Position, tx *types.Tx) error {
+	txBytes, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return err
+	}
+
+	isConfirmed := true
+	for _, txIn := range tx.Inputs {
+		if !txIn.IsNull() && txIn.ConfirmationSignature.IsNull() {
+			isConfirmed = false
+		}
+	}
+
+	w.state.Txes[txPos] = &watchTx{
+		Tx:          utils.EncodeToHex(txBytes),
+		IsConfirmed: isConfirmed,
+	}
+
+	return nil
+}
+
+// subscribe subscribes to ExitStarted events, sent to sink, and then handles the events emitted
+// since the last handled root chain block, which the subscription does not deliver.
+func (w *watcher) subscribe(ctx context.Context, sink chan<- *core.RootChainExitStarted) (event.Subscription, error) {
+	sub, err := w.rc.WatchExitStarted(ctx, sink)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.catchUp(ctx); err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// catchUp handles the ExitStarted events emitted since the last handled root chain block.
+// The events of that block are fetched again, since only some of them may have been handled.
+func (w *watcher) catchUp(ctx context.Context) error {
+	logs, latestBlkNum, err := w.rc.FilterExitStartedFrom(ctx, w.state.LastBlockNumber)
+	if err != nil {
+		return err
+	}
+
+	for _, log := range logs {
+		if err := w.onExitStarted(ctx, log); err != nil {
+			return err
+		}
+	}
+
+	if latestBlkNum > w.state.LastBlockNumber {
+		w.state.LastBlockNumber = latestBlkNum
+	}
+
+	return w.state.save(w.statePath)
+}
+
+// onExitStarted handles an ExitStarted event unless the exit is already handled.
+func (w *watcher) onExitStarted(ctx context.Context, log *core.RootChainExitStarted) error {
+	if log.Raw.BlockNumber > w.state.LastBlockNumber {
+		w.state.LastBlockNumber = log.Raw.BlockNumber
+	}
+
+	txOutPos := types.Position(log.UtxoPosition.Uint64())
+	if _, ok := w.state.Exits[txOutPos]; ok {
+		return w.state.save(w.statePath)
+	}
+
+	exit := &watchExit{
+		Owner:  log.Owner,
+		Amount: log.Amount,
+		Status: watchExitStatusPending,
+	}
+	w.state.Exits[txOutPos] = exit
+
+	if err := w.handleExit(ctx, txOutPos, exit); err != nil {
+		return err
+	}
+
+	return w.state.save(w.statePath)
+}
+
+func (w *watcher) handleExit(ctx context.Context, txOutPos types.Position, exit *watchExit) error {
+	r := &watchReport{
+		Event:    "exit",
+		Position: txOutPos,
+		Owner:    &exit.Owner,
+		Amount:   exit.Amount,
+	}
+	defer func() {
+		r.Status = exit.Status
+		r.Challenge = exit.Challenge
+		w.report(r)
+	}()
+
+	rcExit, err := w.rc.PlasmaExits(txOutPos)
+	if err != nil {
+		r.Error = err.Error()
+		return nil
+	}
+	if !rcExit.IsStarted || !rcExit.IsValid {
+		exit.Status = watchExitStatusClosed
+		return nil
+	}
+
+	spendingTx, spendingInIndex, source, err := w.findSpender(ctx, txOutPos)
+	if err != nil {
+		r.Error = err.Error()
+		return nil
+	}
+	if spendingTx == nil {
+		exit.Status = watchExitStatusIgnored
+		return nil
+	}
+	r.Source = source
+
+	if spendingTx.GetInput(spendingInIndex).ConfirmationSignature.IsNull() {
+		r.Error = core.ErrNullConfirmationSignature.Error()
+		return nil
+	}
+
+	rctx, err := w.rc.ChallengeExit(w.account, txOutPos, spendingTx, spendingInIndex)
+	if err != nil {
+		r.Error = err.Error()
+		return nil
+	}
+
+	challenge := rctx.Hash()
+	exit.Status = watchExitStatusChallenged
+	exit.Challenge = &challenge
+
+	return nil
+}
+
+// findSpender returns the tx that spent the txout at txOutPos to a followed address
+// and where it was found. The stored txes are preferred over the API,
+// unless they lack the confirmation signature. It returns a nil tx if there is no such tx.
+func (w *watcher) findSpender(ctx context.Context, txOutPos types.Position) (*types.Tx, uint64, string, error) {
+	var found *types.Tx
+	var foundInIndex uint64
+	for _, wtx := range w.state.Txes {
+		tx, err := decodeWatchTx(wtx)
+		if err != nil {
+			return nil, 0, "", err
+		}
+
+		for i, txIn := range tx.Inputs {
+			if txIn.IsNull() {
+				continue
+			}
+			if types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex) != txOutPos {
+				continue
+			}
+			found, foundInIndex = tx, uint64(i)
+			if !txIn.ConfirmationSignature.IsNull() {
+				return found, foundInIndex, "local", nil
+			}
+		}
+	}
+
+	spender, err := w.clnt.GetTxOutSpender(ctx, txOutPos)
+	if err != nil {
+		if appErr, ok := err.(*app.Error); ok && appErr.Is(app.ErrTxOutNotSpent) {
+			return found, foundInIndex, "local", nil
+		}
+		if found != nil {
+			return found, foundInIndex, "local", nil
+		}
+		return nil, 0, "", err
+	}
+	if !w.isFollowed(spender.Tx) {
+		return found, foundInIndex, "local", nil
+	}
+
+	if err := w.storeTx(spender.TxPosition, spender.Tx); err != nil {
+		return nil, 0, "", err
+	}
+
+	return spender.Tx, spender.InputIndex, "api", nil
+}
+
+func (w *watcher) isFollowed(tx *types.Tx) bool {
+	for _, txOut := range tx.Outputs {
+		if w.addrs[txOut.OwnerAddress] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func decodeWatchTx(wtx *watchTx) (*types.Tx, error) {
+	txBytes, err := utils.DecodeHex(wtx.Tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx types.Tx
+	if err := rlp.DecodeBytes(txBytes, &tx); err != nil {
+		return nil, err
+	}
+
+	return &tx, nil
+}
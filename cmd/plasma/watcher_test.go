@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/app"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/client"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWatcher(t *testing.T, handler http.HandlerFunc, addrs ...common.Address) (*watcher, func()) {
+	dir, err := ioutil.TempDir("", "mmpc-watch")
+	require.NoError(t, err)
+	server := httptest.NewServer(handler)
+
+	w, err := newWatcher(client.New(server.URL), nil, nil, addrs, filepath.Join(dir, "state.json"))
+	require.NoError(t, err)
+
+	return w, func() {
+		server.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestWatchState_Save(t *testing.T) {
+	w, closeWatcher := newTestWatcher(t, nil)
+	defer closeWatcher()
+
+	// no state file yet
+	require.Empty(t, w.state.Exits)
+
+	w.state.Exits[types.NewTxOutPosition(1, 0, 0)] = &watchExit{
+		Amount: big.NewInt(100),
+		Status: watchExitStatusIgnored,
+	}
+	require.NoError(t, w.state.save(w.statePath))
+
+	state, err := loadWatchState(w.statePath)
+	require.NoError(t, err)
+	require.Equal(t, w.state, state)
+}
+
+func TestWatcher_FindSpender(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	a := types.NewAccount(privKey)
+
+	// the operator does not know the spender
+	w, closeWatcher := newTestWatcher(t, func(rw http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(rw).Encode(map[string]interface{}{
+			"state":  app.ResponseStateError,
+			"result": app.ErrTxOutNotSpent,
+		})
+	}, a.Address())
+	defer closeWatcher()
+
+	txOutPos := types.NewTxOutPosition(1, 0, 0)
+	spendingTx := types.NewTx()
+	require.NoError(t, spendingTx.SetInput(0, types.NewTxIn(types.ParseTxOutPosition(txOutPos))))
+	require.NoError(t, spendingTx.SetOutput(0, types.NewTxOut(a.Address(), big.NewInt(100))))
+	require.NoError(t, spendingTx.Sign(0, a))
+
+	// nothing is stored yet
+	tx, _, _, err := w.findSpender(context.Background(), txOutPos)
+	require.NoError(t, err)
+	require.Nil(t, tx)
+
+	// an unconfirmed spender is still found locally when the API does not know better
+	require.NoError(t, w.storeTx(types.NewTxPosition(2, 0), spendingTx))
+	require.False(t, w.state.Txes[types.NewTxPosition(2, 0)].IsConfirmed)
+	tx, inIndex, source, err := w.findSpender(context.Background(), txOutPos)
+	require.NoError(t, err)
+	require.NotNil(t, tx)
+	require.Equal(t, uint64(0), inIndex)
+	require.Equal(t, "local", source)
+
+	// a confirmed spender is found without asking the API
+	require.NoError(t, spendingTx.Confirm(0, a))
+	require.NoError(t, w.storeTx(types.NewTxPosition(2, 0), spendingTx))
+	require.True(t, w.state.Txes[types.NewTxPosition(2, 0)].IsConfirmed)
+	tx, _, source, err = w.findSpender(context.Background(), txOutPos)
+	require.NoError(t, err)
+	require.Equal(t, spendingTx.GetInput(0).ConfirmationSignature, tx.GetInput(0).ConfirmationSignature)
+	require.Equal(t, "local", source)
+}
+
+// testRootChain is a JSON-RPC server serving the ExitStarted logs of exits, none of which is valid anymore.
+type testRootChain struct {
+	t            *testing.T
+	abi          abi.ABI
+	latestBlkNum uint64
+	logs         []gethtypes.Log
+	fromBlkNums  []uint64 // the blocks from which the logs are queried
+}
+
+func newTestRootChain(t *testing.T) (*testRootChain, *core.RootChain, func()) {
+	rootChainABI, err := abi.JSON(strings.NewReader(core.RootChainABI))
+	require.NoError(t, err)
+
+	trc := &testRootChain{
+		t:   t,
+		abi: rootChainABI,
+	}
+	server := httptest.NewServer(trc)
+
+	rc, err := core.NewRootChain(core.RootChainConfig{
+		RPC:        server.URL,
+		WS:         server.URL,
+		AddressStr: "0x0000000000000000000000000000000000000001",
+	})
+	require.NoError(t, err)
+
+	return trc, rc, server.Close
+}
+
+func (trc *testRootChain) addExitStarted(rootBlkNum uint64, owner common.Address, txOutPos types.Position) {
+	event := trc.abi.Events["ExitStarted"]
+	data, err := event.Inputs.NonIndexed().Pack(new(big.Int).SetUint64(txOutPos.Uint64()), big.NewInt(100))
+	require.NoError(trc.t, err)
+
+	trc.logs = append(trc.logs, gethtypes.Log{
+		Topics:      []common.Hash{event.Id(), owner.Hash()},
+		Data:        data,
+		BlockNumber: rootBlkNum,
+	})
+}
+
+func (trc *testRootChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	require.NoError(trc.t, json.NewDecoder(r.Body).Decode(&req))
+
+	var result interface{}
+	switch req.Method {
+	case "eth_getBlockByNumber":
+		result = &gethtypes.Header{
+			Number:     new(big.Int).SetUint64(trc.latestBlkNum),
+			Difficulty: big.NewInt(0),
+			Time:       big.NewInt(0),
+		}
+	case "eth_getLogs":
+		var q struct {
+			FromBlock hexutil.Uint64 `json:"fromBlock"`
+			ToBlock   hexutil.Uint64 `json:"toBlock"`
+		}
+		require.NoError(trc.t, json.Unmarshal(req.Params[0], &q))
+		trc.fromBlkNums = append(trc.fromBlkNums, uint64(q.FromBlock))
+
+		logs := []gethtypes.Log{}
+		for _, log := range trc.logs {
+			if log.BlockNumber >= uint64(q.FromBlock) && log.BlockNumber <= uint64(q.ToBlock) {
+				logs = append(logs, log)
+			}
+		}
+		result = logs
+	case "eth_call":
+		out, err := trc.abi.Methods["plasmaExits"].Outputs.Pack(common.Address{}, big.NewInt(0), false, false)
+		require.NoError(trc.t, err)
+		result = hexutil.Bytes(out)
+	default:
+		require.FailNow(trc.t, req.Method)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(trc.t, json.NewEncoder(w).Encode(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req.ID,
+		"result":  result,
+	}))
+}
+
+func TestWatcher_CatchUp(t *testing.T) {
+	trc, rc, closeRC := newTestRootChain(t)
+	defer closeRC()
+
+	w, closeWatcher := newTestWatcher(t, nil)
+	defer closeWatcher()
+	w.rc = rc
+
+	owner := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	txOutPos1 := types.NewTxOutPosition(1, 0, 0)
+	txOutPos2 := types.NewTxOutPosition(2, 0, 0)
+
+	// an exit started before the first start
+	trc.addExitStarted(5, owner, txOutPos1)
+	trc.latestBlkNum = 10
+
+	require.NoError(t, w.catchUp(context.Background()))
+	require.Equal(t, []uint64{0}, trc.fromBlkNums)
+	require.Equal(t, watchExitStatusClosed, w.state.Exits[txOutPos1].Status)
+	require.Equal(t, uint64(10), w.state.LastBlockNumber)
+
+	// an exit started while the watcher was down is handled on the next start
+	trc.addExitStarted(12, owner, txOutPos2)
+	trc.latestBlkNum = 15
+
+	w, err := newWatcher(w.clnt, rc, nil, nil, w.statePath)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), w.state.LastBlockNumber)
+
+	require.NoError(t, w.catchUp(context.Background()))
+	require.Equal(t, []uint64{0, 10}, trc.fromBlkNums)
+	require.Equal(t, watchExitStatusClosed, w.state.Exits[txOutPos2].Status)
+	require.Equal(t, uint64(15), w.state.LastBlockNumber)
+}
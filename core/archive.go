@@ -0,0 +1,316 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+const (
+	ArchiveManifestFileName  = "manifest.json"
+	ArchiveChecksumsFileName = "SHA256SUMS"
+)
+
+// ArchiveEntry is an archived block. The file holds the RLP encoded block
+// and is named by the block number and the root of the block.
+type ArchiveEntry struct {
+	BlockNumber uint64      `json:"blknum"`
+	Root        common.Hash `json:"root"`
+	FileName    string      `json:"file"`
+	Checksum    string      `json:"sha256"`
+}
+
+// ArchiveManifest lists the archived blocks in block number order.
+type ArchiveManifest struct {
+	Entries []*ArchiveEntry `json:"blocks"`
+}
+
+func (manifest *ArchiveManifest) get(blkNum uint64) *ArchiveEntry {
+	i := sort.Search(len(manifest.Entries), func(i int) bool {
+		return manifest.Entries[i].BlockNumber >= blkNum
+	})
+	if i < len(manifest.Entries) && manifest.Entries[i].BlockNumber == blkNum {
+		return manifest.Entries[i]
+	}
+
+	return nil
+}
+
+func archiveFileName(blkNum uint64, root common.Hash) string {
+	return fmt.Sprintf("%d_%s.rlp", blkNum, utils.HashToHex(root))
+}
+
+func archiveChecksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// BlockArchiver writes blocks to a directory that can be served as static files.
+type BlockArchiver struct {
+	mu       sync.Mutex
+	dir      string
+	manifest *ArchiveManifest
+}
+
+func NewBlockArchiver(dir string) (*BlockArchiver, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	a := &BlockArchiver{
+		dir:      dir,
+		manifest: &ArchiveManifest{},
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, ArchiveManifestFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return a, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, a.manifest); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a *BlockArchiver) Dir() string {
+	return a.dir
+}
+
+// LastBlockNumber returns the number of the last archived block, or 0 if no block is archived.
+func (a *BlockArchiver) LastBlockNumber() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.manifest.Entries) == 0 {
+		return 0
+	}
+
+	return a.manifest.Entries[len(a.manifest.Entries)-1].BlockNumber
+}
+
+// Put archives blk. Blocks have to be archived in block number order, and archived blocks are skipped.
+func (a *BlockArchiver) Put(blk *types.Block) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if n := len(a.manifest.Entries); n > 0 && blk.Number <= a.manifest.Entries[n-1].BlockNumber {
+		return nil
+	}
+
+	blkBytes, err := rlp.EncodeToBytes(blk)
+	if err != nil {
+		return err
+	}
+	rootHash, err := blk.Root()
+	if err != nil {
+		return err
+	}
+
+	entry := &ArchiveEntry{
+		BlockNumber: blk.Number,
+		Root:        rootHash,
+		FileName:    archiveFileName(blk.Number, rootHash),
+		Checksum:    archiveChecksum(blkBytes),
+	}
+	if err := a.writeFile(entry.FileName, blkBytes); err != nil {
+		return err
+	}
+
+	a.manifest.Entries = append(a.manifest.Entries, entry)
+
+	return a.writeManifest()
+}
+
+func (a *BlockArchiver) writeManifest() error {
+	b, err := json.MarshalIndent(a.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := a.writeFile(ArchiveManifestFileName, b); err != nil {
+		return err
+	}
+
+	// in the format of sha256sum
+	var sums strings.Builder
+	for _, entry := range a.manifest.Entries {
+		fmt.Fprintf(&sums, "%s  %s\n", entry.Checksum, entry.FileName)
+	}
+
+	return a.writeFile(ArchiveChecksumsFileName, []byte(sums.String()))
+}
+
+// writeFile writes to a temporary file first so that no file is ever left half written.
+func (a *BlockArchiver) writeFile(name string, b []byte) error {
+	p := filepath.Join(a.dir, name)
+	tmpPath := p + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, p)
+}
+
+// BlockArchiveReader reads blocks from an archive written by BlockArchiver,
+// either served over HTTP or in a local directory.
+// Every block is checked against its checksum and root before being returned.
+type BlockArchiveReader struct {
+	mu       sync.Mutex
+	base     string
+	manifest *ArchiveManifest
+}
+
+// NewBlockArchiveReader creates a reader of the archive at base, which is an HTTP URL or a directory.
+func NewBlockArchiveReader(base string) *BlockArchiveReader {
+	return &BlockArchiveReader{
+		base:     base,
+		manifest: &ArchiveManifest{},
+	}
+}
+
+func (r *BlockArchiveReader) isRemote() bool {
+	return strings.HasPrefix(r.base, "http://") || strings.HasPrefix(r.base, "https://")
+}
+
+func (r *BlockArchiveReader) readFile(ctx context.Context, name string) ([]byte, error) {
+	if !r.isRemote() {
+		return ioutil.ReadFile(filepath.Join(r.base, name))
+	}
+
+	u, err := url.Parse(r.base)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, name)
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, os.ErrNotExist
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get %s: %s", name, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Manifest reads the manifest of the archive again.
+func (r *BlockArchiveReader) Manifest(ctx context.Context) (*ArchiveManifest, error) {
+	b, err := r.readFile(ctx, ArchiveManifestFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest ArchiveManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.manifest = &manifest
+	r.mu.Unlock()
+
+	return &manifest, nil
+}
+
+func (r *BlockArchiveReader) getEntry(ctx context.Context, blkNum uint64) (*ArchiveEntry, error) {
+	r.mu.Lock()
+	entry := r.manifest.get(blkNum)
+	r.mu.Unlock()
+	if entry != nil {
+		return entry, nil
+	}
+
+	// the block may have been archived since the manifest was read
+	manifest, err := r.Manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if entry := manifest.get(blkNum); entry != nil {
+		return entry, nil
+	}
+
+	return nil, ErrBlockNotFound
+}
+
+func (r *BlockArchiveReader) GetBlock(ctx context.Context, blkNum uint64) (*types.Block, error) {
+	entry, err := r.getEntry(ctx, blkNum)
+	if err != nil {
+		return nil, err
+	}
+
+	blkBytes, err := r.readFile(ctx, entry.FileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrBlockNotFound
+		}
+		return nil, err
+	}
+	if archiveChecksum(blkBytes) != entry.Checksum {
+		return nil, ErrArchivedBlockCorrupted
+	}
+
+	var blk types.Block
+	if err := rlp.DecodeBytes(blkBytes, &blk); err != nil {
+		return nil, ErrArchivedBlockCorrupted
+	}
+
+	rootHash, err := blk.Root()
+	if err != nil {
+		return nil, err
+	}
+	if blk.Number != entry.BlockNumber || rootHash != entry.Root {
+		return nil, ErrArchivedBlockCorrupted
+	}
+
+	return &blk, nil
+}
+
+// GetTxProof rebuilds the proof of the tx at txPos from the archived block.
+func (r *BlockArchiveReader) GetTxProof(ctx context.Context, txPos types.Position) ([]byte, error) {
+	blkNum, txIndex := types.ParseTxPosition(txPos)
+
+	blk, err := r.GetBlock(ctx, blkNum)
+	if err != nil {
+		return nil, err
+	}
+	if !blk.IsExistTx(txIndex) {
+		return nil, ErrTxNotFound
+	}
+
+	tree, err := blk.MerkleTree()
+	if err != nil {
+		return nil, err
+	}
+
+	return tree.CreateMembershipProof(txIndex)
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mmpc-archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	a := types.NewAccount(privKey)
+
+	archiver, err := NewBlockArchiver(dir)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), archiver.LastBlockNumber())
+
+	blks := make([]*types.Block, 3)
+	for i := range blks {
+		tx := types.NewTx()
+		require.NoError(t, tx.SetOutput(0, types.NewTxOut(a.Address(), big.NewInt(int64(i+1)))))
+		blk, err := types.NewBlock([]*types.Tx{tx}, uint64(i+1))
+		require.NoError(t, err)
+		require.NoError(t, blk.Sign(a))
+		require.NoError(t, archiver.Put(blk))
+		blks[i] = blk
+	}
+
+	// archived blocks are skipped
+	require.NoError(t, archiver.Put(blks[0]))
+
+	// the manifest is loaded again
+	archiver, err = NewBlockArchiver(dir)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), archiver.LastBlockNumber())
+
+	r := NewBlockArchiveReader(dir)
+	ctx := context.Background()
+
+	manifest, err := r.Manifest(ctx)
+	require.NoError(t, err)
+	require.Len(t, manifest.Entries, 3)
+
+	blk, err := r.GetBlock(ctx, 2)
+	require.NoError(t, err)
+	rootHash, err := blk.Root()
+	require.NoError(t, err)
+	expectedRootHash, err := blks[1].Root()
+	require.NoError(t, err)
+	require.Equal(t, expectedRootHash, rootHash)
+	require.Equal(t, archiveFileName(2, rootHash), manifest.Entries[1].FileName)
+
+	proof, err := r.GetTxProof(ctx, types.NewTxPosition(2, 0))
+	require.NoError(t, err)
+	tree, err := blks[1].MerkleTree()
+	require.NoError(t, err)
+	expectedProof, err := tree.CreateMembershipProof(0)
+	require.NoError(t, err)
+	require.Equal(t, expectedProof, proof)
+
+	_, err = r.GetBlock(ctx, 4)
+	require.Equal(t, ErrBlockNotFound, err)
+
+	// a tampered block is detected
+	p := filepath.Join(dir, manifest.Entries[2].FileName)
+	b, err := ioutil.ReadFile(p)
+	require.NoError(t, err)
+	b[len(b)-1] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(p, b, 0644))
+	_, err = r.GetBlock(ctx, 3)
+	require.Equal(t, ErrArchivedBlockCorrupted, err)
+}
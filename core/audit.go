@@ -0,0 +1,257 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+)
+
+const (
+	AuditViolationSupplyMismatch  = "supply_mismatch"
+	AuditViolationUnderfunded     = "underfunded"
+	AuditViolationDoubleSpend     = "double_spend"
+	AuditViolationTokenMismatch   = "token_mismatch"
+	AuditViolationDepositMismatch = "deposit_mismatch"
+)
+
+// AuditViolation is an invariant broken by the child chain.
+// Position is the position of the txout concerned, if any.
+type AuditViolation struct {
+	Kind        string         `json:"kind"`
+	BlockNumber uint64         `json:"blknum,omitempty"`
+	Position    types.Position `json:"pos,omitempty"`
+	Message     string         `json:"message"`
+}
+
+// AuditReport reconciles the supply of the child chain, the sum of the txouts neither spent in a block nor exited,
+// with the funds of the root chain contract. Exits sums up the exits that were not challenged,
+// either finalized or still in the challenge period, since txouts leave the supply as soon as their exits start.
+// Deposits and exits the child chain has not caught up with yet are reported as violations.
+type AuditReport struct {
+	BlockNumber uint64            `json:"blknum"`
+	Supply      *big.Int          `json:"supply"`
+	Deposits    *big.Int          `json:"deposits"`
+	Exits       *big.Int          `json:"exits"`
+	Balance     *big.Int          `json:"balance"`
+	Violations  []*AuditViolation `json:"violations"`
+}
+
+func (report *AuditReport) IsSound() bool {
+	return len(report.Violations) == 0
+}
+
+func (report *AuditReport) addViolation(kind string, blkNum uint64, txOutPos types.Position, msg string) {
+	report.Violations = append(report.Violations, &AuditViolation{
+		Kind:        kind,
+		BlockNumber: blkNum,
+		Position:    txOutPos,
+		Message:     msg,
+	})
+}
+
+// auditFunds is what the root chain holds for the child chain.
+type auditFunds struct {
+	deposits []*RootChainDepositCreated
+	exits    []*RootChainExitStarted // not challenged
+	balance  *big.Int
+}
+
+// Audit adds up the supply of the child chain and compares it with the deposits minus the exits
+// and with the balance of the root chain contract. It also checks that no txout is spent twice,
+// that the address index matches the stored txes and that every deposit block matches a DepositCreated event.
+func (cc *ChildChain) Audit(txn Txn, rc *RootChain) (*AuditReport, error) {
+	ctx := context.Background()
+
+	deposits, err := rc.FilterDepositCreated(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exitLogs, err := rc.FilterExitStarted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exits := []*RootChainExitStarted{}
+	for _, log := range exitLogs {
+		exit, err := rc.PlasmaExits(types.Position(log.UtxoPosition.Uint64()))
+		if err != nil {
+			return nil, err
+		}
+
+		// a challenged exit is invalidated and pays nothing out
+		if exit.IsStarted && !exit.IsValid {
+			continue
+		}
+
+		exits = append(exits, log)
+	}
+
+	balance, err := rc.Balance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return cc.audit(txn, &auditFunds{
+		deposits: deposits,
+		exits:    exits,
+		balance:  balance,
+	})
+}
+
+func (cc *ChildChain) audit(txn Txn, funds *auditFunds) (*AuditReport, error) {
+	currentBlkNum, err := cc.getCurrentBlockNumber(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AuditReport{
+		BlockNumber: currentBlkNum,
+		Supply:      big.NewInt(0),
+		Deposits:    big.NewInt(0),
+		Exits:       big.NewInt(0),
+		Balance:     funds.balance,
+		Violations:  []*AuditViolation{},
+	}
+
+	txOuts := map[types.Position]*types.TxOut{}
+	spendingTxInPoses := map[types.Position]types.Position{}
+	depositTxOuts := map[uint64]*types.TxOut{}
+
+	for blkNum := uint64(FirstBlockNumber); blkNum < currentBlkNum; blkNum++ {
+		for txIndex := uint64(0); ; txIndex++ {
+			// the outputs and inputs of pruned txes are kept
+			tx, err := cc.getStoredTx(txn, blkNum, txIndex)
+			if err != nil {
+				if err == ErrKeyNotFound {
+					break
+				} else {
+					return nil, err
+				}
+			}
+
+			isDeposit := true
+			for i, txIn := range tx.Inputs {
+				if txIn.IsNull() {
+					continue
+				}
+				isDeposit = false
+
+				txOutPos := types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)
+				txInPos := types.NewTxInPosition(blkNum, txIndex, uint64(i))
+				if spendingTxInPos, ok := spendingTxInPoses[txOutPos]; ok {
+					report.addViolation(AuditViolationDoubleSpend, blkNum, txOutPos,
+						fmt.Sprintf("txout is spent by txin %d and txin %d", spendingTxInPos, txInPos))
+					continue
+				}
+				spendingTxInPoses[txOutPos] = txInPos
+			}
+
+			for j, txOut := range tx.Outputs {
+				txOuts[types.NewTxOutPosition(blkNum, txIndex, uint64(j))] = txOut
+			}
+
+			// deposit txes are the only txes with an output and no inputs
+			if isDeposit && tx.Outputs[0].OwnerAddress != types.NullAddress {
+				depositTxOuts[blkNum] = tx.Outputs[0]
+			}
+		}
+	}
+
+	// compare the address index with the stored txouts
+	hasToken := map[types.Position]bool{}
+	if err := cc.iterateAllTokens(txn, func(addr common.Address, txOutPos types.Position, token *Token) error {
+		blkNum, _, _ := types.ParseTxOutPosition(txOutPos)
+
+		txOut, ok := txOuts[txOutPos]
+		if !ok || txOut.OwnerAddress != addr {
+			report.addViolation(AuditViolationTokenMismatch, blkNum, txOutPos, "token has no txout of its address")
+			return nil
+		}
+		hasToken[txOutPos] = true
+
+		switch {
+		case token.Amount.Cmp(txOut.Amount) != 0:
+			report.addViolation(AuditViolationTokenMismatch, blkNum, txOutPos, "token amount differs from txout")
+		case token.SpendingTxInPos != spendingTxInPoses[txOutPos]:
+			report.addViolation(AuditViolationTokenMismatch, blkNum, txOutPos, "token spending txin differs from blocks")
+		case token.IsExited != txOut.IsExited:
+			report.addViolation(AuditViolationTokenMismatch, blkNum, txOutPos, "token exit differs from txout")
+		case (token.IsSpent() || token.IsSpentInMempool) != txOut.IsSpent:
+			report.addViolation(AuditViolationTokenMismatch, blkNum, txOutPos, "token spend differs from txout")
+		}
+
+		// amounts sent to the null address are locked in the contract as well
+		if !token.IsSpent() && !token.IsExited {
+			report.Supply.Add(report.Supply, token.Amount)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	missingTxOutPoses := []types.Position{}
+	for txOutPos := range txOuts {
+		if !hasToken[txOutPos] {
+			missingTxOutPoses = append(missingTxOutPoses, txOutPos)
+		}
+	}
+	sort.Slice(missingTxOutPoses, func(i, j int) bool {
+		return missingTxOutPoses[i] < missingTxOutPoses[j]
+	})
+	for _, txOutPos := range missingTxOutPoses {
+		blkNum, _, _ := types.ParseTxOutPosition(txOutPos)
+		report.addViolation(AuditViolationTokenMismatch, blkNum, txOutPos, "txout has no token")
+	}
+
+	// match deposit blocks with deposit events
+	for _, deposit := range funds.deposits {
+		report.Deposits.Add(report.Deposits, deposit.Amount)
+
+		blkNum := deposit.DepositBlock.Uint64()
+		txOutPos := types.NewTxOutPosition(blkNum, 0, 0)
+
+		txOut, ok := depositTxOuts[blkNum]
+		if !ok {
+			report.addViolation(AuditViolationDepositMismatch, blkNum, txOutPos, "deposit event has no deposit block")
+			continue
+		}
+		delete(depositTxOuts, blkNum)
+
+		if txOut.OwnerAddress != deposit.Owner || txOut.Amount.Cmp(deposit.Amount) != 0 {
+			report.addViolation(AuditViolationDepositMismatch, blkNum, txOutPos, "deposit block differs from deposit event")
+		}
+	}
+
+	unmatchedBlkNums := []uint64{}
+	for blkNum := range depositTxOuts {
+		unmatchedBlkNums = append(unmatchedBlkNums, blkNum)
+	}
+	sort.Slice(unmatchedBlkNums, func(i, j int) bool {
+		return unmatchedBlkNums[i] < unmatchedBlkNums[j]
+	})
+	for _, blkNum := range unmatchedBlkNums {
+		report.addViolation(AuditViolationDepositMismatch, blkNum, types.NewTxOutPosition(blkNum, 0, 0), "deposit block has no deposit event")
+	}
+
+	// reconcile supply with funds
+	for _, exit := range funds.exits {
+		report.Exits.Add(report.Exits, exit.Amount)
+	}
+
+	expectedSupply := new(big.Int).Sub(report.Deposits, report.Exits)
+	if report.Supply.Cmp(expectedSupply) != 0 {
+		report.addViolation(AuditViolationSupplyMismatch, 0, 0,
+			fmt.Sprintf("supply %d differs from deposits minus exits %d", report.Supply, expectedSupply))
+	}
+	if report.Balance.Cmp(report.Supply) < 0 {
+		report.addViolation(AuditViolationUnderfunded, 0, 0,
+			fmt.Sprintf("contract balance %d is less than supply %d", report.Balance, report.Supply))
+	}
+
+	return report, nil
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChildChain_Audit(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNumA, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	depositBlkNumB, _, err := cc.AddDepositBlock(txn, b.Address(), big.NewInt(50), a)
+	require.NoError(t, err)
+	blkNum := addTestBlock(t, txn, cc, a, newTestSplitTx(t, depositBlkNumA, a, b))
+
+	exitTxOutPos := types.NewTxOutPosition(blkNum, 0, 1)
+	require.NoError(t, cc.ExitTxOut(txn, exitTxOutPos))
+
+	funds := &auditFunds{
+		deposits: []*RootChainDepositCreated{
+			{Owner: a.Address(), Amount: big.NewInt(100), DepositBlock: new(big.Int).SetUint64(depositBlkNumA)},
+			{Owner: b.Address(), Amount: big.NewInt(50), DepositBlock: new(big.Int).SetUint64(depositBlkNumB)},
+		},
+		exits: []*RootChainExitStarted{
+			{Owner: a.Address(), UtxoPosition: new(big.Int).SetUint64(exitTxOutPos.Uint64()), Amount: big.NewInt(40)},
+		},
+		balance: big.NewInt(150),
+	}
+
+	report, err := cc.audit(txn, funds)
+	require.NoError(t, err)
+	require.True(t, report.IsSound(), "%+v", report.Violations)
+	require.Equal(t, int64(110), report.Supply.Int64())
+
+	// a deposit the child chain does not know about
+	funds.deposits = append(funds.deposits, &RootChainDepositCreated{
+		Owner: a.Address(), Amount: big.NewInt(1), DepositBlock: new(big.Int).SetUint64(blkNum + 1),
+	})
+	report, err = cc.audit(txn, funds)
+	require.NoError(t, err)
+	require.Len(t, report.Violations, 2)
+	require.Equal(t, AuditViolationDepositMismatch, report.Violations[0].Kind)
+	require.Equal(t, AuditViolationSupplyMismatch, report.Violations[1].Kind)
+	funds.deposits = funds.deposits[:2]
+
+	// a block spending the deposit again, added without validation
+	addTestInvalidBlock(t, txn, cc,
+		newTestSpendingTx(t, types.NewTxIn(depositBlkNumA, 0, 0), a, types.NewTxOut(b.Address(), big.NewInt(100))),
+	)
+
+	report, err = cc.audit(txn, funds)
+	require.NoError(t, err)
+	kinds := map[string]bool{}
+	for _, violation := range report.Violations {
+		kinds[violation.Kind] = true
+	}
+	require.Equal(t, map[string]bool{
+		AuditViolationDoubleSpend:    true,
+		AuditViolationTokenMismatch:  true, // the token of the deposit records the second spend
+		AuditViolationSupplyMismatch: true,
+		AuditViolationUnderfunded:    true,
+	}, kinds)
+	require.Equal(t, types.NewTxOutPosition(depositBlkNumA, 0, 0), report.Violations[0].Position)
+}
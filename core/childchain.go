@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"math/big"
-	"strings"
+	"sort"
+	"time"
 
-	"github.com/dgraph-io/badger"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
@@ -14,31 +14,45 @@ import (
 )
 
 /*
-blknum_current                   => uint64
-blk_header_<block number>        => *types.BlockHeader
-tx_<block_number>_<tx index>     => *types.Tx
-tx_mempool_<tx hash>             => *types.Tx
-token_<address>_<txout position> => types.Position
+blknum_current                      => uint64
+blk_header_<block number>           => *types.BlockHeader
+tx_<block_number>_<tx index>        => *types.Tx
+mempool_tx_<tx hash>                => *MempoolTx
+mempool_next_txindex                => uint64
+token_<address>_<txout position>    => *Token
+txhash_<tx hash>                    => *txHashEntry
+pruned_tx_<block number>_<tx index> => *PrunedTx
+prune_next_blknum                   => uint64
+root_unsent_blknum                  => uint64
 */
 
 const (
 	FirstBlockNumber = 1
 	MempoolSize      = 99999 // must be less than or equal to types.MaxBlockTxesNum
 
-	currentBlockNumberKey = "current_blknum"
-	blockHeaderKeyPrefix  = "blk_header"
-	txKeyPrefix           = "tx"
-	mempoolTxKeyPrefix    = "mempool_tx"
-	tokenKeyPrefix        = "token"
+	currentBlockNumberKey    = "current_blknum"
+	blockHeaderKeyPrefix     = "blk_header"
+	txKeyPrefix              = "tx"
+	mempoolTxKeyPrefix       = "mempool_tx"
+	nextMempoolTxIndexKey    = "mempool_next_txindex"
+	tokenKeyPrefix           = "token"
+	txHashKeyPrefix          = "txhash"
+	prunedTxKeyPrefix        = "pruned_tx"
+	nextPruneBlockNumberKey  = "prune_next_blknum"
+	unsentRootBlockNumberKey = "root_unsent_blknum"
 )
 
-type ChildChain struct{}
+type ChildChain struct {
+	mempool *Mempool
+}
 
-func NewChildChain(txn *badger.Txn) (*ChildChain, error) {
-	cc := &ChildChain{}
+func NewChildChain(txn Txn) (*ChildChain, error) {
+	cc := &ChildChain{
+		mempool: NewMempool(),
+	}
 
 	if _, err := cc.getCurrentBlockNumber(txn); err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == ErrKeyNotFound {
 			if err := cc.setCurrentBlockNumber(txn, FirstBlockNumber); err != nil {
 				return nil, err
 			}
@@ -47,17 +61,65 @@ func NewChildChain(txn *badger.Txn) (*ChildChain, error) {
 		}
 	}
 
+	// rebuild mempool index
+	if err := cc.loadMempool(txn); err != nil {
+		return nil, err
+	}
+
+	return cc, nil
+}
+
+// OpenChildChain opens the child chain stored in txn without initializing it,
+// so that it can be used with a read-only txn.
+// It returns ErrChildChainNotFound if no child chain is stored.
+func OpenChildChain(txn Txn) (*ChildChain, error) {
+	cc := &ChildChain{
+		mempool: NewMempool(),
+	}
+
+	if _, err := cc.getCurrentBlockNumber(txn); err != nil {
+		if err == ErrKeyNotFound {
+			return nil, ErrChildChainNotFound
+		} else {
+			return nil, err
+		}
+	}
+
+	// rebuild mempool index
+	if err := cc.loadMempool(txn); err != nil {
+		return nil, err
+	}
+
 	return cc, nil
 }
 
-func (cc *ChildChain) GetCurrentBlockNumber(txn *badger.Txn) (uint64, error) {
+// Commit commits txn and applies the mempool changes made with it.
+// Read-write txns passed to ChildChain must be committed by Commit.
+func (cc *ChildChain) Commit(txn Txn) error {
+	if err := txn.Commit(); err != nil {
+		cc.mempool.discard(txn)
+		return err
+	}
+
+	cc.mempool.commit(txn)
+
+	return nil
+}
+
+// Discard discards txn and the mempool changes made with it.
+func (cc *ChildChain) Discard(txn Txn) {
+	txn.Discard()
+	cc.mempool.discard(txn)
+}
+
+func (cc *ChildChain) GetCurrentBlockNumber(txn Txn) (uint64, error) {
 	return cc.getCurrentBlockNumber(txn)
 }
 
-func (cc *ChildChain) GetBlock(txn *badger.Txn, blkNum uint64) (*types.Block, error) {
+func (cc *ChildChain) GetBlock(txn Txn, blkNum uint64) (*types.Block, error) {
 	blk, err := cc.getBlock(txn, blkNum)
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == ErrKeyNotFound {
 			return nil, ErrBlockNotFound
 		} else {
 			return nil, err
@@ -67,7 +129,8 @@ func (cc *ChildChain) GetBlock(txn *badger.Txn, blkNum uint64) (*types.Block, er
 	return blk, nil
 }
 
-func (cc *ChildChain) AddBlock(txn *badger.Txn, signer *types.Account) (uint64, error) {
+// AddBlock includes the mempool txes in a new block and returns the block number.
+func (cc *ChildChain) AddBlock(txn Txn, signer *types.Account) (uint64, error) {
 	// get current block
 	blk, err := cc.fixCurrentBlock(txn)
 	if err != nil {
@@ -97,51 +160,153 @@ func (cc *ChildChain) AddBlock(txn *badger.Txn, signer *types.Account) (uint64,
 	return blk.Number, nil
 }
 
-func (cc *ChildChain) AddDepositBlock(txn *badger.Txn, ownerAddr common.Address, amount *big.Int, signer *types.Account) (uint64, error) {
+// AddDepositBlock adds a block of a deposit tx of amount to ownerAddr.
+// It returns the block number and the hashes of the mempool txes evicted because of the deposit block.
+func (cc *ChildChain) AddDepositBlock(txn Txn, ownerAddr common.Address, amount *big.Int, signer *types.Account) (uint64, []common.Hash, error) {
 	// create deposit tx
 	tx := types.NewTx()
 	txOut := types.NewTxOut(ownerAddr, amount)
 	if err := tx.SetOutput(0, txOut); err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 
 	// get current block number
 	currentBlkNum, err := cc.getCurrentBlockNumber(txn)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
+	}
+
+	// the deposit block takes the number that pending txes were going to be included in,
+	// so txes spending outputs of pending txes reference txes of the deposit block instead,
+	// and cannot be kept for the next block since the positions they reference are signed
+	evictedTxHashes, err := cc.removeChainedTxesFromMempool(txn)
+	if err != nil {
+		return 0, nil, err
 	}
 
 	// create deposit block
 	blk, err := types.NewBlock([]*types.Tx{tx}, currentBlkNum)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 
 	// sign deposit block
 	if err := blk.Sign(signer); err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 
 	// add deposit block
 	if err := cc.addBlock(txn, blk); err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 
 	// increment current block number
 	if _, err := cc.incrementCurrentBlockNumber(txn); err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 
-	return blk.Number, nil
+	return blk.Number, evictedTxHashes, nil
+}
+
+// AddReplicatedBlock stores blk, a block served by the operator, as the current block.
+// Whether a txout is spent is derived from the replicated blocks, while whether it is exited
+// is taken as served, since the exits started before the replication are not replayed.
+// The block is not validated: an input not referencing a txout is skipped, so that an invalid block
+// committed to the root chain does not stop the replication and is left to FindFraud to report.
+func (cc *ChildChain) AddReplicatedBlock(txn Txn, blk *types.Block) error {
+	// get current block number
+	currentBlkNum, err := cc.getCurrentBlockNumber(txn)
+	if err != nil {
+		return err
+	}
+
+	// check block number
+	if blk.Number != currentBlkNum {
+		return ErrInvalidBlockNumber
+	}
+
+	// the served spent flags may reflect the mempool or later blocks of the operator
+	for _, tx := range blk.Txes {
+		for _, txOut := range tx.Outputs {
+			txOut.IsSpent = false
+		}
+	}
+
+	for _, tx := range blk.Txes {
+		for _, txIn := range tx.Inputs {
+			if txIn.IsNull() {
+				continue
+			}
+
+			// spend txout in the same block
+			if txIn.BlockNumber == blk.Number {
+				inTx := blk.GetTx(txIn.TxIndex)
+				if inTx == nil || !inTx.IsExistOutput(txIn.OutputIndex) {
+					continue
+				}
+				if err := inTx.SpendOutput(txIn.OutputIndex); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// spend txout in a stored block
+			inTx, err := cc.getStoredTx(txn, txIn.BlockNumber, txIn.TxIndex)
+			if err != nil {
+				if err == ErrKeyNotFound {
+					continue
+				} else {
+					return err
+				}
+			}
+			if !inTx.IsExistOutput(txIn.OutputIndex) {
+				continue
+			}
+			if err := inTx.SpendOutput(txIn.OutputIndex); err != nil {
+				return err
+			}
+			if err := cc.setStoredTx(txn, txIn.BlockNumber, txIn.TxIndex, inTx); err != nil {
+				return err
+			}
+		}
+	}
+
+	// add block
+	if err := cc.addBlock(txn, blk); err != nil {
+		return err
+	}
+
+	for i, tx := range blk.Txes {
+		for j, txOut := range tx.Outputs {
+			if !txOut.IsExited {
+				continue
+			}
+
+			// update token
+			if err := cc.updateToken(txn,
+				txOut.OwnerAddress,
+				types.NewTxOutPosition(blk.Number, uint64(i), uint64(j)),
+				func(token *Token) {
+					token.IsExited = true
+				},
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	// increment current block number
+	_, err = cc.incrementCurrentBlockNumber(txn)
+	return err
 }
 
-func (cc *ChildChain) GetTx(txn *badger.Txn, txPos types.Position) (*types.Tx, error) {
+func (cc *ChildChain) GetTx(txn Txn, txPos types.Position) (*types.Tx, error) {
 	blkNum, txIndex := types.ParseTxPosition(txPos)
 
 	tx, err := cc.getTx(txn, blkNum, txIndex)
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
-			return nil, ErrTxNotFound
+		if err == ErrKeyNotFound {
+			return nil, cc.txNotFoundError(txn, blkNum, txIndex)
 		} else {
 			return nil, err
 		}
@@ -150,90 +315,256 @@ func (cc *ChildChain) GetTx(txn *badger.Txn, txPos types.Position) (*types.Tx, e
 	return tx, nil
 }
 
-func (cc *ChildChain) GetTxProof(txn *badger.Txn, txPos types.Position) ([]byte, error) {
+func (cc *ChildChain) GetTxProof(txn Txn, txPos types.Position) ([]byte, error) {
 	blkNum, txIndex := types.ParseTxPosition(txPos)
 
 	// check tx existence
 	if _, err := cc.getTx(txn, blkNum, txIndex); err != nil {
-		if err == badger.ErrKeyNotFound {
-			return nil, ErrTxNotFound
+		if err == ErrKeyNotFound {
+			return nil, cc.txNotFoundError(txn, blkNum, txIndex)
 		} else {
 			return nil, err
 		}
 	}
 
-	// get block
-	blk, err := cc.getBlock(txn, blkNum)
+	// get leaf hashes of tx Merkle tree, some of which may be of pruned txes
+	leafHashes, err := cc.getBlockLeafHashes(txn, blkNum)
 	if err != nil {
 		return nil, err
 	}
 
-	// build tx Merkle tree
-	tree, err := blk.MerkleTree()
+	// create tx proof
+	return types.CreateTxMerkleProof(leafHashes, txIndex)
+}
+
+// GetExitData returns the arguments of startExit for the txout at txOutPos.
+func (cc *ChildChain) GetExitData(txn Txn, txOutPos types.Position) (*ExitData, error) {
+	blkNum, txIndex, outIndex := types.ParseTxOutPosition(txOutPos)
+	txPos := types.NewTxPosition(blkNum, txIndex)
+
+	tx, err := cc.GetTx(txn, txPos)
 	if err != nil {
 		return nil, err
 	}
+	if txOut := tx.GetOutput(outIndex); txOut == nil || txOut.OwnerAddress == types.NullAddress {
+		return nil, NewTxOutError(ErrTxOutNotFound, outIndex, txOutPos)
+	}
 
-	// create tx proof
-	return tree.CreateMembershipProof(txIndex)
+	txProofBytes, err := cc.GetTxProof(txn, txPos)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewExitData(txOutPos, tx, txProofBytes)
 }
 
-func (cc *ChildChain) AddTxToMempool(txn *badger.Txn, tx *types.Tx) error {
-	// check mempool capacity
-	if cc.countTxesInMempool(txn) >= MempoolSize {
-		return ErrMempoolFull
+// AddTxToMempool validates tx, marks its inputs spent and queues it for the next block.
+// An input may spend an output of a tx still in the mempool by referencing it at
+// its pending position, i.e. the current block number and the tx index the pending
+// tx was assigned when it entered the mempool.
+func (cc *ChildChain) AddTxToMempool(txn Txn, tx *types.Tx) error {
+	// validate tx
+	res, err := cc.CheckTx(txn, tx)
+	if err != nil {
+		return err
+	}
+	if err := res.Err(); err != nil {
+		return err
 	}
 
-	// validate tx
-	if err := cc.ValidateTx(txn, tx); err != nil {
+	// assign tx index in next block
+	txIndex, err := cc.getNextMempoolTxIndex(txn)
+	if err != nil {
 		return err
 	}
+	nextTxIndex := txIndex + 1
 
-	for _, txIn := range tx.Inputs {
+	// make room by removing the lowest priority tx if the mempool is full and tx pays more
+	if txIndex >= MempoolSize || cc.mempool.len(txn) >= MempoolSize {
+		lowest := cc.mempool.lowest(txn)
+		if lowest == nil || res.Fee.Cmp(lowest.Fee) <= 0 {
+			return ErrMempoolFull
+		}
+
+		// no tx index is left, so tx takes over the one of the removed tx,
+		// which must come after the pending txes tx spends
+		if txIndex >= MempoolSize {
+			currentBlkNum, err := cc.getCurrentBlockNumber(txn)
+			if err != nil {
+				return err
+			}
+			for _, txIn := range tx.Inputs {
+				if !txIn.IsNull() && txIn.BlockNumber == currentBlkNum && txIn.TxIndex >= lowest.TxIndex {
+					return ErrMempoolFull
+				}
+			}
+			txIndex, nextTxIndex = lowest.TxIndex, txIndex
+		}
+
+		if _, err := cc.removeTxFromMempool(txn, lowest); err != nil {
+			return err
+		}
+
+		// tx may spend an output of a removed tx
+		if err := cc.ValidateTx(txn, tx); err != nil {
+			return err
+		}
+	}
+
+	for i, txIn := range tx.Inputs {
 		// skip if txin is null
 		if txIn.IsNull() {
 			continue
 		}
 
-		// get tx
-		tx, err := cc.getTx(txn, txIn.BlockNumber, txIn.TxIndex)
+		inTxOutPos := types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)
+
+		// get input tx
+		inTx, err := cc.getInputTx(txn, txIn)
 		if err != nil {
-			if err == badger.ErrKeyNotFound {
-				return ErrInvalidTxIn
+			if err == ErrKeyNotFound {
+				return NewTxInError(ErrInvalidTxIn, uint64(i), inTxOutPos)
 			} else {
 				return err
 			}
 		}
 
 		// spend txout
-		if err := tx.SpendOutput(txIn.OutputIndex); err != nil {
+		if err := inTx.SpendOutput(txIn.OutputIndex); err != nil {
 			if err == types.ErrInvalidTxOutIndex {
-				return ErrInvalidTxIn
+				return NewTxInError(ErrInvalidTxIn, uint64(i), inTxOutPos)
 			} else {
 				return err
 			}
 		}
 
-		// update tx
-		if err := cc.setTx(txn, txIn.BlockNumber, txIn.TxIndex, tx); err != nil {
+		// update input tx
+		if err := cc.setInputTx(txn, txIn, inTx); err != nil {
 			return err
 		}
 	}
 
 	// add tx to mempool
-	if err := cc.addTxToMempool(txn, tx); err != nil {
+	if err := cc.setMempoolTx(txn, NewMempoolTx(tx, txIndex, res.Fee, time.Now())); err != nil {
 		return err
 	}
 
-	return nil
+	return cc.setNextMempoolTxIndex(txn, nextTxIndex)
+}
+
+// GetMempoolTxes returns the txes in the mempool, highest priority first.
+func (cc *ChildChain) GetMempoolTxes(txn Txn) ([]*MempoolTx, error) {
+	mtxes := cc.mempool.list(txn)
+
+	sort.Slice(mtxes, func(i, j int) bool {
+		return mtxes[i].HasPriorityOver(mtxes[j])
+	})
+
+	return mtxes, nil
+}
+
+func (cc *ChildChain) GetMempoolTx(txn Txn, txHash common.Hash) (*MempoolTx, error) {
+	mtx, err := cc.getMempoolTx(txn, txHash)
+	if err != nil {
+		if err == ErrKeyNotFound {
+			return nil, ErrMempoolTxNotFound
+		} else {
+			return nil, err
+		}
+	}
+
+	return mtx, nil
+}
+
+// CancelTx removes the tx from the mempool on behalf of one of its input owners
+// and releases the inputs it spent. Txes spending its outputs are removed as well.
+// It returns the hashes of all removed txes.
+func (cc *ChildChain) CancelTx(txn Txn, txHash common.Hash, cancelSig types.Signature) ([]common.Hash, error) {
+	mtx, err := cc.GetMempoolTx(txn, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// verify cancellation signature
+	signerAddr, err := mtx.Tx.CancellationSignerAddress(cancelSig)
+	if err != nil || bytes.Equal(signerAddr.Bytes(), types.NullAddress.Bytes()) {
+		return nil, ErrInvalidTxCancellationSignature
+	}
+	isOwner, err := cc.isTxInputOwner(txn, mtx.Tx, signerAddr)
+	if err != nil {
+		return nil, err
+	}
+	if !isOwner {
+		return nil, ErrInvalidTxCancellationSignature
+	}
+
+	return cc.removeTxFromMempool(txn, mtx)
+}
+
+// RemoveTxFromMempool removes the tx from the mempool without any authorization
+// and releases the inputs it spent. Txes spending its outputs are removed as well.
+// It returns the hashes of all removed txes.
+func (cc *ChildChain) RemoveTxFromMempool(txn Txn, txHash common.Hash) ([]common.Hash, error) {
+	mtx, err := cc.GetMempoolTx(txn, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return cc.removeTxFromMempool(txn, mtx)
+}
+
+// EvictExpiredTxesFromMempool removes the txes added to the mempool before deadline,
+// together with the txes spending their outputs, and releases the inputs they spent.
+func (cc *ChildChain) EvictExpiredTxesFromMempool(txn Txn, deadline time.Time) ([]common.Hash, error) {
+	mtxes, err := cc.getMempoolTxes(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	txHashes := []common.Hash{}
+	for _, mtx := range mtxes {
+		if !mtx.IsExpired(deadline) {
+			continue
+		}
+
+		txHash, err := mtx.Hash()
+		if err != nil {
+			return nil, err
+		}
+
+		// skip if tx was already removed together with its parent
+		if cc.mempool.get(txn, txHash) == nil {
+			continue
+		}
+
+		removedTxHashes, err := cc.removeTxFromMempool(txn, mtx)
+		if err != nil {
+			return nil, err
+		}
+
+		txHashes = append(txHashes, removedTxHashes...)
+	}
+
+	return txHashes, nil
+}
+
+func (cc *ChildChain) ValidateTx(txn Txn, tx *types.Tx) error {
+	res, err := cc.CheckTx(txn, tx)
+	if err != nil {
+		return err
+	}
+
+	return res.Err()
 }
 
-func (cc *ChildChain) ValidateTx(txn *badger.Txn, tx *types.Tx) error {
+// CheckTx runs every validation rule against tx without modifying any state
+// and reports all violations instead of stopping at the first one.
+func (cc *ChildChain) CheckTx(txn Txn, tx *types.Tx) (*TxValidationResult, error) {
+	res := NewTxValidationResult()
 	nullTxInNum := 0
-	iAmount, oAmount := big.NewInt(0), big.NewInt(0)
 
 	for _, txOut := range tx.Outputs {
-		oAmount.Add(oAmount, txOut.Amount)
+		res.OutputAmount.Add(res.OutputAmount, txOut.Amount)
 	}
 
 	for i, txIn := range tx.Inputs {
@@ -243,62 +574,67 @@ func (cc *ChildChain) ValidateTx(txn *badger.Txn, tx *types.Tx) error {
 			continue
 		}
 
+		inTxOutPos := types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)
+
 		// get input txout
-		inTxOut, err := cc.getTxOut(txn, txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)
+		inTxOut, err := cc.getInputTxOut(txn, txIn)
 		if err != nil {
-			if err == badger.ErrKeyNotFound { // tx is not found
-				return ErrInvalidTxIn
+			if err == ErrKeyNotFound { // tx is not found
+				res.addError(NewTxInError(ErrInvalidTxIn, uint64(i), inTxOutPos))
+				continue
 			} else {
-				return err
+				return nil, err
 			}
 		} else if inTxOut == nil { // tx does not have the output
-			return ErrInvalidTxIn
+			res.addError(NewTxInError(ErrInvalidTxIn, uint64(i), inTxOutPos))
+			continue
 		}
 
 		// check if input txout is not spent
 		if inTxOut.IsSpent {
-			return ErrTxOutAlreadySpent
+			res.addError(NewTxInError(ErrTxOutAlreadySpent, uint64(i), inTxOutPos))
 		}
 
 		// check if input txout is not exited
 		if inTxOut.IsExited {
-			return ErrTxOutAlreadyExited
+			res.addError(NewTxInError(ErrTxOutAlreadyExited, uint64(i), inTxOutPos))
 		}
 
 		// verify signature
 		signerAddr, err := tx.SignerAddress(uint64(i))
 		if err != nil {
-			return ErrInvalidTxSignature
-		}
-		if txIn.Signature == types.NullSignature ||
+			res.addSignatureError(NewTxInError(ErrInvalidTxSignature, uint64(i), inTxOutPos))
+		} else if txIn.Signature == types.NullSignature ||
 			!bytes.Equal(signerAddr.Bytes(), inTxOut.OwnerAddress.Bytes()) {
-			return ErrInvalidTxSignature
+			res.addError(NewTxInError(ErrInvalidTxSignature, uint64(i), inTxOutPos))
 		}
 
-		iAmount.Add(iAmount, inTxOut.Amount)
+		res.InputAmount.Add(res.InputAmount, inTxOut.Amount)
 	}
 
 	// check txins validity
 	if nullTxInNum == len(tx.Inputs) {
-		return ErrInvalidTxIn
+		res.addError(ErrInvalidTxIn)
 	}
 
 	// check in/out balance
-	if oAmount.Cmp(iAmount) > 0 {
-		return ErrInvalidTxBalance
+	if res.OutputAmount.Cmp(res.InputAmount) > 0 {
+		res.addError(ErrInvalidTxBalance)
 	}
 
-	return nil
+	res.Fee.Sub(res.InputAmount, res.OutputAmount)
+
+	return res, nil
 }
 
-func (cc *ChildChain) ConfirmTx(txn *badger.Txn, txInPos types.Position, confSig types.Signature) error {
+func (cc *ChildChain) ConfirmTx(txn Txn, txInPos types.Position, confSig types.Signature) error {
 	blkNum, txIndex, inIndex := types.ParseTxInPosition(txInPos)
 
 	// check tx existence
 	tx, err := cc.getTx(txn, blkNum, txIndex)
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
-			return ErrTxNotFound
+		if err == ErrKeyNotFound {
+			return cc.txNotFoundError(txn, blkNum, txIndex)
 		} else {
 			return err
 		}
@@ -306,21 +642,22 @@ func (cc *ChildChain) ConfirmTx(txn *badger.Txn, txInPos types.Position, confSig
 
 	// check txin existence
 	if !tx.IsExistInput(inIndex) {
-		return ErrTxInNotFound
+		return NewTxInError(ErrTxInNotFound, inIndex, txInPos)
 	}
 
 	// get txin
 	txIn := tx.GetInput(inIndex)
+	inTxOutPos := types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)
 
 	// check txin validity
 	if txIn.IsNull() {
-		return ErrNullTxInConfirmation
+		return NewTxInError(ErrNullTxInConfirmation, inIndex, inTxOutPos)
 	}
 
 	// get input txout
 	inTxOut, err := cc.getTxOut(txn, txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)
 	if err != nil || inTxOut == nil {
-		return ErrInvalidTxIn
+		return NewTxInError(ErrInvalidTxIn, inIndex, inTxOutPos)
 	}
 
 	// verify confirmation signature
@@ -330,16 +667,16 @@ func (cc *ChildChain) ConfirmTx(txn *badger.Txn, txInPos types.Position, confSig
 	}
 	signerAddr, err := confSig.SignerAddress(h)
 	if err != nil {
-		return ErrInvalidTxConfirmationSignature
+		return NewTxInError(ErrInvalidTxConfirmationSignature, inIndex, inTxOutPos)
 	}
 	if !bytes.Equal(signerAddr.Bytes(), inTxOut.OwnerAddress.Bytes()) {
-		return ErrInvalidTxConfirmationSignature
+		return NewTxInError(ErrInvalidTxConfirmationSignature, inIndex, inTxOutPos)
 	}
 
 	// update confirmation signature
 	if err := tx.SetConfirmationSignature(inIndex, confSig); err != nil {
 		if err == types.ErrInvalidTxInIndex {
-			return ErrInvalidTxIn
+			return NewTxInError(ErrInvalidTxIn, inIndex, inTxOutPos)
 		} else {
 			return err
 		}
@@ -349,77 +686,57 @@ func (cc *ChildChain) ConfirmTx(txn *badger.Txn, txInPos types.Position, confSig
 	return cc.setTx(txn, blkNum, txIndex, tx)
 }
 
-func (cc *ChildChain) GetUTXOPositions(txn *badger.Txn, addr common.Address) ([]types.Position, error) {
-	opts := badger.DefaultIteratorOptions
-	opts.PrefetchValues = false // key-only iteration
-
-	it := txn.NewIterator(opts)
-	defer it.Close()
-
-	prefix, poses := cc.tokenKeyPrefix(addr), []types.Position{}
-	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-		// get position
-		pos, err := types.StrToPosition(strings.TrimPrefix(string(it.Item().Key()), string(prefix)))
-		if err != nil {
-			return nil, err
-		}
-
-		blkNum, txIndex, outIndex := types.ParseTxOutPosition(pos)
-
-		// get txout
-		txOut, err := cc.getTxOut(txn, blkNum, txIndex, outIndex)
-		if err != nil {
-			return nil, err
-		}
-
-		// skip if txout was spent or exited
-		if txOut.IsSpent || txOut.IsExited {
-			continue
-		}
-
-		poses = append(poses, pos)
-	}
-
-	return poses, nil
-}
-
-func (cc *ChildChain) ExitTxOut(txn *badger.Txn, txOutPos types.Position) error {
+// ExitTxOut marks the txout at txOutPos exited from the current block on.
+func (cc *ChildChain) ExitTxOut(txn Txn, txOutPos types.Position) error {
 	blkNum, txIndex, outIndex := types.ParseTxInPosition(txOutPos)
 
-	// check tx existence
-	tx, err := cc.getTx(txn, blkNum, txIndex)
+	// check tx existence, where an exit of a txout of a pruned tx is still recorded
+	tx, err := cc.getStoredTx(txn, blkNum, txIndex)
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == ErrKeyNotFound {
 			return ErrTxNotFound
 		} else {
 			return err
 		}
 	}
 
+	// get current block number
+	currentBlkNum, err := cc.getCurrentBlockNumber(txn)
+	if err != nil {
+		return err
+	}
+
 	// exit txout
 	if err := tx.ExitOutput(outIndex); err != nil {
 		if err == types.ErrInvalidTxOutIndex {
-			return ErrTxOutNotFound
+			return NewTxOutError(ErrTxOutNotFound, outIndex, txOutPos)
 		} else {
 			return err
 		}
 	}
 
+	// update token
+	if err := cc.updateToken(txn, tx.GetOutput(outIndex).OwnerAddress, txOutPos, func(token *Token) {
+		token.IsExited = true
+
+		// an exit seen again keeps its block number, and an exit replicated without it gets one here
+		if token.ExitBlockNumber == 0 {
+			token.ExitBlockNumber = currentBlkNum
+		}
+	}); err != nil {
+		return err
+	}
+
 	// update tx
-	return cc.setTx(txn, blkNum, txIndex, tx)
+	return cc.setStoredTx(txn, blkNum, txIndex, tx)
 }
 
 func (cc *ChildChain) currentBlockNumberKey() []byte {
 	return []byte(currentBlockNumberKey)
 }
 
-func (cc *ChildChain) getCurrentBlockNumber(txn *badger.Txn) (uint64, error) {
-	item, err := txn.Get(cc.currentBlockNumberKey())
-	if err != nil {
-		return 0, err
-	}
-
-	blkNumBytes, err := item.Value()
+func (cc *ChildChain) getCurrentBlockNumber(txn Txn) (uint64, error) {
+	blkNumBytes, err := txn.Get(cc.currentBlockNumberKey())
 	if err != nil {
 		return 0, err
 	}
@@ -427,11 +744,11 @@ func (cc *ChildChain) getCurrentBlockNumber(txn *badger.Txn) (uint64, error) {
 	return utils.BytesToUint64(blkNumBytes)
 }
 
-func (cc *ChildChain) setCurrentBlockNumber(txn *badger.Txn, blkNum uint64) error {
+func (cc *ChildChain) setCurrentBlockNumber(txn Txn, blkNum uint64) error {
 	return txn.Set(cc.currentBlockNumberKey(), utils.Uint64ToBytes(blkNum))
 }
 
-func (cc *ChildChain) getNextBlockNumber(txn *badger.Txn) (uint64, error) {
+func (cc *ChildChain) getNextBlockNumber(txn Txn) (uint64, error) {
 	currentBlkNum, err := cc.getCurrentBlockNumber(txn)
 	if err != nil {
 		return 0, err
@@ -440,7 +757,7 @@ func (cc *ChildChain) getNextBlockNumber(txn *badger.Txn) (uint64, error) {
 	return currentBlkNum + 1, nil
 }
 
-func (cc *ChildChain) incrementCurrentBlockNumber(txn *badger.Txn) (uint64, error) {
+func (cc *ChildChain) incrementCurrentBlockNumber(txn Txn) (uint64, error) {
 	nextBlkNum, err := cc.getNextBlockNumber(txn)
 	if err != nil {
 		return 0, err
@@ -453,11 +770,48 @@ func (cc *ChildChain) incrementCurrentBlockNumber(txn *badger.Txn) (uint64, erro
 	return nextBlkNum, nil
 }
 
+// GetUnsentRootBlockNumber returns the number of the block whose root is yet to be sent to the root chain,
+// or 0 if there is no such block.
+func (cc *ChildChain) GetUnsentRootBlockNumber(txn Txn) (uint64, error) {
+	blkNumBytes, err := txn.Get(cc.unsentRootBlockNumberKey())
+	if err != nil {
+		if err == ErrKeyNotFound {
+			return 0, nil
+		} else {
+			return 0, err
+		}
+	}
+
+	return utils.BytesToUint64(blkNumBytes)
+}
+
+// SetUnsentRootBlockNumber records that the root of the block blkNum is yet to be sent to the root chain.
+func (cc *ChildChain) SetUnsentRootBlockNumber(txn Txn, blkNum uint64) error {
+	return txn.Set(cc.unsentRootBlockNumberKey(), utils.Uint64ToBytes(blkNum))
+}
+
+// ClearUnsentRootBlockNumber records that the root of the block blkNum was sent to the root chain.
+func (cc *ChildChain) ClearUnsentRootBlockNumber(txn Txn, blkNum uint64) error {
+	unsentBlkNum, err := cc.GetUnsentRootBlockNumber(txn)
+	if err != nil {
+		return err
+	}
+	if unsentBlkNum != blkNum {
+		return nil
+	}
+
+	return txn.Delete(cc.unsentRootBlockNumberKey())
+}
+
+func (cc *ChildChain) unsentRootBlockNumberKey() []byte {
+	return []byte(unsentRootBlockNumberKey)
+}
+
 func (cc *ChildChain) blockHeaderKey(blkNum uint64) []byte {
 	return []byte(fmt.Sprintf("%s_%d", blockHeaderKeyPrefix, blkNum))
 }
 
-func (cc *ChildChain) setBlockHeader(txn *badger.Txn, blkNum uint64, blkHeader *types.BlockHeader) error {
+func (cc *ChildChain) setBlockHeader(txn Txn, blkNum uint64, blkHeader *types.BlockHeader) error {
 	blkHeaderBytes, err := rlp.EncodeToBytes(blkHeader)
 	if err != nil {
 		return err
@@ -466,13 +820,8 @@ func (cc *ChildChain) setBlockHeader(txn *badger.Txn, blkNum uint64, blkHeader *
 	return txn.Set(cc.blockHeaderKey(blkNum), blkHeaderBytes)
 }
 
-func (cc *ChildChain) getBlockHeader(txn *badger.Txn, blkNum uint64) (*types.BlockHeader, error) {
-	item, err := txn.Get(cc.blockHeaderKey(blkNum))
-	if err != nil {
-		return nil, err
-	}
-
-	blkHeaderBytes, err := item.Value()
+func (cc *ChildChain) getBlockHeader(txn Txn, blkNum uint64) (*types.BlockHeader, error) {
+	blkHeaderBytes, err := txn.Get(cc.blockHeaderKey(blkNum))
 	if err != nil {
 		return nil, err
 	}
@@ -485,7 +834,7 @@ func (cc *ChildChain) getBlockHeader(txn *badger.Txn, blkNum uint64) (*types.Blo
 	return &blkHeader, nil
 }
 
-func (cc *ChildChain) getBlock(txn *badger.Txn, blkNum uint64) (*types.Block, error) {
+func (cc *ChildChain) getBlock(txn Txn, blkNum uint64) (*types.Block, error) {
 	// get block header
 	blkHeader, err := cc.getBlockHeader(txn, blkNum)
 	if err != nil {
@@ -498,23 +847,23 @@ func (cc *ChildChain) getBlock(txn *badger.Txn, blkNum uint64) (*types.Block, er
 		Txes:        nil,
 	}
 
-	it := txn.NewIterator(badger.DefaultIteratorOptions)
-	defer it.Close()
-
-	prefix := cc.txKeyPrefix(blkNum)
-	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-		// get tx
-		var tx types.Tx
-		txBytes, err := it.Item().Value()
+	// get txes by index, since tx keys are not sorted numerically
+	for txIndex := uint64(0); ; txIndex++ {
+		tx, err := cc.getTx(txn, blkNum, txIndex)
 		if err != nil {
-			return nil, err
-		}
-		if err := rlp.DecodeBytes(txBytes, &tx); err != nil {
-			return nil, err
+			if err != ErrKeyNotFound {
+				return nil, err
+			}
+			if err := cc.txNotFoundError(txn, blkNum, txIndex); err == ErrTxPruned {
+				return nil, ErrBlockPruned
+			} else if err != ErrTxNotFound {
+				return nil, err
+			}
+			break
 		}
 
 		// add tx to block
-		if err := blk.AddTx(&tx); err != nil {
+		if err := blk.AddTx(tx); err != nil {
 			return nil, err
 		}
 	}
@@ -522,7 +871,12 @@ func (cc *ChildChain) getBlock(txn *badger.Txn, blkNum uint64) (*types.Block, er
 	return blk, nil
 }
 
-func (cc *ChildChain) fixCurrentBlock(txn *badger.Txn) (*types.Block, error) {
+// fixCurrentBlock moves the mempool txes into a new block.
+// Txes whose outputs are spent by other mempool txes are referenced at the indexes they were assigned,
+// so they keep those indexes, while the other txes are moved forward to fill the slots of removed txes.
+// A slot no tx can fill is padded with a null tx, so that the txes spending outputs of the next referenced tx,
+// which cannot be kept for a later block since the positions they reference are signed, stay valid.
+func (cc *ChildChain) fixCurrentBlock(txn Txn) (*types.Block, error) {
 	// get current block number
 	currentBlkNum, err := cc.getCurrentBlockNumber(txn)
 	if err != nil {
@@ -535,54 +889,82 @@ func (cc *ChildChain) fixCurrentBlock(txn *badger.Txn) (*types.Block, error) {
 		return nil, err
 	}
 
-	it := txn.NewIterator(badger.DefaultIteratorOptions)
-	defer it.Close()
+	// get mempool txes in dependency order
+	mtxes, err := cc.getMempoolTxes(txn)
+	if err != nil {
+		return nil, err
+	}
+	if err := sortMempoolTxes(mtxes, currentBlkNum); err != nil {
+		return nil, err
+	}
 
-	prefix := cc.mempoolTxKeyPrefix()
-	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-		item := it.Item()
+	for len(mtxes) > 0 {
+		isReferenced := referencedMempoolTxIndexes(mtxes, currentBlkNum)
 
-		// get tx
-		var tx types.Tx
-		txBytes, err := item.Value()
-		if err != nil {
-			return nil, err
-		}
-		if err := rlp.DecodeBytes(txBytes, &tx); err != nil {
-			return nil, err
+		i := nextMempoolTxToInclude(mtxes, isReferenced, uint64(len(blk.Txes)), currentBlkNum)
+		if i < 0 {
+			// pad the slot before the next referenced tx
+			if err := blk.AddTx(types.NewTx()); err != nil {
+				return nil, err
+			}
+			continue
 		}
 
+		mtx := mtxes[i]
+		mtxes = append(mtxes[:i], mtxes[i+1:]...)
+
 		// add tx to block
-		if err := blk.AddTx(&tx); err != nil {
+		if err := blk.AddTx(mtx.Tx); err != nil {
 			return nil, err
 		}
 
 		// remove tx from mempool
-		if err := txn.Delete(item.Key()); err != nil {
+		if err := cc.deleteMempoolTx(txn, mtx); err != nil {
 			return nil, err
 		}
 	}
 
+	// reset tx index for next block
+	if err := cc.setNextMempoolTxIndex(txn, 0); err != nil {
+		return nil, err
+	}
+
 	return blk, nil
 }
 
-func (cc *ChildChain) addBlock(txn *badger.Txn, blk *types.Block) error {
+func (cc *ChildChain) addBlock(txn Txn, blk *types.Block) error {
 	for i, tx := range blk.Txes {
+		// a null tx padding the block has nothing to index
+		if tx.IsNull() {
+			if err := cc.setTx(txn, blk.Number, uint64(i), tx); err != nil {
+				return err
+			}
+			continue
+		}
+
 		for j, txIn := range tx.Inputs {
 			if txIn.IsNull() {
 				continue
 			}
 
 			inTxOut, err := cc.getTxOut(txn, txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)
-			if err != nil {
+			if err != nil && err != ErrKeyNotFound {
 				return err
 			}
 
+			// only an input of a replicated block may not reference a txout
+			if inTxOut == nil {
+				continue
+			}
+
 			// update position of txin by which token was spent
-			if err := cc.setToken(txn,
+			if err := cc.updateToken(txn,
 				inTxOut.OwnerAddress,
 				types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex),
-				types.NewTxInPosition(blk.Number, uint64(i), uint64(j)),
+				func(token *Token) {
+					token.SpendingTxInPos = types.NewTxInPosition(blk.Number, uint64(i), uint64(j))
+					token.IsSpentInMempool = false
+				},
 			); err != nil {
 				return err
 			}
@@ -594,12 +976,17 @@ func (cc *ChildChain) addBlock(txn *badger.Txn, blk *types.Block) error {
 				txn,
 				txOut.OwnerAddress,
 				types.NewTxOutPosition(blk.Number, uint64(i), uint64(j)),
-				0,
+				NewToken(txOut.Amount),
 			); err != nil {
 				return err
 			}
 		}
 
+		// index tx hash
+		if err := cc.indexTxHash(txn, tx, types.NewTxPosition(blk.Number, uint64(i))); err != nil {
+			return err
+		}
+
 		// store tx
 		if err := cc.setTx(txn, blk.Number, uint64(i), tx); err != nil {
 			return err
@@ -610,15 +997,11 @@ func (cc *ChildChain) addBlock(txn *badger.Txn, blk *types.Block) error {
 	return cc.setBlockHeader(txn, blk.Number, blk.BlockHeader)
 }
 
-func (cc *ChildChain) txKeyPrefix(blkNum uint64) []byte {
-	return []byte(fmt.Sprintf("%s_%d_", txKeyPrefix, blkNum))
-}
-
 func (cc *ChildChain) txKey(blkNum, txIndex uint64) []byte {
 	return []byte(fmt.Sprintf("%s_%d_%d", txKeyPrefix, blkNum, txIndex))
 }
 
-func (cc *ChildChain) setTx(txn *badger.Txn, blkNum, txIndex uint64, tx *types.Tx) error {
+func (cc *ChildChain) setTx(txn Txn, blkNum, txIndex uint64, tx *types.Tx) error {
 	txBytes, err := rlp.EncodeToBytes(tx)
 	if err != nil {
 		return err
@@ -627,13 +1010,8 @@ func (cc *ChildChain) setTx(txn *badger.Txn, blkNum, txIndex uint64, tx *types.T
 	return txn.Set(cc.txKey(blkNum, txIndex), txBytes)
 }
 
-func (cc *ChildChain) getTx(txn *badger.Txn, blkNum, txIndex uint64) (*types.Tx, error) {
-	item, err := txn.Get(cc.txKey(blkNum, txIndex))
-	if err != nil {
-		return nil, err
-	}
-
-	txBytes, err := item.Value()
+func (cc *ChildChain) getTx(txn Txn, blkNum, txIndex uint64) (*types.Tx, error) {
+	txBytes, err := txn.Get(cc.txKey(blkNum, txIndex))
 	if err != nil {
 		return nil, err
 	}
@@ -650,46 +1028,334 @@ func (cc *ChildChain) mempoolTxKeyPrefix() []byte {
 	return []byte(fmt.Sprintf("%s_", mempoolTxKeyPrefix))
 }
 
-func (cc *ChildChain) mempoolTxKey(tx *types.Tx) ([]byte, error) {
-	txHash, err := tx.Hash()
+func (cc *ChildChain) mempoolTxKey(txHash common.Hash) []byte {
+	return []byte(fmt.Sprintf("%s_%s", mempoolTxKeyPrefix, utils.HashToHex(txHash)))
+}
+
+func (cc *ChildChain) loadMempool(txn Txn) error {
+	prefix := cc.mempoolTxKeyPrefix()
+
+	it := txn.NewIterator(prefix)
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		var mtx MempoolTx
+		mtxBytes, err := it.Value()
+		if err != nil {
+			return err
+		}
+		if err := rlp.DecodeBytes(mtxBytes, &mtx); err != nil {
+			return err
+		}
+
+		txHash, err := mtx.Hash()
+		if err != nil {
+			return err
+		}
+
+		cc.mempool.load(txHash, &mtx)
+	}
+
+	return nil
+}
+
+func (cc *ChildChain) setMempoolTx(txn Txn, mtx *MempoolTx) error {
+	mtxBytes, err := rlp.EncodeToBytes(mtx)
+	if err != nil {
+		return err
+	}
+
+	txHash, err := mtx.Hash()
+	if err != nil {
+		return err
+	}
+
+	if err := txn.Set(cc.mempoolTxKey(txHash), mtxBytes); err != nil {
+		return err
+	}
+
+	cc.mempool.put(txn, txHash, mtx)
+
+	return nil
+}
+
+// getMempoolTx reads the tx from the KV rather than from the mempool index
+// so that txn conflicts with other txns updating the same tx.
+func (cc *ChildChain) getMempoolTx(txn Txn, txHash common.Hash) (*MempoolTx, error) {
+	mtxBytes, err := txn.Get(cc.mempoolTxKey(txHash))
 	if err != nil {
 		return nil, err
 	}
 
-	return []byte(fmt.Sprintf("%s_%s", mempoolTxKeyPrefix, utils.HashToHex(txHash))), nil
+	var mtx MempoolTx
+	if err := rlp.DecodeBytes(mtxBytes, &mtx); err != nil {
+		return nil, err
+	}
+
+	return &mtx, nil
 }
 
-func (cc *ChildChain) countTxesInMempool(txn *badger.Txn) uint64 {
-	opts := badger.DefaultIteratorOptions
-	opts.PrefetchValues = false // key-only iteration
+func (cc *ChildChain) getMempoolTxByIndex(txn Txn, txIndex uint64) (*MempoolTx, error) {
+	txHash, ok := cc.mempool.getTxHashByIndex(txn, txIndex)
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
 
-	it := txn.NewIterator(opts)
-	defer it.Close()
+	return cc.getMempoolTx(txn, txHash)
+}
+
+func (cc *ChildChain) getMempoolTxes(txn Txn) ([]*MempoolTx, error) {
+	return cc.mempool.list(txn), nil
+}
 
-	prefix, cnt := cc.mempoolTxKeyPrefix(), uint64(0)
-	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-		cnt++
+// getMempoolChildTxes returns the mempool txes spending outputs of the pending tx at txIndex.
+func (cc *ChildChain) getMempoolChildTxes(txn Txn, txIndex uint64) ([]*MempoolTx, error) {
+	currentBlkNum, err := cc.getCurrentBlockNumber(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	txOutPoses := make([]types.Position, types.TxElementsNum)
+	for i := range txOutPoses {
+		txOutPoses[i] = types.NewTxOutPosition(currentBlkNum, txIndex, uint64(i))
+	}
+
+	children := []*MempoolTx{}
+	for _, txHash := range cc.mempool.getSpenders(txn, txOutPoses) {
+		child, err := cc.getMempoolTx(txn, txHash)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
 	}
 
-	return cnt
+	return children, nil
 }
 
-func (cc *ChildChain) addTxToMempool(txn *badger.Txn, tx *types.Tx) error {
-	txBytes, err := rlp.EncodeToBytes(tx)
+func (cc *ChildChain) deleteMempoolTx(txn Txn, mtx *MempoolTx) error {
+	txHash, err := mtx.Hash()
 	if err != nil {
 		return err
 	}
 
-	key, err := cc.mempoolTxKey(tx)
+	if err := txn.Delete(cc.mempoolTxKey(txHash)); err != nil {
+		return err
+	}
+
+	cc.mempool.del(txn, txHash)
+
+	return nil
+}
+
+func (cc *ChildChain) removeTxFromMempool(txn Txn, mtx *MempoolTx) ([]common.Hash, error) {
+	txHashes := []common.Hash{}
+
+	// remove txes spending outputs of the tx first
+	children, err := cc.getMempoolChildTxes(txn, mtx.TxIndex)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		childTxHashes, err := cc.removeTxFromMempool(txn, child)
+		if err != nil {
+			return nil, err
+		}
+		txHashes = append(txHashes, childTxHashes...)
+	}
+
+	for _, txIn := range mtx.Tx.Inputs {
+		// skip if txin is null
+		if txIn.IsNull() {
+			continue
+		}
+
+		// get input tx
+		inTx, err := cc.getInputTx(txn, txIn)
+		if err != nil {
+			return nil, err
+		}
+
+		// release txout
+		if err := inTx.UnspendOutput(txIn.OutputIndex); err != nil {
+			return nil, err
+		}
+
+		// update input tx
+		if err := cc.setInputTx(txn, txIn, inTx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cc.deleteMempoolTx(txn, mtx); err != nil {
+		return nil, err
+	}
+
+	txHash, err := mtx.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	// remember that tx was evicted
+	if err := cc.setTxHashEntry(txn, txHash, &txHashEntry{
+		IsEvicted: true,
+	}); err != nil {
+		return nil, err
+	}
+
+	// start assigning tx indexes from the beginning again if mempool became empty
+	if cc.mempool.len(txn) == 0 {
+		if err := cc.setNextMempoolTxIndex(txn, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(txHashes, txHash), nil
+}
+
+// removeChainedTxesFromMempool removes the mempool txes spending outputs of other mempool txes.
+func (cc *ChildChain) removeChainedTxesFromMempool(txn Txn) ([]common.Hash, error) {
+	currentBlkNum, err := cc.getCurrentBlockNumber(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	mtxes, err := cc.getMempoolTxes(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	txHashes := []common.Hash{}
+	for _, mtx := range mtxes {
+		if !mtx.IsChained(currentBlkNum) {
+			continue
+		}
+
+		txHash, err := mtx.Hash()
+		if err != nil {
+			return nil, err
+		}
+
+		// skip if tx was already removed together with its parent
+		if cc.mempool.get(txn, txHash) == nil {
+			continue
+		}
+
+		removedTxHashes, err := cc.removeTxFromMempool(txn, mtx)
+		if err != nil {
+			return nil, err
+		}
+
+		txHashes = append(txHashes, removedTxHashes...)
+	}
+
+	return txHashes, nil
+}
+
+func (cc *ChildChain) nextMempoolTxIndexKey() []byte {
+	return []byte(nextMempoolTxIndexKey)
+}
+
+func (cc *ChildChain) getNextMempoolTxIndex(txn Txn) (uint64, error) {
+	txIndexBytes, err := txn.Get(cc.nextMempoolTxIndexKey())
+	if err != nil {
+		if err == ErrKeyNotFound {
+			return 0, nil
+		} else {
+			return 0, err
+		}
+	}
+
+	return utils.BytesToUint64(txIndexBytes)
+}
+
+func (cc *ChildChain) setNextMempoolTxIndex(txn Txn, txIndex uint64) error {
+	return txn.Set(cc.nextMempoolTxIndexKey(), utils.Uint64ToBytes(txIndex))
+}
+
+// getInputTx returns the tx whose output txIn spends,
+// looking it up in the mempool if txIn references the current block.
+func (cc *ChildChain) getInputTx(txn Txn, txIn *types.TxIn) (*types.Tx, error) {
+	currentBlkNum, err := cc.getCurrentBlockNumber(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	if txIn.BlockNumber == currentBlkNum {
+		mtx, err := cc.getMempoolTxByIndex(txn, txIn.TxIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		return mtx.Tx, nil
+	}
+
+	return cc.getStoredTx(txn, txIn.BlockNumber, txIn.TxIndex)
+}
+
+func (cc *ChildChain) setInputTx(txn Txn, txIn *types.TxIn, tx *types.Tx) error {
+	currentBlkNum, err := cc.getCurrentBlockNumber(txn)
 	if err != nil {
 		return err
 	}
 
-	return txn.Set(key, txBytes)
+	if txIn.BlockNumber == currentBlkNum {
+		mtx, err := cc.getMempoolTxByIndex(txn, txIn.TxIndex)
+		if err != nil {
+			return err
+		}
+		mtx.Tx = tx
+
+		return cc.setMempoolTx(txn, mtx)
+	}
+
+	// keep token in sync with whether a mempool tx spends txout
+	txOut := tx.GetOutput(txIn.OutputIndex)
+	if err := cc.updateToken(txn,
+		txOut.OwnerAddress,
+		types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex),
+		func(token *Token) {
+			token.IsSpentInMempool = txOut.IsSpent
+		},
+	); err != nil {
+		return err
+	}
+
+	return cc.setStoredTx(txn, txIn.BlockNumber, txIn.TxIndex, tx)
 }
 
-func (cc *ChildChain) getTxOut(txn *badger.Txn, blkNum, txIndex, outIndex uint64) (*types.TxOut, error) {
-	tx, err := cc.getTx(txn, blkNum, txIndex)
+func (cc *ChildChain) getInputTxOut(txn Txn, txIn *types.TxIn) (*types.TxOut, error) {
+	tx, err := cc.getInputTx(txn, txIn)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.GetOutput(txIn.OutputIndex), nil
+}
+
+func (cc *ChildChain) isTxInputOwner(txn Txn, tx *types.Tx, addr common.Address) (bool, error) {
+	for _, txIn := range tx.Inputs {
+		if txIn.IsNull() {
+			continue
+		}
+
+		inTxOut, err := cc.getInputTxOut(txn, txIn)
+		if err != nil {
+			return false, err
+		}
+		if inTxOut == nil {
+			continue
+		}
+
+		if bytes.Equal(inTxOut.OwnerAddress.Bytes(), addr.Bytes()) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (cc *ChildChain) getTxOut(txn Txn, blkNum, txIndex, outIndex uint64) (*types.TxOut, error) {
+	tx, err := cc.getStoredTx(txn, blkNum, txIndex)
 	if err != nil {
 		return nil, err
 	}
@@ -705,6 +1371,158 @@ func (cc *ChildChain) tokenKey(addr common.Address, txOutPos types.Position) []b
 	return []byte(fmt.Sprintf("%s_%s_%d", tokenKeyPrefix, utils.AddressToHex(addr), txOutPos))
 }
 
-func (cc *ChildChain) setToken(txn *badger.Txn, addr common.Address, txOutPos types.Position, spendingTxInPos types.Position) error {
-	return txn.Set(cc.tokenKey(addr, txOutPos), spendingTxInPos.Bytes())
+func (cc *ChildChain) setToken(txn Txn, addr common.Address, txOutPos types.Position, token *Token) error {
+	tokenBytes, err := rlp.EncodeToBytes(token)
+	if err != nil {
+		return err
+	}
+
+	return txn.Set(cc.tokenKey(addr, txOutPos), tokenBytes)
+}
+
+func (cc *ChildChain) getToken(txn Txn, addr common.Address, txOutPos types.Position) (*Token, error) {
+	tokenBytes, err := txn.Get(cc.tokenKey(addr, txOutPos))
+	if err != nil {
+		return nil, err
+	}
+
+	return cc.decodeToken(txn, txOutPos, tokenBytes)
+}
+
+func (cc *ChildChain) updateToken(txn Txn, addr common.Address, txOutPos types.Position, fn func(token *Token)) error {
+	token, err := cc.getToken(txn, addr, txOutPos)
+	if err != nil {
+		return err
+	}
+
+	fn(token)
+
+	return cc.setToken(txn, addr, txOutPos, token)
+}
+
+// decodeToken also accepts the tokens stored without the exit block number,
+// and the bare spending txin position that tokens used to be stored as,
+// in which case the rest of the token is restored from the txout.
+func (cc *ChildChain) decodeToken(txn Txn, txOutPos types.Position, tokenBytes []byte) (*Token, error) {
+	var token Token
+	if err := rlp.DecodeBytes(tokenBytes, &token); err == nil {
+		return &token, nil
+	}
+
+	var v1 tokenV1
+	if err := rlp.DecodeBytes(tokenBytes, &v1); err == nil {
+		return &Token{
+			Amount:           v1.Amount,
+			SpendingTxInPos:  v1.SpendingTxInPos,
+			IsSpentInMempool: v1.IsSpentInMempool,
+			IsExited:         v1.IsExited,
+		}, nil
+	}
+
+	spendingTxInPos, err := types.BytesToPosition(tokenBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	blkNum, txIndex, outIndex := types.ParseTxOutPosition(txOutPos)
+	txOut, err := cc.getTxOut(txn, blkNum, txIndex, outIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Token{
+		Amount:           txOut.Amount,
+		SpendingTxInPos:  spendingTxInPos,
+		IsSpentInMempool: txOut.IsSpent && spendingTxInPos == 0,
+		IsExited:         txOut.IsExited,
+	}, nil
+}
+
+// indexTxHash adds txPos to the positions of the hash of tx.
+// Deposit txes of the same owner and amount have the same hash,
+// so a hash already at another position keeps it.
+func (cc *ChildChain) indexTxHash(txn Txn, tx *types.Tx, txPos types.Position) error {
+	txHash, err := tx.Hash()
+	if err != nil {
+		return err
+	}
+
+	entry, err := cc.getTxHashEntry(txn, txHash)
+	if err != nil {
+		if err == ErrKeyNotFound {
+			entry = nil
+		} else {
+			return err
+		}
+	}
+
+	if entry == nil || entry.IsEvicted {
+		entry = &txHashEntry{
+			Position: txPos,
+		}
+	} else if !entry.hasPosition(txPos) {
+		entry.LaterPositions = append(entry.LaterPositions, txPos)
+	}
+
+	return cc.setTxHashEntry(txn, txHash, entry)
+}
+
+func (cc *ChildChain) txHashKey(txHash common.Hash) []byte {
+	return []byte(fmt.Sprintf("%s_%s", txHashKeyPrefix, utils.HashToHex(txHash)))
+}
+
+func (cc *ChildChain) setTxHashEntry(txn Txn, txHash common.Hash, entry *txHashEntry) error {
+	entryBytes, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		return err
+	}
+
+	return txn.Set(cc.txHashKey(txHash), entryBytes)
+}
+
+func (cc *ChildChain) getTxHashEntry(txn Txn, txHash common.Hash) (*txHashEntry, error) {
+	entryBytes, err := txn.Get(cc.txHashKey(txHash))
+	if err != nil {
+		return nil, err
+	}
+
+	var entry txHashEntry
+	if err := rlp.DecodeBytes(entryBytes, &entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// CountKeys counts the keys of each kind stored by a child chain, by their key prefix.
+func CountKeys(txn Txn) map[string]int {
+	counts := map[string]int{}
+
+	for _, key := range []string{currentBlockNumberKey, nextMempoolTxIndexKey, nextPruneBlockNumberKey, unsentRootBlockNumberKey} {
+		if _, err := txn.Get([]byte(key)); err == nil {
+			counts[key] = 1
+		} else {
+			counts[key] = 0
+		}
+	}
+
+	for _, prefix := range []string{
+		blockHeaderKeyPrefix,
+		txKeyPrefix,
+		mempoolTxKeyPrefix,
+		tokenKeyPrefix,
+		txHashKeyPrefix,
+		prunedTxKeyPrefix,
+	} {
+		n := 0
+		it := txn.NewIterator([]byte(prefix + "_"))
+		for ; it.Valid(); it.Next() {
+			n++
+		}
+		it.Close()
+
+		counts[prefix] = n
+	}
+
+	return counts
 }
@@ -0,0 +1,613 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChildChain_AddReplicatedBlock(t *testing.T) {
+	opDB, closeOpDB := openTestDB(t)
+	defer closeOpDB()
+	fDB, closeFDB := openTestDB(t)
+	defer closeFDB()
+
+	a, b := newTestAccounts(t)
+
+	// operator: a deposit and a tx spending it, followed by a pending tx spending its output
+	txn, opCC := newTestChildChain(t, opDB)
+	depositBlkNum, _, err := opCC.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+
+	tx := newTestSplitTx(t, depositBlkNum, a, b)
+	blkNum := addTestBlock(t, txn, opCC, a, tx)
+
+	pendingTx := newTestSpendingTx(t, types.NewTxIn(blkNum, 0, 0), b, types.NewTxOut(a.Address(), big.NewInt(60)))
+	require.NoError(t, opCC.AddTxToMempool(txn, pendingTx))
+	require.NoError(t, opCC.Commit(txn))
+
+	// follower: replicate the blocks as served
+	txn, fCC := newTestChildChain(t, fDB)
+
+	opTxn := opDB.NewTransaction(false)
+	defer opTxn.Discard()
+	for _, n := range []uint64{depositBlkNum, blkNum} {
+		blk, err := opCC.GetBlock(opTxn, n)
+		require.NoError(t, err)
+
+		blkBytes, err := rlp.EncodeToBytes(blk)
+		require.NoError(t, err)
+		var servedBlk types.Block
+		require.NoError(t, rlp.DecodeBytes(blkBytes, &servedBlk))
+
+		require.NoError(t, fCC.AddReplicatedBlock(txn, &servedBlk))
+	}
+
+	// a block cannot be replicated twice
+	blk, err := opCC.GetBlock(opTxn, blkNum)
+	require.NoError(t, err)
+	require.Equal(t, ErrInvalidBlockNumber, fCC.AddReplicatedBlock(txn, blk))
+	require.NoError(t, fCC.Commit(txn))
+
+	rtxn := fDB.NewTransaction(false)
+	defer rtxn.Discard()
+
+	currentBlkNum, err := fCC.GetCurrentBlockNumber(rtxn)
+	require.NoError(t, err)
+	require.Equal(t, blkNum+1, currentBlkNum)
+
+	// the deposit is spent in a block
+	depositTx, err := fCC.GetTx(rtxn, types.NewTxPosition(depositBlkNum, 0))
+	require.NoError(t, err)
+	require.True(t, depositTx.GetOutput(0).IsSpent)
+
+	// the txout spent only in the mempool of the operator is not spent
+	replicatedTx, err := fCC.GetTx(rtxn, types.NewTxPosition(blkNum, 0))
+	require.NoError(t, err)
+	require.False(t, replicatedTx.GetOutput(0).IsSpent)
+
+	balanceA, err := fCC.GetBalance(rtxn, a.Address())
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(40), balanceA.Total)
+
+	utxos, err := fCC.GetUTXOs(rtxn, b.Address(), nil, 0)
+	require.NoError(t, err)
+	require.Len(t, utxos, 1)
+	require.Equal(t, types.NewTxOutPosition(blkNum, 0, 0), utxos[0].Position)
+
+	// the tx hash index is rebuilt
+	txHash, err := tx.Hash()
+	require.NoError(t, err)
+	status, err := fCC.GetTxStatus(rtxn, txHash)
+	require.NoError(t, err)
+	require.Equal(t, TxStateIncluded, status.State)
+}
+
+func TestChildChain_GetAllUTXOs(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+
+	require.NoError(t, cc.AddTxToMempool(txn, newTestSplitTx(t, depositBlkNum, a, b)))
+
+	// the deposit is spent only in the mempool
+	utxos, err := cc.GetAllUTXOs(txn)
+	require.NoError(t, err)
+	require.Len(t, utxos, 1)
+	require.Equal(t, a.Address(), utxos[0].Address)
+	require.Equal(t, types.NewTxOutPosition(depositBlkNum, 0, 0), utxos[0].Position)
+
+	blkNum, err := cc.AddBlock(txn, a)
+	require.NoError(t, err)
+
+	utxos, err = cc.GetAllUTXOs(txn)
+	require.NoError(t, err)
+	require.Len(t, utxos, 2)
+	amounts := map[types.Position]int64{}
+	for _, utxo := range utxos {
+		amounts[utxo.Position] = utxo.Amount.Int64()
+	}
+	require.Equal(t, map[types.Position]int64{
+		types.NewTxOutPosition(blkNum, 0, 0): 60,
+		types.NewTxOutPosition(blkNum, 0, 1): 40,
+	}, amounts)
+}
+
+func TestChildChain_GetUTXOsAt(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+
+	blkNum := addTestBlock(t, txn, cc, a, newTestSplitTx(t, depositBlkNum, a, b))
+
+	lastDepositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(10), a)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		blkNum  uint64
+		poses   []types.Position
+		balance int64
+	}{
+		{depositBlkNum, []types.Position{types.NewTxOutPosition(depositBlkNum, 0, 0)}, 100},
+		{blkNum, []types.Position{types.NewTxOutPosition(blkNum, 0, 1)}, 40},
+		{lastDepositBlkNum, []types.Position{types.NewTxOutPosition(blkNum, 0, 1), types.NewTxOutPosition(lastDepositBlkNum, 0, 0)}, 50},
+	}
+	for _, tc := range testCases {
+		utxos, err := cc.GetUTXOsAt(txn, a.Address(), tc.blkNum, nil, 0)
+		require.NoError(t, err)
+		poses := []types.Position{}
+		for _, utxo := range utxos {
+			poses = append(poses, utxo.Position)
+		}
+		require.Equal(t, tc.poses, poses)
+
+		balance, err := cc.GetBalanceAt(txn, a.Address(), tc.blkNum)
+		require.NoError(t, err)
+		require.Equal(t, tc.balance, balance.Total.Int64())
+		require.Equal(t, uint64(len(tc.poses)), balance.Count)
+	}
+
+	// the output of b did not exist before the block spending the deposit
+	utxos, err := cc.GetUTXOsAt(txn, b.Address(), depositBlkNum, nil, 0)
+	require.NoError(t, err)
+	require.Empty(t, utxos)
+
+	_, err = cc.GetUTXOsAt(txn, a.Address(), lastDepositBlkNum+1, nil, 0)
+	require.Equal(t, ErrBlockNotFound, err)
+	_, err = cc.GetBalanceAt(txn, a.Address(), 0)
+	require.Equal(t, ErrBlockNotFound, err)
+}
+
+func TestCountKeys(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, _ := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+	_, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(1), a)
+	require.NoError(t, err)
+
+	counts := CountKeys(txn)
+	require.Equal(t, 1, counts[currentBlockNumberKey])
+	require.Equal(t, 0, counts[nextMempoolTxIndexKey])
+	require.Equal(t, 1, counts[blockHeaderKeyPrefix])
+	require.Equal(t, 1, counts[txKeyPrefix])
+	require.Equal(t, 0, counts[mempoolTxKeyPrefix])
+	require.Equal(t, 1, counts[txHashKeyPrefix])
+	require.Equal(t, 0, counts[prunedTxKeyPrefix])
+}
+
+func TestChildChain_AddTxToMempool_TxInError(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	depositTxOutPos := types.NewTxOutPosition(depositBlkNum, 0, 0)
+
+	// the deposit of a signed by b
+	tx := newTestSpendingTx(t, types.NewTxIn(depositBlkNum, 0, 0), b, types.NewTxOut(b.Address(), big.NewInt(100)))
+	require.Equal(t, NewTxInError(ErrInvalidTxSignature, 0, depositTxOutPos), cc.AddTxToMempool(txn, tx))
+
+	// the second input spends a txout that does not exist
+	tx = newTestSplitTx(t, depositBlkNum, a, b)
+	require.NoError(t, tx.SetInput(1, types.NewTxIn(depositBlkNum+1, 0, 0)))
+	require.NoError(t, tx.Sign(0, a))
+	require.NoError(t, tx.Sign(1, a))
+	err = cc.AddTxToMempool(txn, tx)
+	require.Equal(t, NewTxInError(ErrInvalidTxIn, 1, types.NewTxOutPosition(depositBlkNum+1, 0, 0)), err)
+	require.Equal(t, ErrInvalidTxIn, Cause(err))
+
+	// the deposit spent in the mempool
+	require.NoError(t, cc.AddTxToMempool(txn, newTestSplitTx(t, depositBlkNum, a, b)))
+	tx = newTestSpendingTx(t, types.NewTxIn(depositBlkNum, 0, 0), a, types.NewTxOut(a.Address(), big.NewInt(100)))
+	require.Equal(t, NewTxInError(ErrTxOutAlreadySpent, 0, depositTxOutPos), cc.AddTxToMempool(txn, tx))
+}
+
+func TestChildChain_ConfirmTx(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	tx := newTestSplitTx(t, depositBlkNum, a, b)
+	blkNum := addTestBlock(t, txn, cc, a, tx)
+	depositTxOutPos := types.NewTxOutPosition(depositBlkNum, 0, 0)
+
+	// confirmed by b, who does not own the input
+	require.NoError(t, tx.Confirm(0, b))
+	txInPos := types.NewTxInPosition(blkNum, 0, 0)
+	require.Equal(t,
+		NewTxInError(ErrInvalidTxConfirmationSignature, 0, depositTxOutPos),
+		cc.ConfirmTx(txn, txInPos, tx.GetInput(0).ConfirmationSignature),
+	)
+
+	// the second input is null
+	require.Equal(t,
+		NewTxInError(ErrNullTxInConfirmation, 1, types.NewTxOutPosition(0, 0, 0)),
+		cc.ConfirmTx(txn, types.NewTxInPosition(blkNum, 0, 1), tx.GetInput(0).ConfirmationSignature),
+	)
+
+	// the tx has no third input, which is reported at its own position
+	missingTxInPos := types.NewTxInPosition(blkNum, 0, types.TxElementsNum)
+	require.Equal(t,
+		NewTxInError(ErrTxInNotFound, types.TxElementsNum, missingTxInPos),
+		cc.ConfirmTx(txn, missingTxInPos, tx.GetInput(0).ConfirmationSignature),
+	)
+
+	require.NoError(t, tx.Confirm(0, a))
+	require.NoError(t, cc.ConfirmTx(txn, txInPos, tx.GetInput(0).ConfirmationSignature))
+	confirmedTx, err := cc.GetTx(txn, types.NewTxPosition(blkNum, 0))
+	require.NoError(t, err)
+	require.Equal(t, tx.GetInput(0).ConfirmationSignature, confirmedTx.GetInput(0).ConfirmationSignature)
+}
+
+func TestChildChain_AddBlock(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNums := make([]uint64, 6)
+	for i := range depositBlkNums {
+		depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+		require.NoError(t, err)
+		depositBlkNums[i] = depositBlkNum
+	}
+	spendDeposit := func(i int) *types.Tx {
+		return newTestSpendingTx(t, types.NewTxIn(depositBlkNums[i], 0, 0), a, types.NewTxOut(b.Address(), big.NewInt(100)))
+	}
+	spendPending := func(blkNum, txIndex uint64) *types.Tx {
+		return newTestSpendingTx(t, types.NewTxIn(blkNum, txIndex, 0), b, types.NewTxOut(a.Address(), big.NewInt(100)))
+	}
+	requireBlockTxes := func(blkNum uint64, txes ...*types.Tx) {
+		blk, err := cc.GetBlock(txn, blkNum)
+		require.NoError(t, err)
+		require.Len(t, blk.Txes, len(txes))
+		for i, tx := range txes {
+			txHash, err := tx.Hash()
+			require.NoError(t, err)
+			blkTxHash, err := blk.Txes[i].Hash()
+			require.NoError(t, err)
+			require.Equal(t, txHash, blkTxHash)
+		}
+	}
+	txHash := func(tx *types.Tx) common.Hash {
+		h, err := tx.Hash()
+		require.NoError(t, err)
+		return h
+	}
+
+	// a chained spend is included right after its parent
+	blkNum, err := cc.GetCurrentBlockNumber(txn)
+	require.NoError(t, err)
+	parentTx, childTx := spendDeposit(0), spendPending(blkNum, 0)
+	require.Equal(t, blkNum, addTestBlock(t, txn, cc, a, parentTx, childTx))
+	requireBlockTxes(blkNum, parentTx, childTx)
+
+	// the slot of a removed tx is filled by a tx added after it,
+	// while a tx whose outputs are spent in the mempool keeps its index
+	blkNum, err = cc.GetCurrentBlockNumber(txn)
+	require.NoError(t, err)
+	removedTx, parentTx, childTx, lateTx := spendDeposit(1), spendDeposit(2), spendPending(blkNum, 1), spendDeposit(3)
+	for _, tx := range []*types.Tx{removedTx, parentTx, childTx, lateTx} {
+		require.NoError(t, cc.AddTxToMempool(txn, tx))
+	}
+	_, err = cc.RemoveTxFromMempool(txn, txHash(removedTx))
+	require.NoError(t, err)
+	_, err = cc.AddBlock(txn, a)
+	require.NoError(t, err)
+	requireBlockTxes(blkNum, lateTx, parentTx, childTx)
+
+	// if nothing can fill the slot, it is padded with a null tx so that the chained spend is kept
+	blkNum, err = cc.GetCurrentBlockNumber(txn)
+	require.NoError(t, err)
+	removedTx, parentTx, childTx = spendDeposit(4), spendDeposit(5), spendPending(blkNum, 1)
+	for _, tx := range []*types.Tx{removedTx, parentTx, childTx} {
+		require.NoError(t, cc.AddTxToMempool(txn, tx))
+	}
+	_, err = cc.RemoveTxFromMempool(txn, txHash(removedTx))
+	require.NoError(t, err)
+	_, err = cc.AddBlock(txn, a)
+	require.NoError(t, err)
+	requireBlockTxes(blkNum, types.NewTx(), parentTx, childTx)
+
+	status, err := cc.GetTxStatus(txn, txHash(childTx))
+	require.NoError(t, err)
+	require.Equal(t, TxStateIncluded, status.State)
+	require.Equal(t, types.NewTxPosition(blkNum, 2), status.Position)
+
+	// the null tx is not taken as fraud
+	deposits := map[uint64]*RootChainDepositCreated{}
+	for _, depositBlkNum := range depositBlkNums {
+		deposits[depositBlkNum] = newTestDepositCreated(a.Address(), 100, depositBlkNum)
+	}
+	report, err := cc.findFraud(txn, blkNum+1, deposits)
+	require.NoError(t, err)
+	require.True(t, report.IsValid())
+}
+
+func TestChildChain_AddDepositBlock(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	blkNum, err := cc.GetCurrentBlockNumber(txn)
+	require.NoError(t, err)
+
+	parentTx := newTestSplitTx(t, depositBlkNum, a, b)
+	childTx := newTestSpendingTx(t, types.NewTxIn(blkNum, 0, 0), b, types.NewTxOut(a.Address(), big.NewInt(60)))
+	require.NoError(t, cc.AddTxToMempool(txn, parentTx))
+	require.NoError(t, cc.AddTxToMempool(txn, childTx))
+
+	// the deposit block takes the number the chained tx references
+	newDepositBlkNum, evictedTxHashes, err := cc.AddDepositBlock(txn, b.Address(), big.NewInt(50), a)
+	require.NoError(t, err)
+	require.Equal(t, blkNum, newDepositBlkNum)
+	childTxHash, err := childTx.Hash()
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{childTxHash}, evictedTxHashes)
+
+	status, err := cc.GetTxStatus(txn, childTxHash)
+	require.NoError(t, err)
+	require.Equal(t, TxStateEvicted, status.State)
+
+	// the parent tx stays in the mempool
+	blkNum = addTestBlock(t, txn, cc, a)
+	tx, err := cc.GetTx(txn, types.NewTxPosition(blkNum, 0))
+	require.NoError(t, err)
+	txHash, err := tx.Hash()
+	require.NoError(t, err)
+	parentTxHash, err := parentTx.Hash()
+	require.NoError(t, err)
+	require.Equal(t, parentTxHash, txHash)
+}
+
+func TestOpenChildChain(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	rtxn := db.NewTransaction(false)
+	_, err := OpenChildChain(rtxn)
+	require.Equal(t, ErrChildChainNotFound, err)
+	rtxn.Discard()
+
+	txn, cc := newTestChildChain(t, db)
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	require.NoError(t, cc.AddTxToMempool(txn, newTestSplitTx(t, depositBlkNum, a, b)))
+	require.NoError(t, cc.Commit(txn))
+
+	rtxn = db.NewTransaction(false)
+	defer rtxn.Discard()
+	openedCC, err := OpenChildChain(rtxn)
+	require.NoError(t, err)
+	blkNum, err := openedCC.GetCurrentBlockNumber(rtxn)
+	require.NoError(t, err)
+	require.Equal(t, depositBlkNum+1, blkNum)
+	mtxes, err := openedCC.GetMempoolTxes(rtxn)
+	require.NoError(t, err)
+	require.Len(t, mtxes, 1)
+}
+
+func TestChildChain_UnsentRootBlockNumber(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	blkNum, err := cc.GetUnsentRootBlockNumber(txn)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), blkNum)
+
+	require.NoError(t, cc.SetUnsentRootBlockNumber(txn, 2))
+
+	// clearing another block leaves the unsent block as is
+	require.NoError(t, cc.ClearUnsentRootBlockNumber(txn, 1))
+	blkNum, err = cc.GetUnsentRootBlockNumber(txn)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), blkNum)
+
+	require.NoError(t, cc.ClearUnsentRootBlockNumber(txn, 2))
+	blkNum, err = cc.GetUnsentRootBlockNumber(txn)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), blkNum)
+}
+
+// addTestChainedTxes adds a tx spending the deposit of 100 of a to b and a tx spending its output back to a to the mempool.
+func addTestChainedTxes(t *testing.T, txn Txn, cc *ChildChain, a, b *types.Account) (*types.Tx, *types.Tx) {
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	blkNum, err := cc.GetCurrentBlockNumber(txn)
+	require.NoError(t, err)
+
+	parentTx := newTestSpendingTx(t, types.NewTxIn(depositBlkNum, 0, 0), a, types.NewTxOut(b.Address(), big.NewInt(100)))
+	childTx := newTestSpendingTx(t, types.NewTxIn(blkNum, 0, 0), b, types.NewTxOut(a.Address(), big.NewInt(100)))
+	require.NoError(t, cc.AddTxToMempool(txn, parentTx))
+	require.NoError(t, cc.AddTxToMempool(txn, childTx))
+
+	return parentTx, childTx
+}
+
+// requireTestTxState checks the state of each tx in the txhash index.
+func requireTestTxState(t *testing.T, txn Txn, cc *ChildChain, state string, txes ...*types.Tx) {
+	for _, tx := range txes {
+		txHash, err := tx.Hash()
+		require.NoError(t, err)
+		status, err := cc.GetTxStatus(txn, txHash)
+		require.NoError(t, err)
+		require.Equal(t, state, status.State)
+	}
+}
+
+func TestChildChain_CancelTx(t *testing.T) {
+	a, b := newTestAccounts(t)
+
+	testCases := []struct {
+		name string
+		sign func(t *testing.T, parentTx, childTx *types.Tx) types.Signature
+		err  error
+	}{
+		{
+			"signed by the input owner",
+			func(t *testing.T, parentTx, childTx *types.Tx) types.Signature {
+				sig, err := parentTx.SignCancellation(a)
+				require.NoError(t, err)
+				return sig
+			},
+			nil,
+		},
+		{
+			"signed by another account",
+			func(t *testing.T, parentTx, childTx *types.Tx) types.Signature {
+				sig, err := parentTx.SignCancellation(b)
+				require.NoError(t, err)
+				return sig
+			},
+			ErrInvalidTxCancellationSignature,
+		},
+		{
+			"signed for another tx",
+			func(t *testing.T, parentTx, childTx *types.Tx) types.Signature {
+				sig, err := childTx.SignCancellation(a)
+				require.NoError(t, err)
+				return sig
+			},
+			ErrInvalidTxCancellationSignature,
+		},
+		{
+			"not signed",
+			func(t *testing.T, parentTx, childTx *types.Tx) types.Signature {
+				return types.NullSignature
+			},
+			ErrInvalidTxCancellationSignature,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, closeDB := openTestDB(t)
+			defer closeDB()
+
+			txn, cc := newTestChildChain(t, db)
+			defer txn.Discard()
+
+			parentTx, childTx := addTestChainedTxes(t, txn, cc, a, b)
+			parentTxHash, err := parentTx.Hash()
+			require.NoError(t, err)
+			childTxHash, err := childTx.Hash()
+			require.NoError(t, err)
+
+			removedTxHashes, err := cc.CancelTx(txn, parentTxHash, tc.sign(t, parentTx, childTx))
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				requireTestTxState(t, txn, cc, TxStatePending, parentTx, childTx)
+				return
+			}
+			require.NoError(t, err)
+
+			// the tx spending its output is removed as well
+			require.ElementsMatch(t, []common.Hash{parentTxHash, childTxHash}, removedTxHashes)
+			requireTestTxState(t, txn, cc, TxStateEvicted, parentTx, childTx)
+
+			// the deposit can be spent again
+			require.NoError(t, cc.AddTxToMempool(txn, parentTx))
+		})
+	}
+}
+
+func TestChildChain_EvictExpiredTxesFromMempool(t *testing.T) {
+	a, b := newTestAccounts(t)
+
+	testCases := []struct {
+		name         string
+		expireChild  bool
+		expireParent bool
+		evictsChild  bool
+		evictsParent bool
+	}{
+		{"nothing expired", false, false, false, false},
+		{"chained tx expired", true, false, true, false},
+		{"parent expired", false, true, true, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, closeDB := openTestDB(t)
+			defer closeDB()
+
+			txn, cc := newTestChildChain(t, db)
+			defer txn.Discard()
+
+			parentTx, childTx := addTestChainedTxes(t, txn, cc, a, b)
+
+			// backdate the txes to expire
+			for tx, expire := range map[*types.Tx]bool{parentTx: tc.expireParent, childTx: tc.expireChild} {
+				if !expire {
+					continue
+				}
+				txHash, err := tx.Hash()
+				require.NoError(t, err)
+				mtx, err := cc.getMempoolTx(txn, txHash)
+				require.NoError(t, err)
+				mtx.AddedAt -= 3600
+				require.NoError(t, cc.setMempoolTx(txn, mtx))
+			}
+
+			removedTxHashes, err := cc.EvictExpiredTxesFromMempool(txn, time.Now().Add(-time.Minute))
+			require.NoError(t, err)
+
+			expectedTxHashes := []common.Hash{}
+			for tx, evicted := range map[*types.Tx]bool{parentTx: tc.evictsParent, childTx: tc.evictsChild} {
+				state := TxStatePending
+				if evicted {
+					txHash, err := tx.Hash()
+					require.NoError(t, err)
+					expectedTxHashes = append(expectedTxHashes, txHash)
+					state = TxStateEvicted
+				}
+				requireTestTxState(t, txn, cc, state, tx)
+			}
+			require.ElementsMatch(t, expectedTxHashes, removedTxHashes)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package core
+
+import "github.com/ethereum/go-ethereum/common"
+
+// BlockDrift is a block whose stored root differs from the root committed to the root chain.
+// Root is null if the block is not stored.
+type BlockDrift struct {
+	BlockNumber   uint64      `json:"blknum"`
+	Root          common.Hash `json:"root"`
+	CommittedRoot common.Hash `json:"committed"`
+}
+
+// ConsistencyReport compares the stored blocks with the root chain.
+// Both block numbers are the numbers of the next block.
+type ConsistencyReport struct {
+	BlockNumber     uint64        `json:"blknum"`
+	RootBlockNumber uint64        `json:"rootblknum"`
+	Drifts          []*BlockDrift `json:"drifts"`
+}
+
+func (report *ConsistencyReport) IsConsistent() bool {
+	return report.BlockNumber == report.RootBlockNumber && len(report.Drifts) == 0
+}
+
+// CheckConsistency recomputes the root of every stored block that is also committed to the root chain
+// and compares it with the committed root.
+func (cc *ChildChain) CheckConsistency(txn Txn, rc *RootChain) (*ConsistencyReport, error) {
+	blkNum, err := cc.GetCurrentBlockNumber(txn)
+	if err != nil {
+		return nil, err
+	}
+	rootBlkNum, err := rc.CurrentPlasmaBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ConsistencyReport{
+		BlockNumber:     blkNum,
+		RootBlockNumber: rootBlkNum,
+		Drifts:          []*BlockDrift{},
+	}
+
+	lastBlkNum := blkNum
+	if rootBlkNum < lastBlkNum {
+		lastBlkNum = rootBlkNum
+	}
+
+	for i := uint64(FirstBlockNumber); i < lastBlkNum; i++ {
+		pb, err := rc.PlasmaBlocks(i)
+		if err != nil {
+			return nil, err
+		}
+
+		// the root of a pruned block is rebuilt from the leaf hashes kept
+		rootHash, err := cc.GetBlockRoot(txn, i)
+		if err != nil && err != ErrBlockNotFound {
+			return nil, err
+		}
+
+		if rootHash != pb.RootHash() {
+			report.Drifts = append(report.Drifts, &BlockDrift{
+				BlockNumber:   i,
+				Root:          rootHash,
+				CommittedRoot: pb.RootHash(),
+			})
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChildChain_CheckConsistency(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	blkNum := addTestBlock(t, txn, cc, a, newTestSplitTx(t, depositBlkNum, a, b))
+
+	roots := map[uint64][32]byte{}
+	for _, i := range []uint64{depositBlkNum, blkNum} {
+		rootHash, err := cc.GetBlockRoot(txn, i)
+		require.NoError(t, err)
+		roots[i] = rootHash
+	}
+
+	check := func(rootBlkNum uint64) *ConsistencyReport {
+		rc, closeRC := newTestRootChain(t, map[string]testRootChainCall{
+			"currentPlasmaBlockNumber": newTestUintCall(rootBlkNum),
+			"plasmaBlocks":             newTestPlasmaBlocksCall(roots),
+		})
+		defer closeRC()
+
+		report, err := cc.CheckConsistency(txn, rc)
+		require.NoError(t, err)
+		return report
+	}
+
+	report := check(blkNum + 1)
+	require.True(t, report.IsConsistent())
+	require.Equal(t, blkNum+1, report.BlockNumber)
+	require.Equal(t, blkNum+1, report.RootBlockNumber)
+	require.Empty(t, report.Drifts)
+
+	// the last root is not committed yet
+	report = check(blkNum)
+	require.False(t, report.IsConsistent())
+	require.Equal(t, blkNum, report.RootBlockNumber)
+	require.Empty(t, report.Drifts)
+
+	// another root is committed
+	storedRootHash := roots[blkNum]
+	roots[blkNum] = [32]byte{0x01}
+	report = check(blkNum + 1)
+	require.False(t, report.IsConsistent())
+	require.Len(t, report.Drifts, 1)
+	require.Equal(t, &BlockDrift{
+		BlockNumber:   blkNum,
+		Root:          storedRootHash,
+		CommittedRoot: roots[blkNum],
+	}, report.Drifts[0])
+
+	// the root chain is ahead of the stored blocks
+	roots[blkNum] = storedRootHash
+	report = check(blkNum + 2)
+	require.False(t, report.IsConsistent())
+	require.Equal(t, blkNum+1, report.BlockNumber)
+	require.Empty(t, report.Drifts)
+}
@@ -1,25 +1,124 @@
 package core
 
-import "errors"
+import (
+	"fmt"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+)
 
 var (
-	ErrMempoolFull = errors.New("mempool is full")
+	ErrMempoolFull         = NewError("mempool_full", "mempool is full")
+	ErrMempoolTxNotFound   = NewError("mempool_tx_not_found", "tx is not found in mempool")
+	ErrInvalidMempoolOrder = NewError("invalid_mempool_order", "mempool txes cannot be ordered")
+
+	ErrBlockNotFound      = NewError("block_not_found", "block is not found")
+	ErrEmptyBlock         = NewError("empty_block", "block is empty")
+	ErrBlockNotCommitted  = NewError("block_not_committed", "block is not committed to root chain")
+	ErrInvalidBlockNumber = NewError("invalid_block_number", "block number is invalid")
+	ErrBlockPruned        = NewError("block_pruned", "block is pruned")
+
+	ErrTxNotFound                     = NewError("tx_not_found", "tx is not found")
+	ErrInvalidTxSignature             = NewError("invalid_tx_signature", "tx signature is invalid")
+	ErrInvalidTxConfirmationSignature = NewError("invalid_tx_confirmation_signature", "tx confirmation signature is invalid")
+	ErrInvalidTxBalance               = NewError("invalid_tx_balance", "tx balance is invalid")
+	ErrInvalidTxCancellationSignature = NewError("invalid_tx_cancellation_signature", "tx cancellation signature is invalid")
+	ErrTxPruned                       = NewError("tx_pruned", "tx is pruned")
+	ErrInvalidDepositTx               = NewError("invalid_deposit_tx", "deposit tx is not backed by root chain")
+
+	ErrTxInNotFound         = NewError("txin_not_found", "txin is not found")
+	ErrInvalidTxIn          = NewError("invalid_txin", "txin is invalid")
+	ErrNullTxInConfirmation = NewError("null_txin_confirmation", "null txin cannot be confirmed")
 
-	ErrBlockNotFound = errors.New("block is not found")
-	ErrEmptyBlock    = errors.New("block is empty")
+	ErrTxOutNotFound      = NewError("txout_not_found", "txout is not found")
+	ErrTxOutAlreadySpent  = NewError("txout_already_spent", "txout was already spent")
+	ErrTxOutAlreadyExited = NewError("txout_already_exited", "txout was already exited")
+	ErrTxOutNotSpent      = NewError("txout_not_spent", "txout is not spent in block")
 
-	ErrTxNotFound                     = errors.New("tx is not found")
-	ErrInvalidTxSignature             = errors.New("tx signature is invalid")
-	ErrInvalidTxConfirmationSignature = errors.New("tx confirmation signature is invalid")
-	ErrInvalidTxBalance               = errors.New("tx balance is invalid")
+	ErrNullConfirmationSignature = NewError("null_confirmation_signature", "confirmation signature is null")
 
-	ErrTxInNotFound         = errors.New("txin is not found")
-	ErrInvalidTxIn          = errors.New("txin is invalid")
-	ErrNullTxInConfirmation = errors.New("null txin cannot be confirmed")
+	ErrChildChainNotFound = NewError("child_chain_not_found", "child chain is not found")
 
-	ErrTxOutNotFound      = errors.New("txout is not found")
-	ErrTxOutAlreadySpent  = errors.New("txout was already spent")
-	ErrTxOutAlreadyExited = errors.New("txout was already exited")
+	ErrStateMachineStopped = NewError("state_machine_stopped", "state machine is stopped")
 
-	ErrNullConfirmationSignature = errors.New("confirmation signature is null")
+	ErrArchivedBlockCorrupted = NewError("archived_block_corrupted", "archived block is corrupted")
 )
+
+// Error is a child chain error identified by a stable code.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func NewError(code, msg string) *Error {
+	return &Error{
+		Code:    code,
+		Message: msg,
+	}
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+// TxInError is an error attributed to one of the inputs of a tx.
+// Position is the position of the txout referenced by the input,
+// or the position of the input itself if the tx has no such input.
+type TxInError struct {
+	Cause    *Error
+	Index    uint64
+	Position types.Position
+}
+
+func NewTxInError(cause *Error, inIndex uint64, pos types.Position) *TxInError {
+	return &TxInError{
+		Cause:    cause,
+		Index:    inIndex,
+		Position: pos,
+	}
+}
+
+func (err *TxInError) Error() string {
+	return fmt.Sprintf("%s: txin %d (%d)", err.Cause.Message, err.Index, err.Position)
+}
+
+func (err *TxInError) Unwrap() error {
+	return err.Cause
+}
+
+// TxOutError is an error attributed to one of the outputs of a tx.
+// Position is the position of the txout itself.
+type TxOutError struct {
+	Cause    *Error
+	Index    uint64
+	Position types.Position
+}
+
+func NewTxOutError(cause *Error, outIndex uint64, txOutPos types.Position) *TxOutError {
+	return &TxOutError{
+		Cause:    cause,
+		Index:    outIndex,
+		Position: txOutPos,
+	}
+}
+
+func (err *TxOutError) Error() string {
+	return fmt.Sprintf("%s: txout %d (%d)", err.Cause.Message, err.Index, err.Position)
+}
+
+func (err *TxOutError) Unwrap() error {
+	return err.Cause
+}
+
+// Cause returns the *Error underlying err, or nil if err is not a child chain error.
+func Cause(err error) *Error {
+	switch e := err.(type) {
+	case *Error:
+		return e
+	case *TxInError:
+		return e.Cause
+	case *TxOutError:
+		return e.Cause
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+)
+
+// ExitData holds the arguments of startExit of the root chain for a txout,
+// encoded exactly as the contract expects them, and the bond to send with it.
+type ExitData struct {
+	TxOutPosition          types.Position `json:"pos"`
+	BlockNumber            uint64         `json:"blknum"`
+	TxIndex                uint64         `json:"txindex"`
+	OutputIndex            uint64         `json:"oindex"`
+	EncodedTx              hexutil.Bytes  `json:"tx"`
+	TxProof                hexutil.Bytes  `json:"proof"`
+	Signatures             hexutil.Bytes  `json:"sigs"`
+	ConfirmationSignatures hexutil.Bytes  `json:"confsigs"`
+	Bond                   *big.Int       `json:"bond"`
+}
+
+func NewExitData(txOutPos types.Position, tx *types.Tx, txProofBytes []byte) (*ExitData, error) {
+	blkNum, txIndex, outIndex := types.ParseTxOutPosition(txOutPos)
+
+	encodedTxBytes, err := tx.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	sigsBytes, err := tx.SignaturesBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	confSigsBytes, err := tx.ConfirmationSignaturesBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExitData{
+		TxOutPosition:          txOutPos,
+		BlockNumber:            blkNum,
+		TxIndex:                txIndex,
+		OutputIndex:            outIndex,
+		EncodedTx:              encodedTxBytes,
+		TxProof:                txProofBytes,
+		Signatures:             sigsBytes,
+		ConfirmationSignatures: confSigsBytes,
+		Bond:                   big.NewInt(DefaultExitBondAmount),
+	}, nil
+}
+
+// Spender is the input of a tx in a block that spends a txout,
+// which is what challengeExit of the root chain takes to challenge an exit of the txout.
+type Spender struct {
+	TxOutPosition         types.Position  `json:"pos"`
+	TxInPosition          types.Position  `json:"vspos"`
+	TxPosition            types.Position  `json:"txpos"`
+	InputIndex            uint64          `json:"index"`
+	Tx                    *types.Tx       `json:"tx"`
+	ConfirmationSignature types.Signature `json:"confsig"`
+}
+
+// GetSpender returns the input spending the txout at txOutPos, found through the address index.
+// The txes may be pruned, in which case the spending tx lacks the signatures, which challengeExit does not take.
+func (cc *ChildChain) GetSpender(txn Txn, txOutPos types.Position) (*Spender, error) {
+	blkNum, txIndex, outIndex := types.ParseTxOutPosition(txOutPos)
+
+	// get txout
+	tx, err := cc.getStoredTx(txn, blkNum, txIndex)
+	if err != nil {
+		if err == ErrKeyNotFound {
+			return nil, ErrTxNotFound
+		} else {
+			return nil, err
+		}
+	}
+	txOut := tx.GetOutput(outIndex)
+	if txOut == nil || txOut.OwnerAddress == types.NullAddress {
+		return nil, NewTxOutError(ErrTxOutNotFound, outIndex, txOutPos)
+	}
+
+	// get token
+	token, err := cc.getToken(txn, txOut.OwnerAddress, txOutPos)
+	if err != nil {
+		return nil, err
+	}
+	if !token.IsSpent() {
+		return nil, NewTxOutError(ErrTxOutNotSpent, outIndex, txOutPos)
+	}
+
+	// get spending tx
+	spendingBlkNum, spendingTxIndex, spendingInIndex := types.ParseTxInPosition(token.SpendingTxInPos)
+	spendingTxPos := types.NewTxPosition(spendingBlkNum, spendingTxIndex)
+	spendingTx, err := cc.getStoredTx(txn, spendingBlkNum, spendingTxIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Spender{
+		TxOutPosition:         txOutPos,
+		TxInPosition:          token.SpendingTxInPos,
+		TxPosition:            spendingTxPos,
+		InputIndex:            spendingInIndex,
+		Tx:                    spendingTx,
+		ConfirmationSignature: spendingTx.GetInput(spendingInIndex).ConfirmationSignature,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChildChain_GetExitData(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	tx := newTestSplitTx(t, depositBlkNum, a, b)
+	blkNum := addTestBlock(t, txn, cc, a, tx)
+	txOutPos := types.NewTxOutPosition(blkNum, 0, 1)
+
+	exitData, err := cc.GetExitData(txn, txOutPos)
+	require.NoError(t, err)
+	require.Equal(t, txOutPos, exitData.TxOutPosition)
+	require.Equal(t, blkNum, exitData.BlockNumber)
+	require.Equal(t, uint64(0), exitData.TxIndex)
+	require.Equal(t, uint64(1), exitData.OutputIndex)
+
+	encodedTxBytes, err := tx.Encode()
+	require.NoError(t, err)
+	require.Equal(t, encodedTxBytes, []byte(exitData.EncodedTx))
+	sigsBytes, err := tx.SignaturesBytes()
+	require.NoError(t, err)
+	require.Equal(t, sigsBytes, []byte(exitData.Signatures))
+	txProofBytes, err := cc.GetTxProof(txn, types.NewTxPosition(blkNum, 0))
+	require.NoError(t, err)
+	require.Equal(t, txProofBytes, []byte(exitData.TxProof))
+
+	// the deposit tx has a null second output
+	nullTxOutPos := types.NewTxOutPosition(depositBlkNum, 0, 1)
+	_, err = cc.GetExitData(txn, nullTxOutPos)
+	require.Equal(t, NewTxOutError(ErrTxOutNotFound, 1, nullTxOutPos), err)
+
+	_, err = cc.GetExitData(txn, types.NewTxOutPosition(blkNum, 1, 0))
+	require.Equal(t, ErrTxNotFound, Cause(err))
+}
+
+func TestChildChain_GetSpender(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	depositTxOutPos := types.NewTxOutPosition(depositBlkNum, 0, 0)
+
+	// spent in the mempool only
+	tx := newTestSplitTx(t, depositBlkNum, a, b)
+	require.NoError(t, cc.AddTxToMempool(txn, tx))
+	_, err = cc.GetSpender(txn, depositTxOutPos)
+	require.Equal(t, NewTxOutError(ErrTxOutNotSpent, 0, depositTxOutPos), err)
+
+	blkNum, err := cc.AddBlock(txn, a)
+	require.NoError(t, err)
+	require.NoError(t, tx.Confirm(0, a))
+	require.NoError(t, cc.ConfirmTx(txn, types.NewTxInPosition(blkNum, 0, 0), tx.GetInput(0).ConfirmationSignature))
+
+	spender, err := cc.GetSpender(txn, depositTxOutPos)
+	require.NoError(t, err)
+	require.Equal(t, depositTxOutPos, spender.TxOutPosition)
+	require.Equal(t, types.NewTxInPosition(blkNum, 0, 0), spender.TxInPosition)
+	require.Equal(t, types.NewTxPosition(blkNum, 0), spender.TxPosition)
+	require.Equal(t, uint64(0), spender.InputIndex)
+	require.Equal(t, tx.GetInput(0).ConfirmationSignature, spender.ConfirmationSignature)
+
+	// the outputs of the spending tx are not spent yet
+	txOutPos := types.NewTxOutPosition(blkNum, 0, 0)
+	_, err = cc.GetSpender(txn, txOutPos)
+	require.Equal(t, NewTxOutError(ErrTxOutNotSpent, 0, txOutPos), err)
+}
@@ -0,0 +1,350 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+)
+
+// FraudTx is a tx of a block with its membership proof.
+// Root is the root the proof is against, and CommittedRoot is the root committed to the root chain,
+// with which the proof can only be verified if both are the same.
+type FraudTx struct {
+	Position      types.Position `json:"txpos"`
+	Tx            *types.Tx      `json:"tx"`
+	Proof         hexutil.Bytes  `json:"proof"`
+	Root          common.Hash    `json:"root"`
+	CommittedRoot common.Hash    `json:"committed"`
+}
+
+// FraudEvidence is a violation of the tx validation rules by a tx in a block.
+// Txes holds the invalid tx first, followed by the txes it is checked against:
+// the txes of its inputs and, for a double spend, the tx that spent the txout before.
+// TxInPosition is the position of the invalid input, if any.
+type FraudEvidence struct {
+	Code         string         `json:"code"`
+	Message      string         `json:"message"`
+	TxPosition   types.Position `json:"txpos"`
+	TxInPosition types.Position `json:"vspos,omitempty"`
+	Txes         []*FraudTx     `json:"txes"`
+}
+
+// FraudReport holds the evidence found in the blocks before BlockNumber.
+type FraudReport struct {
+	BlockNumber uint64           `json:"blknum"`
+	Evidence    []*FraudEvidence `json:"evidence"`
+}
+
+func (report *FraudReport) IsValid() bool {
+	return len(report.Evidence) == 0
+}
+
+// FindFraud re-validates the txes of the blocks committed to the root chain in the order they were included,
+// each against the txouts spent before it, and returns evidence for every violation found.
+// Txes with no inputs are only valid as deposit txes backed by DepositCreated events on the root chain.
+// Pruned txes, which have no signatures, are not validated, though the txouts they spent are taken as spent.
+// Exits are not taken into account, since whether a txout was exited before it was spent is only known to the root chain.
+func (cc *ChildChain) FindFraud(txn Txn, rc *RootChain) (*FraudReport, error) {
+	blkNum, err := cc.GetCurrentBlockNumber(txn)
+	if err != nil {
+		return nil, err
+	}
+	rootBlkNum, err := rc.CurrentPlasmaBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	if rootBlkNum < blkNum {
+		blkNum = rootBlkNum
+	}
+
+	events, err := rc.FilterDepositCreated(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	deposits := map[uint64]*RootChainDepositCreated{}
+	for _, event := range events {
+		deposits[event.DepositBlock.Uint64()] = event
+	}
+
+	report, err := cc.findFraud(txn, blkNum, deposits)
+	if err != nil {
+		return nil, err
+	}
+
+	committedRootHashes := map[uint64]common.Hash{}
+	for _, evidence := range report.Evidence {
+		for _, ftx := range evidence.Txes {
+			ftxBlkNum, _ := types.ParseTxPosition(ftx.Position)
+
+			rootHash, ok := committedRootHashes[ftxBlkNum]
+			if !ok {
+				pb, err := rc.PlasmaBlocks(ftxBlkNum)
+				if err != nil {
+					return nil, err
+				}
+				rootHash = pb.RootHash()
+				committedRootHashes[ftxBlkNum] = rootHash
+			}
+
+			ftx.CommittedRoot = rootHash
+		}
+	}
+
+	return report, nil
+}
+
+// fraudChecker replays the blocks, keeping track of the txouts spent so far.
+type fraudChecker struct {
+	cc                *ChildChain
+	txn               Txn
+	report            *FraudReport
+	deposits          map[uint64]*RootChainDepositCreated
+	spendingTxInPoses map[types.Position]types.Position
+	leafHashes        map[uint64][]common.Hash
+}
+
+// findFraud checks the blocks before lastBlkNum, with deposits, the DepositCreated events by deposit block number.
+func (cc *ChildChain) findFraud(txn Txn, lastBlkNum uint64, deposits map[uint64]*RootChainDepositCreated) (*FraudReport, error) {
+	fc := &fraudChecker{
+		cc:  cc,
+		txn: txn,
+		report: &FraudReport{
+			BlockNumber: lastBlkNum,
+			Evidence:    []*FraudEvidence{},
+		},
+		deposits:          deposits,
+		spendingTxInPoses: map[types.Position]types.Position{},
+		leafHashes:        map[uint64][]common.Hash{},
+	}
+
+	for blkNum := uint64(FirstBlockNumber); blkNum < lastBlkNum; blkNum++ {
+		for txIndex := uint64(0); ; txIndex++ {
+			ok, err := fc.checkTx(blkNum, txIndex)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+		}
+	}
+
+	return fc.report, nil
+}
+
+// checkTx validates the tx at the position and records the txouts it spends.
+// It returns false if there is no tx at the position.
+func (fc *fraudChecker) checkTx(blkNum, txIndex uint64) (bool, error) {
+	txPos := types.NewTxPosition(blkNum, txIndex)
+
+	isPruned := false
+	tx, err := fc.cc.getTx(fc.txn, blkNum, txIndex)
+	if err != nil {
+		if err != ErrKeyNotFound {
+			return false, err
+		}
+
+		prunedTx, err := fc.cc.getPrunedTx(fc.txn, blkNum, txIndex)
+		if err != nil {
+			if err == ErrKeyNotFound {
+				return false, nil
+			} else {
+				return false, err
+			}
+		}
+		tx, isPruned = prunedTx.Tx, true
+	}
+
+	// the txouts spent by pruned txes were spent long enough ago to be valid
+	if isPruned {
+		for i, txIn := range tx.Inputs {
+			if txIn.IsNull() {
+				continue
+			}
+
+			inTxOutPos := types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)
+			if _, ok := fc.spendingTxInPoses[inTxOutPos]; !ok {
+				fc.spendingTxInPoses[inTxOutPos] = types.NewTxInPosition(blkNum, txIndex, uint64(i))
+			}
+		}
+
+		return true, nil
+	}
+
+	inputAmount := big.NewInt(0)
+	inTxPoses := []types.Position{}
+	nullTxInNum := 0
+
+	for i, txIn := range tx.Inputs {
+		// skip validation if txin is null (deposit)
+		if txIn.IsNull() {
+			nullTxInNum++
+			continue
+		}
+
+		txInPos := types.NewTxInPosition(blkNum, txIndex, uint64(i))
+		inTxPos := types.NewTxPosition(txIn.BlockNumber, txIn.TxIndex)
+		inTxOutPos := types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)
+
+		// check if input txout is of a tx included before
+		if inTxPos >= txPos {
+			if err := fc.addEvidence(ErrInvalidTxIn, txInPos, txPos); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		// get input txout
+		inTx, err := fc.cc.getStoredTx(fc.txn, txIn.BlockNumber, txIn.TxIndex)
+		if err != nil {
+			if err == ErrKeyNotFound { // tx is not found
+				if err := fc.addEvidence(ErrInvalidTxIn, txInPos, txPos); err != nil {
+					return false, err
+				}
+				continue
+			} else {
+				return false, err
+			}
+		}
+		inTxOut := inTx.GetOutput(txIn.OutputIndex)
+		if inTxOut == nil || inTxOut.OwnerAddress == types.NullAddress { // tx does not have the output
+			if err := fc.addEvidence(ErrInvalidTxIn, txInPos, txPos, inTxPos); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		inTxPoses = append(inTxPoses, inTxPos)
+		inputAmount.Add(inputAmount, inTxOut.Amount)
+
+		// check if input txout is not spent by a tx included before
+		if spendingTxInPos, ok := fc.spendingTxInPoses[inTxOutPos]; ok {
+			spendingBlkNum, spendingTxIndex, _ := types.ParseTxInPosition(spendingTxInPos)
+			if err := fc.addEvidence(ErrTxOutAlreadySpent, txInPos,
+				txPos, inTxPos, types.NewTxPosition(spendingBlkNum, spendingTxIndex)); err != nil {
+				return false, err
+			}
+		} else {
+			fc.spendingTxInPoses[inTxOutPos] = txInPos
+		}
+
+		// verify signature
+		signerAddr, err := tx.SignerAddress(uint64(i))
+		if err != nil || txIn.Signature == types.NullSignature ||
+			!bytes.Equal(signerAddr.Bytes(), inTxOut.OwnerAddress.Bytes()) {
+			if err := fc.addEvidence(ErrInvalidTxSignature, txInPos, txPos, inTxPos); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	// null txes pad blocks and have no effect
+	if tx.IsNull() {
+		return true, nil
+	}
+
+	// deposit txes have no inputs and are the only txes of their blocks
+	if nullTxInNum == len(tx.Inputs) {
+		leafHashes, err := fc.getLeafHashes(blkNum)
+		if err != nil {
+			return false, err
+		}
+		if len(leafHashes) != 1 || verifyDepositTx(blkNum, tx, fc.deposits[blkNum]) != nil {
+			if err := fc.addEvidence(ErrInvalidDepositTx, 0, txPos); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	// check in/out balance
+	outputAmount := big.NewInt(0)
+	for _, txOut := range tx.Outputs {
+		outputAmount.Add(outputAmount, txOut.Amount)
+	}
+	if outputAmount.Cmp(inputAmount) > 0 {
+		if err := fc.addEvidence(ErrInvalidTxBalance, 0, append([]types.Position{txPos}, inTxPoses...)...); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// addEvidence adds evidence of the violation with the txes at txPoses.
+func (fc *fraudChecker) addEvidence(cause *Error, txInPos types.Position, txPoses ...types.Position) error {
+	evidence := &FraudEvidence{
+		Code:         cause.Code,
+		Message:      cause.Message,
+		TxPosition:   txPoses[0],
+		TxInPosition: txInPos,
+		Txes:         []*FraudTx{},
+	}
+
+	isAdded := map[types.Position]bool{}
+	for _, txPos := range txPoses {
+		// a tx may spend more than one txout of the same tx
+		if isAdded[txPos] {
+			continue
+		}
+		isAdded[txPos] = true
+
+		ftx, err := fc.fraudTx(txPos)
+		if err != nil {
+			return err
+		}
+		evidence.Txes = append(evidence.Txes, ftx)
+	}
+
+	fc.report.Evidence = append(fc.report.Evidence, evidence)
+
+	return nil
+}
+
+func (fc *fraudChecker) fraudTx(txPos types.Position) (*FraudTx, error) {
+	blkNum, txIndex := types.ParseTxPosition(txPos)
+
+	tx, err := fc.cc.getStoredTx(fc.txn, blkNum, txIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	leafHashes, err := fc.getLeafHashes(blkNum)
+	if err != nil {
+		return nil, err
+	}
+
+	proofBytes, err := types.CreateTxMerkleProof(leafHashes, txIndex)
+	if err != nil {
+		return nil, err
+	}
+	rootHash, err := types.TxMerkleRoot(leafHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FraudTx{
+		Position: txPos,
+		Tx:       tx,
+		Proof:    proofBytes,
+		Root:     rootHash,
+	}, nil
+}
+
+func (fc *fraudChecker) getLeafHashes(blkNum uint64) ([]common.Hash, error) {
+	if leafHashes, ok := fc.leafHashes[blkNum]; ok {
+		return leafHashes, nil
+	}
+
+	leafHashes, err := fc.cc.getBlockLeafHashes(fc.txn, blkNum)
+	if err != nil {
+		return nil, err
+	}
+	fc.leafHashes[blkNum] = leafHashes
+
+	return leafHashes, nil
+}
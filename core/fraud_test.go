@@ -0,0 +1,167 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDepositCreated builds the DepositCreated event of a deposit of amount by owner in the block at blkNum.
+func newTestDepositCreated(owner common.Address, amount int64, blkNum uint64) *RootChainDepositCreated {
+	return &RootChainDepositCreated{
+		Owner:        owner,
+		Amount:       big.NewInt(amount),
+		DepositBlock: new(big.Int).SetUint64(blkNum),
+	}
+}
+
+func TestChildChain_FindFraud(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNumA, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	depositBlkNumB, _, err := cc.AddDepositBlock(txn, b.Address(), big.NewInt(50), a)
+	require.NoError(t, err)
+	blkNum := addTestBlock(t, txn, cc, a, newTestSplitTx(t, depositBlkNumA, a, b))
+
+	deposits := map[uint64]*RootChainDepositCreated{
+		depositBlkNumA: newTestDepositCreated(a.Address(), 100, depositBlkNumA),
+		depositBlkNumB: newTestDepositCreated(b.Address(), 50, depositBlkNumB),
+	}
+
+	report, err := cc.findFraud(txn, blkNum+1, deposits)
+	require.NoError(t, err)
+	require.True(t, report.IsValid())
+
+	// a block added without validation, spending the deposit of a again
+	// and the deposit of b with a signature of a
+	doubleSpendingTx := newTestSpendingTx(t, types.NewTxIn(depositBlkNumA, 0, 0), a, types.NewTxOut(a.Address(), big.NewInt(100)))
+	forgedTx := newTestSpendingTx(t, types.NewTxIn(depositBlkNumB, 0, 0), a, types.NewTxOut(a.Address(), big.NewInt(50)))
+	blk := addTestInvalidBlock(t, txn, cc, doubleSpendingTx, forgedTx)
+
+	report, err = cc.findFraud(txn, blk.Number+1, deposits)
+	require.NoError(t, err)
+	require.Len(t, report.Evidence, 2)
+
+	rootHash, err := blk.Root()
+	require.NoError(t, err)
+	tree, err := blk.MerkleTree()
+	require.NoError(t, err)
+
+	// double spend
+	evidence := report.Evidence[0]
+	require.Equal(t, ErrTxOutAlreadySpent.Code, evidence.Code)
+	require.Equal(t, types.NewTxPosition(blkNum+1, 0), evidence.TxPosition)
+	require.Equal(t, types.NewTxInPosition(blkNum+1, 0, 0), evidence.TxInPosition)
+	require.Len(t, evidence.Txes, 3)
+	require.Equal(t, types.NewTxPosition(depositBlkNumA, 0), evidence.Txes[1].Position)
+	require.Equal(t, types.NewTxPosition(blkNum, 0), evidence.Txes[2].Position)
+
+	proof, err := tree.CreateMembershipProof(0)
+	require.NoError(t, err)
+	require.Equal(t, proof, []byte(evidence.Txes[0].Proof))
+	require.Equal(t, rootHash, evidence.Txes[0].Root)
+
+	// signature not of the owner
+	evidence = report.Evidence[1]
+	require.Equal(t, ErrInvalidTxSignature.Code, evidence.Code)
+	require.Equal(t, types.NewTxPosition(blkNum+1, 1), evidence.TxPosition)
+	require.Len(t, evidence.Txes, 2)
+	forgedTxHash, err := forgedTx.Hash()
+	require.NoError(t, err)
+	evidenceTxHash, err := evidence.Txes[0].Tx.Hash()
+	require.NoError(t, err)
+	require.Equal(t, forgedTxHash, evidenceTxHash)
+
+	proof, err = tree.CreateMembershipProof(1)
+	require.NoError(t, err)
+	require.Equal(t, proof, []byte(evidence.Txes[0].Proof))
+}
+
+func TestChildChain_FindFraud_DepositTx(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	// blocks 1, 2 and 3: deposits, of which only the first is backed as made
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	unbackedBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	mismatchedBlkNum, _, err := cc.AddDepositBlock(txn, b.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+
+	// block 4: a tx with no inputs alongside another tx
+	depositTx := types.NewTx()
+	require.NoError(t, depositTx.SetOutput(0, types.NewTxOut(a.Address(), big.NewInt(100))))
+	blk := addTestInvalidBlock(t, txn, cc, depositTx, newTestSplitTx(t, depositBlkNum, a, b))
+
+	deposits := map[uint64]*RootChainDepositCreated{
+		depositBlkNum:    newTestDepositCreated(a.Address(), 100, depositBlkNum),
+		mismatchedBlkNum: newTestDepositCreated(b.Address(), 50, mismatchedBlkNum),
+		blk.Number:       newTestDepositCreated(a.Address(), 100, blk.Number),
+	}
+
+	report, err := cc.findFraud(txn, blk.Number+1, deposits)
+	require.NoError(t, err)
+	require.Len(t, report.Evidence, 3)
+
+	for i, txPos := range []types.Position{
+		types.NewTxPosition(unbackedBlkNum, 0),
+		types.NewTxPosition(mismatchedBlkNum, 0),
+		types.NewTxPosition(blk.Number, 0),
+	} {
+		evidence := report.Evidence[i]
+		require.Equal(t, ErrInvalidDepositTx.Code, evidence.Code)
+		require.Equal(t, txPos, evidence.TxPosition)
+		require.Len(t, evidence.Txes, 1)
+	}
+}
+
+func TestChildChain_AddReplicatedBlock_InvalidTxIn(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+
+	blkNum, err := cc.GetCurrentBlockNumber(txn)
+	require.NoError(t, err)
+
+	// a block committed by the operator, spending a tx which was never included
+	tx := newTestSpendingTx(t, types.NewTxIn(depositBlkNum, 1, 0), a, types.NewTxOut(b.Address(), big.NewInt(100)))
+	blk, err := types.NewBlock([]*types.Tx{tx}, blkNum)
+	require.NoError(t, err)
+
+	// replicated as committed, and reported by FindFraud
+	require.NoError(t, cc.AddReplicatedBlock(txn, blk))
+
+	deposits := map[uint64]*RootChainDepositCreated{
+		depositBlkNum: newTestDepositCreated(a.Address(), 100, depositBlkNum),
+	}
+
+	report, err := cc.findFraud(txn, blkNum+1, deposits)
+	require.NoError(t, err)
+	require.Len(t, report.Evidence, 2)
+	require.Equal(t, ErrInvalidTxIn.Code, report.Evidence[0].Code)
+	require.Equal(t, types.NewTxInPosition(blkNum, 0, 0), report.Evidence[0].TxInPosition)
+	require.Equal(t, ErrInvalidTxBalance.Code, report.Evidence[1].Code)
+}
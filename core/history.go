@@ -0,0 +1,125 @@
+package core
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+)
+
+const (
+	AddressTxDirectionIn  = "in"
+	AddressTxDirectionOut = "out"
+)
+
+// AddressTx is a tx in the history of an address.
+// A tx is incoming if it has an output owned by the address
+// and outgoing if it spends an output owned by the address, so it can be both.
+type AddressTx struct {
+	Position types.Position `json:"txpos"`
+	Incoming bool           `json:"in"`
+	Outgoing bool           `json:"out"`
+	Received *big.Int       `json:"received"`
+	Sent     *big.Int       `json:"sent"`
+}
+
+func newAddressTx(txPos types.Position) *AddressTx {
+	return &AddressTx{
+		Position: txPos,
+		Received: big.NewInt(0),
+		Sent:     big.NewInt(0),
+	}
+}
+
+func (atx *AddressTx) BlockNumber() uint64 {
+	blkNum, _ := types.ParseTxPosition(atx.Position)
+	return blkNum
+}
+
+// AddressTxFilter selects a page of the history of an address.
+// Zero values mean no restriction, except that a zero limit means no limit.
+type AddressTxFilter struct {
+	FromBlockNumber uint64 // inclusive
+	ToBlockNumber   uint64 // inclusive
+	Direction       string
+	Offset          uint64
+	Limit           uint64
+}
+
+func (f AddressTxFilter) match(atx *AddressTx) bool {
+	blkNum := atx.BlockNumber()
+	if f.FromBlockNumber > 0 && blkNum < f.FromBlockNumber {
+		return false
+	}
+	if f.ToBlockNumber > 0 && blkNum > f.ToBlockNumber {
+		return false
+	}
+
+	switch f.Direction {
+	case AddressTxDirectionIn:
+		return atx.Incoming
+	case AddressTxDirectionOut:
+		return atx.Outgoing
+	default:
+		return true
+	}
+}
+
+// GetAddressTxes returns the txes in blocks that sent tokens to or from addr, newest first,
+// together with the number of txes matching f before pagination.
+func (cc *ChildChain) GetAddressTxes(txn Txn, addr common.Address, f AddressTxFilter) ([]*AddressTx, uint64, error) {
+	atxes := map[types.Position]*AddressTx{}
+	getAddressTx := func(txPos types.Position) *AddressTx {
+		atx, ok := atxes[txPos]
+		if !ok {
+			atx = newAddressTx(txPos)
+			atxes[txPos] = atx
+		}
+		return atx
+	}
+
+	if err := cc.iterateTokens(txn, addr, func(txOutPos types.Position, token *Token) error {
+		// the tx that created token
+		blkNum, txIndex, _ := types.ParseTxOutPosition(txOutPos)
+		atx := getAddressTx(types.NewTxPosition(blkNum, txIndex))
+		atx.Incoming = true
+		atx.Received.Add(atx.Received, token.Amount)
+
+		// the tx that spent token
+		if token.IsSpent() {
+			blkNum, txIndex, _ := types.ParseTxInPosition(token.SpendingTxInPos)
+			atx := getAddressTx(types.NewTxPosition(blkNum, txIndex))
+			atx.Outgoing = true
+			atx.Sent.Add(atx.Sent, token.Amount)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	matched := []*AddressTx{}
+	for _, atx := range atxes {
+		if f.match(atx) {
+			matched = append(matched, atx)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Position > matched[j].Position
+	})
+
+	total := uint64(len(matched))
+
+	// paginate
+	if f.Offset >= total {
+		return []*AddressTx{}, total, nil
+	}
+	matched = matched[f.Offset:]
+	if f.Limit > 0 && f.Limit < uint64(len(matched)) {
+		matched = matched[:f.Limit]
+	}
+
+	return matched, total, nil
+}
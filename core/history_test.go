@@ -0,0 +1,83 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChildChain_GetAddressTxes(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	splitBlkNum := addTestBlock(t, txn, cc, a, newTestSplitTx(t, depositBlkNum, a, b))
+	returnBlkNum := addTestBlock(t, txn, cc, a,
+		newTestSpendingTx(t, types.NewTxIn(splitBlkNum, 0, 0), b, types.NewTxOut(a.Address(), big.NewInt(60))),
+	)
+
+	atxes, total, err := cc.GetAddressTxes(txn, a.Address(), AddressTxFilter{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), total)
+	require.Len(t, atxes, 3)
+
+	// newest first
+	require.Equal(t, types.NewTxPosition(returnBlkNum, 0), atxes[0].Position)
+	require.True(t, atxes[0].Incoming)
+	require.False(t, atxes[0].Outgoing)
+	require.Equal(t, big.NewInt(60), atxes[0].Received)
+
+	// the split tx sends 100 of a and returns 40 to a
+	require.Equal(t, types.NewTxPosition(splitBlkNum, 0), atxes[1].Position)
+	require.True(t, atxes[1].Incoming)
+	require.True(t, atxes[1].Outgoing)
+	require.Equal(t, big.NewInt(40), atxes[1].Received)
+	require.Equal(t, big.NewInt(100), atxes[1].Sent)
+
+	require.Equal(t, types.NewTxPosition(depositBlkNum, 0), atxes[2].Position)
+	require.Equal(t, big.NewInt(100), atxes[2].Received)
+
+	// outgoing only
+	atxes, total, err = cc.GetAddressTxes(txn, a.Address(), AddressTxFilter{Direction: AddressTxDirectionOut})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), total)
+	require.Equal(t, types.NewTxPosition(splitBlkNum, 0), atxes[0].Position)
+
+	// block range
+	atxes, total, err = cc.GetAddressTxes(txn, a.Address(), AddressTxFilter{
+		FromBlockNumber: depositBlkNum,
+		ToBlockNumber:   splitBlkNum,
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), total)
+	require.Equal(t, types.NewTxPosition(splitBlkNum, 0), atxes[0].Position)
+
+	// the total is counted before pagination
+	atxes, total, err = cc.GetAddressTxes(txn, a.Address(), AddressTxFilter{Offset: 1, Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), total)
+	require.Len(t, atxes, 1)
+	require.Equal(t, types.NewTxPosition(splitBlkNum, 0), atxes[0].Position)
+
+	atxes, total, err = cc.GetAddressTxes(txn, a.Address(), AddressTxFilter{Offset: 3})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), total)
+	require.Empty(t, atxes)
+
+	// b received 60 and sent it back
+	atxes, total, err = cc.GetAddressTxes(txn, b.Address(), AddressTxFilter{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), total)
+	require.True(t, atxes[0].Outgoing)
+	require.Equal(t, big.NewInt(60), atxes[0].Sent)
+	require.True(t, atxes[1].Incoming)
+	require.Equal(t, big.NewInt(60), atxes[1].Received)
+}
@@ -0,0 +1,277 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+)
+
+var (
+	// ErrKeyNotFound is returned by Txn.Get when the key is not set.
+	ErrKeyNotFound = errors.New("key is not found")
+)
+
+// KV is a key-value store that ChildChain is stored in.
+type KV interface {
+	// NewTransaction starts a txn, which is read-write if update is true.
+	// The txn has to be ended with Commit or Discard.
+	NewTransaction(update bool) Txn
+	Close() error
+}
+
+// Txn is a txn of a KV, which reads from a consistent snapshot of the store.
+// A read-write txn also sees its own writes, which other txns see only once it is committed.
+// Returned keys and values are only valid until the txn ends and must not be modified.
+type Txn interface {
+	Get(key []byte) ([]byte, error)
+	Set(key, val []byte) error
+	Delete(key []byte) error
+	// NewIterator iterates the keys with prefix in key order.
+	NewIterator(prefix []byte) Iterator
+	Commit() error
+	Discard()
+}
+
+// Iterator iterates keys in key order. Key is only valid until Next is called.
+type Iterator interface {
+	Valid() bool
+	Next()
+	Key() []byte
+	Value() ([]byte, error)
+	Close()
+}
+
+// kvSnapshot is a consistent view of a store, from which a bufferedTxn reads.
+type kvSnapshot interface {
+	get(key []byte) ([]byte, error)
+	newIterator(prefix []byte) Iterator
+	release()
+}
+
+// kvWrite is a write buffered in a bufferedTxn.
+type kvWrite struct {
+	key       []byte
+	val       []byte
+	isDeleted bool
+}
+
+// bufferedTxn reads from a snapshot and buffers writes until it is committed.
+// It is shared by the stores that have no txns of their own.
+// Read-write txns of the same store hold its write lock until they end,
+// so that they never conflict with each other.
+type bufferedTxn struct {
+	snapshot kvSnapshot
+	err      error // an error in starting the txn, returned by every operation
+	update   bool
+	writes   map[string]*kvWrite
+	commitFn func(writes []*kvWrite) error
+	unlockFn func()
+	isDone   bool
+}
+
+func newBufferedTxn(snapshot kvSnapshot, update bool, commitFn func(writes []*kvWrite) error, unlockFn func()) *bufferedTxn {
+	return &bufferedTxn{
+		snapshot: snapshot,
+		update:   update,
+		writes:   map[string]*kvWrite{},
+		commitFn: commitFn,
+		unlockFn: unlockFn,
+	}
+}
+
+func newErrorTxn(err error, unlockFn func()) *bufferedTxn {
+	return &bufferedTxn{
+		err:      err,
+		unlockFn: unlockFn,
+	}
+}
+
+func (txn *bufferedTxn) check(isWrite bool) error {
+	if txn.err != nil {
+		return txn.err
+	}
+	if txn.isDone {
+		return errors.New("txn is already ended")
+	}
+	if isWrite && !txn.update {
+		return errors.New("txn is read-only")
+	}
+
+	return nil
+}
+
+func (txn *bufferedTxn) Get(key []byte) ([]byte, error) {
+	if err := txn.check(false); err != nil {
+		return nil, err
+	}
+
+	if w, ok := txn.writes[string(key)]; ok {
+		if w.isDeleted {
+			return nil, ErrKeyNotFound
+		}
+		return w.val, nil
+	}
+
+	return txn.snapshot.get(key)
+}
+
+func (txn *bufferedTxn) Set(key, val []byte) error {
+	if err := txn.check(true); err != nil {
+		return err
+	}
+
+	txn.writes[string(key)] = &kvWrite{
+		key: append([]byte{}, key...),
+		val: append([]byte{}, val...),
+	}
+
+	return nil
+}
+
+func (txn *bufferedTxn) Delete(key []byte) error {
+	if err := txn.check(true); err != nil {
+		return err
+	}
+
+	txn.writes[string(key)] = &kvWrite{
+		key:       append([]byte{}, key...),
+		isDeleted: true,
+	}
+
+	return nil
+}
+
+func (txn *bufferedTxn) NewIterator(prefix []byte) Iterator {
+	if err := txn.check(false); err != nil {
+		return &bufferedIterator{
+			base: emptyIterator{},
+		}
+	}
+
+	it := &bufferedIterator{
+		base:   txn.snapshot.newIterator(prefix),
+		writes: txn.sortedWrites(prefix),
+	}
+	it.seek()
+
+	return it
+}
+
+func (txn *bufferedTxn) sortedWrites(prefix []byte) []*kvWrite {
+	writes := []*kvWrite{}
+	for _, w := range txn.writes {
+		if bytes.HasPrefix(w.key, prefix) {
+			writes = append(writes, w)
+		}
+	}
+	sort.Slice(writes, func(i, j int) bool {
+		return bytes.Compare(writes[i].key, writes[j].key) < 0
+	})
+
+	return writes
+}
+
+// Commit applies the writes. Committing a read-only txn only ends it.
+func (txn *bufferedTxn) Commit() error {
+	if err := txn.check(false); err != nil {
+		txn.Discard()
+		return err
+	}
+	defer txn.Discard()
+
+	if !txn.update || len(txn.writes) == 0 {
+		return nil
+	}
+
+	return txn.commitFn(txn.sortedWrites(nil))
+}
+
+// Discard ends the txn. It does nothing if the txn is already ended.
+func (txn *bufferedTxn) Discard() {
+	if txn.isDone {
+		return
+	}
+	txn.isDone = true
+
+	if txn.snapshot != nil {
+		txn.snapshot.release()
+	}
+	if txn.unlockFn != nil {
+		txn.unlockFn()
+	}
+}
+
+// bufferedIterator merges the writes of a bufferedTxn into the iteration of its snapshot.
+type bufferedIterator struct {
+	base         Iterator
+	writes       []*kvWrite
+	i            int
+	isFromWrites bool // whether the current entry is a write
+}
+
+// seek moves to the first entry that is not deleted by a write.
+func (it *bufferedIterator) seek() {
+	for it.i < len(it.writes) {
+		w := it.writes[it.i]
+
+		c := 1
+		if it.base.Valid() {
+			c = bytes.Compare(it.base.Key(), w.key)
+		}
+		if c < 0 {
+			break
+		}
+		if c == 0 {
+			// the write shadows the entry in the snapshot
+			it.base.Next()
+		}
+		if !w.isDeleted {
+			it.isFromWrites = true
+			return
+		}
+		it.i++
+	}
+
+	it.isFromWrites = false
+}
+
+func (it *bufferedIterator) Valid() bool {
+	return it.isFromWrites || it.base.Valid()
+}
+
+func (it *bufferedIterator) Next() {
+	if it.isFromWrites {
+		it.i++
+	} else {
+		it.base.Next()
+	}
+	it.seek()
+}
+
+func (it *bufferedIterator) Key() []byte {
+	if it.isFromWrites {
+		return it.writes[it.i].key
+	}
+
+	return it.base.Key()
+}
+
+func (it *bufferedIterator) Value() ([]byte, error) {
+	if it.isFromWrites {
+		return it.writes[it.i].val, nil
+	}
+
+	return it.base.Value()
+}
+
+func (it *bufferedIterator) Close() {
+	it.base.Close()
+}
+
+type emptyIterator struct{}
+
+func (emptyIterator) Valid() bool            { return false }
+func (emptyIterator) Next()                  {}
+func (emptyIterator) Key() []byte            { return nil }
+func (emptyIterator) Value() ([]byte, error) { return nil, nil }
+func (emptyIterator) Close()                 {}
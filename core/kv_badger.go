@@ -0,0 +1,96 @@
+package core
+
+import "github.com/dgraph-io/badger"
+
+// BadgerKV stores a child chain in badger, whose own txns are used.
+type BadgerKV struct {
+	db *badger.DB
+}
+
+func NewBadgerKV(db *badger.DB) *BadgerKV {
+	return &BadgerKV{
+		db: db,
+	}
+}
+
+// DB returns the underlying badger, for operations that only badger supports.
+func (kv *BadgerKV) DB() *badger.DB {
+	return kv.db
+}
+
+func (kv *BadgerKV) NewTransaction(update bool) Txn {
+	return &badgerTxn{
+		txn: kv.db.NewTransaction(update),
+	}
+}
+
+func (kv *BadgerKV) Close() error {
+	return kv.db.Close()
+}
+
+type badgerTxn struct {
+	txn *badger.Txn
+}
+
+func (txn *badgerTxn) Get(key []byte) ([]byte, error) {
+	item, err := txn.txn.Get(key)
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+
+	return item.Value()
+}
+
+func (txn *badgerTxn) Set(key, val []byte) error {
+	return txn.txn.Set(key, val)
+}
+
+func (txn *badgerTxn) Delete(key []byte) error {
+	return txn.txn.Delete(key)
+}
+
+func (txn *badgerTxn) NewIterator(prefix []byte) Iterator {
+	it := txn.txn.NewIterator(badger.DefaultIteratorOptions)
+	it.Seek(prefix)
+
+	return &badgerIterator{
+		it:     it,
+		prefix: prefix,
+	}
+}
+
+func (txn *badgerTxn) Commit() error {
+	return txn.txn.Commit(nil)
+}
+
+func (txn *badgerTxn) Discard() {
+	txn.txn.Discard()
+}
+
+type badgerIterator struct {
+	it     *badger.Iterator
+	prefix []byte
+}
+
+func (it *badgerIterator) Valid() bool {
+	return it.it.ValidForPrefix(it.prefix)
+}
+
+func (it *badgerIterator) Next() {
+	it.it.Next()
+}
+
+func (it *badgerIterator) Key() []byte {
+	return it.it.Item().Key()
+}
+
+func (it *badgerIterator) Value() ([]byte, error) {
+	return it.it.Item().Value()
+}
+
+func (it *badgerIterator) Close() {
+	it.it.Close()
+}
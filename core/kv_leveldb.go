@@ -0,0 +1,114 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// LevelDBKV stores a child chain in goleveldb.
+// A txn reads from a leveldb snapshot and is committed as a batch.
+type LevelDBKV struct {
+	writeMu sync.Mutex
+	db      *leveldb.DB
+}
+
+func NewLevelDBKV(db *leveldb.DB) *LevelDBKV {
+	return &LevelDBKV{
+		db: db,
+	}
+}
+
+// DB returns the underlying leveldb, for operations that only leveldb supports.
+func (kv *LevelDBKV) DB() *leveldb.DB {
+	return kv.db
+}
+
+func (kv *LevelDBKV) NewTransaction(update bool) Txn {
+	var unlockFn func()
+	if update {
+		kv.writeMu.Lock()
+		unlockFn = kv.writeMu.Unlock
+	}
+
+	// the snapshot is taken after the lock so that it has every committed write
+	snap, err := kv.db.GetSnapshot()
+	if err != nil {
+		return newErrorTxn(err, unlockFn)
+	}
+
+	return newBufferedTxn(&levelDBSnapshot{snap}, update, kv.commit, unlockFn)
+}
+
+func (kv *LevelDBKV) commit(writes []*kvWrite) error {
+	batch := new(leveldb.Batch)
+	for _, w := range writes {
+		if w.isDeleted {
+			batch.Delete(w.key)
+		} else {
+			batch.Put(w.key, w.val)
+		}
+	}
+
+	return kv.db.Write(batch, nil)
+}
+
+func (kv *LevelDBKV) Close() error {
+	return kv.db.Close()
+}
+
+type levelDBSnapshot struct {
+	snap *leveldb.Snapshot
+}
+
+func (s *levelDBSnapshot) get(key []byte) ([]byte, error) {
+	val, err := s.snap.Get(key, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+
+	return val, nil
+}
+
+func (s *levelDBSnapshot) newIterator(prefix []byte) Iterator {
+	it := s.snap.NewIterator(util.BytesPrefix(prefix), nil)
+
+	return &levelDBIterator{
+		it:      it,
+		isValid: it.First(),
+	}
+}
+
+func (s *levelDBSnapshot) release() {
+	s.snap.Release()
+}
+
+type levelDBIterator struct {
+	it      iterator.Iterator
+	isValid bool
+}
+
+func (it *levelDBIterator) Valid() bool {
+	return it.isValid
+}
+
+func (it *levelDBIterator) Next() {
+	it.isValid = it.it.Next()
+}
+
+func (it *levelDBIterator) Key() []byte {
+	return it.it.Key()
+}
+
+func (it *levelDBIterator) Value() ([]byte, error) {
+	return it.it.Value(), it.it.Error()
+}
+
+func (it *levelDBIterator) Close() {
+	it.it.Release()
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryKV stores a child chain in memory. Every commit copies the store
+// so that open txns keep their snapshot, which suits tests and small chains.
+type MemoryKV struct {
+	writeMu sync.Mutex
+	mu      sync.RWMutex
+	data    *memorySnapshot
+}
+
+func NewMemoryKV() *MemoryKV {
+	return &MemoryKV{
+		data: &memorySnapshot{
+			entries: map[string][]byte{},
+			keys:    []string{},
+		},
+	}
+}
+
+func (kv *MemoryKV) NewTransaction(update bool) Txn {
+	var unlockFn func()
+	if update {
+		kv.writeMu.Lock()
+		unlockFn = kv.writeMu.Unlock
+	}
+
+	kv.mu.RLock()
+	data := kv.data
+	kv.mu.RUnlock()
+
+	return newBufferedTxn(data, update, kv.commit, unlockFn)
+}
+
+func (kv *MemoryKV) commit(writes []*kvWrite) error {
+	kv.mu.RLock()
+	data := kv.data
+	kv.mu.RUnlock()
+
+	entries := make(map[string][]byte, len(data.entries)+len(writes))
+	for k, v := range data.entries {
+		entries[k] = v
+	}
+
+	// writes are sorted, so the added keys are merged into the sorted keys
+	addedKeys := []string{}
+	isKeysChanged := false
+	for _, w := range writes {
+		k := string(w.key)
+		_, ok := entries[k]
+		if w.isDeleted {
+			delete(entries, k)
+			isKeysChanged = isKeysChanged || ok
+			continue
+		}
+		if !ok {
+			addedKeys = append(addedKeys, k)
+			isKeysChanged = true
+		}
+		entries[k] = w.val
+	}
+
+	keys := data.keys
+	if isKeysChanged {
+		keys = make([]string, 0, len(entries))
+		i := 0
+		for _, k := range data.keys {
+			for i < len(addedKeys) && addedKeys[i] < k {
+				keys = append(keys, addedKeys[i])
+				i++
+			}
+			if _, ok := entries[k]; ok {
+				keys = append(keys, k)
+			}
+		}
+		keys = append(keys, addedKeys[i:]...)
+	}
+
+	kv.mu.Lock()
+	kv.data = &memorySnapshot{
+		entries: entries,
+		keys:    keys,
+	}
+	kv.mu.Unlock()
+
+	return nil
+}
+
+func (kv *MemoryKV) Close() error {
+	return nil
+}
+
+// memorySnapshot is never modified once committed.
+type memorySnapshot struct {
+	entries map[string][]byte
+	keys    []string // sorted
+}
+
+func (s *memorySnapshot) get(key []byte) ([]byte, error) {
+	val, ok := s.entries[string(key)]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return val, nil
+}
+
+func (s *memorySnapshot) newIterator(prefix []byte) Iterator {
+	p := string(prefix)
+
+	return &memoryIterator{
+		snapshot: s,
+		prefix:   p,
+		i:        sort.SearchStrings(s.keys, p),
+	}
+}
+
+func (s *memorySnapshot) release() {}
+
+type memoryIterator struct {
+	snapshot *memorySnapshot
+	prefix   string
+	i        int
+}
+
+func (it *memoryIterator) Valid() bool {
+	return it.i < len(it.snapshot.keys) && strings.HasPrefix(it.snapshot.keys[it.i], it.prefix)
+}
+
+func (it *memoryIterator) Next() {
+	it.i++
+}
+
+func (it *memoryIterator) Key() []byte {
+	return []byte(it.snapshot.keys[it.i])
+}
+
+func (it *memoryIterator) Value() ([]byte, error) {
+	return it.snapshot.entries[it.snapshot.keys[it.i]], nil
+}
+
+func (it *memoryIterator) Close() {}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/dgraph-io/badger"
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func testKV(t *testing.T, kv KV) {
+	get := func(txn Txn, key string) string {
+		val, err := txn.Get([]byte(key))
+		if err == ErrKeyNotFound {
+			return ""
+		}
+		require.NoError(t, err)
+		return string(val)
+	}
+	keys := func(txn Txn, prefix string) []string {
+		ks := []string{}
+		it := txn.NewIterator([]byte(prefix))
+		defer it.Close()
+		for ; it.Valid(); it.Next() {
+			_, err := it.Value()
+			require.NoError(t, err)
+			ks = append(ks, string(it.Key()))
+		}
+		return ks
+	}
+
+	txn := kv.NewTransaction(true)
+	for _, k := range []string{"a_2", "a_1", "a_3", "b_1"} {
+		require.NoError(t, txn.Set([]byte(k), []byte("v"+k)))
+	}
+	require.NoError(t, txn.Commit())
+
+	rtxn := kv.NewTransaction(false)
+	defer rtxn.Discard()
+	require.Equal(t, "va_1", get(rtxn, "a_1"))
+	require.Equal(t, []string{"a_1", "a_2", "a_3"}, keys(rtxn, "a_"))
+
+	// a txn sees its own writes
+	txn = kv.NewTransaction(true)
+	require.NoError(t, txn.Delete([]byte("a_2")))
+	require.NoError(t, txn.Set([]byte("a_0"), []byte("va_0")))
+	require.NoError(t, txn.Set([]byte("a_3"), []byte("new")))
+	require.Equal(t, "", get(txn, "a_2"))
+	require.Equal(t, "new", get(txn, "a_3"))
+	require.Equal(t, []string{"a_0", "a_1", "a_3"}, keys(txn, "a_"))
+
+	// other txns do not until it is committed
+	require.Equal(t, "va_2", get(rtxn, "a_2"))
+	require.NoError(t, txn.Commit())
+	require.Equal(t, []string{"a_1", "a_2", "a_3"}, keys(rtxn, "a_"))
+	require.Equal(t, "va_3", get(rtxn, "a_3"))
+
+	rtxn2 := kv.NewTransaction(false)
+	defer rtxn2.Discard()
+	require.Equal(t, []string{"a_0", "a_1", "a_3"}, keys(rtxn2, "a_"))
+	require.Equal(t, "new", get(rtxn2, "a_3"))
+	require.Equal(t, []string{"b_1"}, keys(rtxn2, "b_"))
+
+	// discarded writes are dropped
+	txn = kv.NewTransaction(true)
+	require.NoError(t, txn.Set([]byte("c_1"), []byte("vc_1")))
+	txn.Discard()
+
+	rtxn3 := kv.NewTransaction(false)
+	defer rtxn3.Discard()
+	require.Equal(t, "", get(rtxn3, "c_1"))
+}
+
+func TestMemoryKV(t *testing.T) {
+	kv := NewMemoryKV()
+	defer kv.Close()
+
+	testKV(t, kv)
+}
+
+func TestBadgerKV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mmpc-badger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	opts := badger.DefaultOptions
+	opts.Dir = dir
+	opts.ValueDir = dir
+	db, err := badger.Open(opts)
+	require.NoError(t, err)
+	kv := NewBadgerKV(db)
+	defer kv.Close()
+
+	testKV(t, kv)
+}
+
+func TestLevelDBKV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mmpc-leveldb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := leveldb.OpenFile(dir, nil)
+	require.NoError(t, err)
+	kv := NewLevelDBKV(db)
+	defer kv.Close()
+
+	testKV(t, kv)
+}
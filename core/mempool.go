@@ -0,0 +1,518 @@
+package core
+
+import (
+	"container/heap"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+)
+
+// MempoolTx is a tx waiting in the mempool to be included in a block.
+// TxIndex is the index the tx was assigned in the pending block, at which other mempool txes spend its outputs.
+type MempoolTx struct {
+	Tx      *types.Tx `json:"tx"`
+	TxIndex uint64    `json:"txindex"`
+	Fee     *big.Int  `json:"fee"`
+	AddedAt uint64    `json:"added"` // unix time
+}
+
+func NewMempoolTx(tx *types.Tx, txIndex uint64, fee *big.Int, addedAt time.Time) *MempoolTx {
+	return &MempoolTx{
+		Tx:      tx,
+		TxIndex: txIndex,
+		Fee:     fee,
+		AddedAt: uint64(addedAt.Unix()),
+	}
+}
+
+func (mtx *MempoolTx) Hash() (common.Hash, error) {
+	return mtx.Tx.Hash()
+}
+
+func (mtx *MempoolTx) AddedTime() time.Time {
+	return time.Unix(int64(mtx.AddedAt), 0)
+}
+
+// IsExpired reports whether mtx was added before deadline.
+func (mtx *MempoolTx) IsExpired(deadline time.Time) bool {
+	return mtx.AddedTime().Before(deadline)
+}
+
+// IsChained reports whether mtx spends an output of another mempool tx,
+// which is the case if one of its inputs references the pending block.
+func (mtx *MempoolTx) IsChained(pendingBlkNum uint64) bool {
+	for _, txIn := range mtx.Tx.Inputs {
+		if !txIn.IsNull() && txIn.BlockNumber == pendingBlkNum {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasPriorityOver reports whether mtx should be kept in preference to other:
+// a higher fee wins, and the tx that arrived first wins a tie.
+func (mtx *MempoolTx) HasPriorityOver(other *MempoolTx) bool {
+	if c := mtx.Fee.Cmp(other.Fee); c != 0 {
+		return c > 0
+	}
+
+	return mtx.TxIndex < other.TxIndex
+}
+
+// sortMempoolTxes sorts mtxes so that every tx comes after the txes whose outputs it spends.
+// A tx can only spend outputs of txes that were already in the mempool when it arrived,
+// and tx indexes are assigned in arrival order, so tx index order is such an order.
+// It is checked anyway so that a broken mempool never produces a block
+// in which a tx spends an output that does not exist yet.
+func sortMempoolTxes(mtxes []*MempoolTx, pendingBlkNum uint64) error {
+	sort.Slice(mtxes, func(i, j int) bool {
+		return mtxes[i].TxIndex < mtxes[j].TxIndex
+	})
+
+	for i, mtx := range mtxes {
+		if i > 0 && mtxes[i-1].TxIndex == mtx.TxIndex {
+			return ErrInvalidMempoolOrder
+		}
+
+		for _, txIn := range mtx.Tx.Inputs {
+			if txIn.IsNull() || txIn.BlockNumber != pendingBlkNum {
+				continue
+			}
+			if txIn.TxIndex >= mtx.TxIndex {
+				return ErrInvalidMempoolOrder
+			}
+		}
+	}
+
+	return nil
+}
+
+// referencedMempoolTxIndexes returns the tx indexes of the mempool txes
+// whose outputs are spent by other mempool txes.
+func referencedMempoolTxIndexes(mtxes []*MempoolTx, pendingBlkNum uint64) map[uint64]bool {
+	isReferenced := map[uint64]bool{}
+	for _, mtx := range mtxes {
+		for _, txIn := range mtx.Tx.Inputs {
+			if !txIn.IsNull() && txIn.BlockNumber == pendingBlkNum {
+				isReferenced[txIn.TxIndex] = true
+			}
+		}
+	}
+
+	return isReferenced
+}
+
+// nextMempoolTxToInclude returns the index in mtxes, sorted by tx index, of the tx to be included at txIndex,
+// or -1 if there is none. A referenced tx can only be included at its own tx index,
+// and any other tx can be included once the txes whose outputs it spends are.
+func nextMempoolTxToInclude(mtxes []*MempoolTx, isReferenced map[uint64]bool, txIndex, pendingBlkNum uint64) int {
+	for i, mtx := range mtxes {
+		if isReferenced[mtx.TxIndex] && mtx.TxIndex == txIndex {
+			return i
+		}
+	}
+
+	for i, mtx := range mtxes {
+		if isReferenced[mtx.TxIndex] {
+			continue
+		}
+
+		isIncludable := true
+		for _, txIn := range mtx.Tx.Inputs {
+			if !txIn.IsNull() && txIn.BlockNumber == pendingBlkNum && txIn.TxIndex >= txIndex {
+				isIncludable = false
+				break
+			}
+		}
+		if isIncludable {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Mempool is the in-memory index of the txes in the mempool.
+// Every tx is also stored in the KV, from which the index is rebuilt on startup.
+//
+// Changes made with a txn are staged and only become visible to other txns
+// once the txn is committed through ChildChain.Commit, so that a discarded txn
+// never leaves the index out of sync with the KV.
+type Mempool struct {
+	mu        sync.RWMutex
+	entries   map[common.Hash]*mempoolEntry
+	txIndexes map[uint64]common.Hash
+	spenders  map[types.Position]common.Hash // spent txout position => spending tx hash
+	queue     mempoolQueue                   // lowest priority first
+	staged    map[Txn]*mempoolChanges
+}
+
+type mempoolEntry struct {
+	hash       common.Hash
+	mtx        *MempoolTx
+	queueIndex int
+}
+
+type mempoolChanges struct {
+	puts map[common.Hash]*MempoolTx
+	dels map[common.Hash]struct{}
+}
+
+func NewMempool() *Mempool {
+	return &Mempool{
+		entries:   map[common.Hash]*mempoolEntry{},
+		txIndexes: map[uint64]common.Hash{},
+		spenders:  map[types.Position]common.Hash{},
+		queue:     mempoolQueue{},
+		staged:    map[Txn]*mempoolChanges{},
+	}
+}
+
+// Len returns the number of committed txes in the mempool.
+func (mp *Mempool) Len() int {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	return len(mp.entries)
+}
+
+// Txes returns the committed txes in the mempool, highest priority first.
+func (mp *Mempool) Txes() []*MempoolTx {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	mtxes := make([]*MempoolTx, 0, len(mp.entries))
+	for _, entry := range mp.entries {
+		mtxes = append(mtxes, entry.mtx)
+	}
+
+	sort.Slice(mtxes, func(i, j int) bool {
+		return mtxes[i].HasPriorityOver(mtxes[j])
+	})
+
+	return mtxes
+}
+
+func (mp *Mempool) changes(txn Txn) *mempoolChanges {
+	chgs, ok := mp.staged[txn]
+	if !ok {
+		chgs = &mempoolChanges{
+			puts: map[common.Hash]*MempoolTx{},
+			dels: map[common.Hash]struct{}{},
+		}
+		mp.staged[txn] = chgs
+	}
+
+	return chgs
+}
+
+// len returns the number of txes in the mempool as seen by txn.
+func (mp *Mempool) len(txn Txn) int {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	n := len(mp.entries)
+
+	chgs, ok := mp.staged[txn]
+	if !ok {
+		return n
+	}
+
+	for txHash := range chgs.puts {
+		if _, ok := mp.entries[txHash]; !ok {
+			n++
+		}
+	}
+	for txHash := range chgs.dels {
+		if _, ok := mp.entries[txHash]; ok {
+			n--
+		}
+	}
+
+	return n
+}
+
+// get returns the tx with txHash as seen by txn, or nil.
+func (mp *Mempool) get(txn Txn, txHash common.Hash) *MempoolTx {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	return mp.getLocked(txn, txHash)
+}
+
+func (mp *Mempool) getLocked(txn Txn, txHash common.Hash) *MempoolTx {
+	if chgs, ok := mp.staged[txn]; ok {
+		if mtx, ok := chgs.puts[txHash]; ok {
+			return mtx
+		}
+		if _, ok := chgs.dels[txHash]; ok {
+			return nil
+		}
+	}
+
+	entry, ok := mp.entries[txHash]
+	if !ok {
+		return nil
+	}
+
+	return entry.mtx
+}
+
+// getTxHashByIndex returns the hash of the tx assigned txIndex as seen by txn.
+func (mp *Mempool) getTxHashByIndex(txn Txn, txIndex uint64) (common.Hash, bool) {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	if chgs, ok := mp.staged[txn]; ok {
+		for txHash, mtx := range chgs.puts {
+			if mtx.TxIndex == txIndex {
+				return txHash, true
+			}
+		}
+	}
+
+	txHash, ok := mp.txIndexes[txIndex]
+	if !ok || mp.getLocked(txn, txHash) == nil {
+		return types.NullHash, false
+	}
+
+	return txHash, true
+}
+
+// getSpenders returns the hashes of the txes spending any of txOutPoses as seen by txn.
+func (mp *Mempool) getSpenders(txn Txn, txOutPoses []types.Position) []common.Hash {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	found := map[common.Hash]struct{}{}
+	txHashes := []common.Hash{}
+	add := func(txHash common.Hash) {
+		if _, ok := found[txHash]; ok {
+			return
+		}
+		found[txHash] = struct{}{}
+		txHashes = append(txHashes, txHash)
+	}
+
+	for _, txOutPos := range txOutPoses {
+		if txHash, ok := mp.spenders[txOutPos]; ok && mp.getLocked(txn, txHash) != nil {
+			add(txHash)
+		}
+	}
+
+	if chgs, ok := mp.staged[txn]; ok {
+		for txHash, mtx := range chgs.puts {
+			for _, txOutPos := range txOutPoses {
+				if mtxSpends(mtx, txOutPos) {
+					add(txHash)
+				}
+			}
+		}
+	}
+
+	return txHashes
+}
+
+// list returns the txes in the mempool as seen by txn, in no particular order.
+func (mp *Mempool) list(txn Txn) []*MempoolTx {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	chgs, ok := mp.staged[txn]
+
+	mtxes := make([]*MempoolTx, 0, len(mp.entries))
+	for txHash, entry := range mp.entries {
+		if ok {
+			if _, ok := chgs.dels[txHash]; ok {
+				continue
+			}
+			if _, ok := chgs.puts[txHash]; ok {
+				continue
+			}
+		}
+		mtxes = append(mtxes, entry.mtx)
+	}
+	if ok {
+		for _, mtx := range chgs.puts {
+			mtxes = append(mtxes, mtx)
+		}
+	}
+
+	return mtxes
+}
+
+// lowest returns the committed tx with the lowest priority that txn has not removed.
+func (mp *Mempool) lowest(txn Txn) *MempoolTx {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	if len(mp.queue) == 0 {
+		return nil
+	}
+
+	chgs, ok := mp.staged[txn]
+	if !ok {
+		return mp.queue[0].mtx
+	}
+	if _, ok := chgs.dels[mp.queue[0].hash]; !ok {
+		return mp.queue[0].mtx
+	}
+
+	// fall back to a linear scan, which is only needed
+	// when txn already removed the lowest priority tx
+	var lowest *MempoolTx
+	for txHash, entry := range mp.entries {
+		if _, ok := chgs.dels[txHash]; ok {
+			continue
+		}
+		if lowest == nil || lowest.HasPriorityOver(entry.mtx) {
+			lowest = entry.mtx
+		}
+	}
+
+	return lowest
+}
+
+func (mp *Mempool) put(txn Txn, txHash common.Hash, mtx *MempoolTx) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	chgs := mp.changes(txn)
+	delete(chgs.dels, txHash)
+	chgs.puts[txHash] = mtx
+}
+
+func (mp *Mempool) del(txn Txn, txHash common.Hash) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	chgs := mp.changes(txn)
+	delete(chgs.puts, txHash)
+	chgs.dels[txHash] = struct{}{}
+}
+
+// commit applies the changes staged with txn.
+func (mp *Mempool) commit(txn Txn) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	chgs, ok := mp.staged[txn]
+	if !ok {
+		return
+	}
+	delete(mp.staged, txn)
+
+	for txHash := range chgs.dels {
+		mp.removeLocked(txHash)
+	}
+	for txHash, mtx := range chgs.puts {
+		mp.removeLocked(txHash)
+		mp.addLocked(txHash, mtx)
+	}
+}
+
+// discard drops the changes staged with txn.
+func (mp *Mempool) discard(txn Txn) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	delete(mp.staged, txn)
+}
+
+// load adds committed txes, used to rebuild the index from the KV.
+func (mp *Mempool) load(txHash common.Hash, mtx *MempoolTx) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	mp.removeLocked(txHash)
+	mp.addLocked(txHash, mtx)
+}
+
+func (mp *Mempool) addLocked(txHash common.Hash, mtx *MempoolTx) {
+	entry := &mempoolEntry{
+		hash: txHash,
+		mtx:  mtx,
+	}
+
+	mp.entries[txHash] = entry
+	mp.txIndexes[mtx.TxIndex] = txHash
+	for _, txIn := range mtx.Tx.Inputs {
+		if txIn.IsNull() {
+			continue
+		}
+		mp.spenders[types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)] = txHash
+	}
+	heap.Push(&mp.queue, entry)
+}
+
+func (mp *Mempool) removeLocked(txHash common.Hash) {
+	entry, ok := mp.entries[txHash]
+	if !ok {
+		return
+	}
+
+	delete(mp.entries, txHash)
+	if mp.txIndexes[entry.mtx.TxIndex] == txHash {
+		delete(mp.txIndexes, entry.mtx.TxIndex)
+	}
+	for _, txIn := range entry.mtx.Tx.Inputs {
+		if txIn.IsNull() {
+			continue
+		}
+		txOutPos := types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex)
+		if mp.spenders[txOutPos] == txHash {
+			delete(mp.spenders, txOutPos)
+		}
+	}
+	heap.Remove(&mp.queue, entry.queueIndex)
+}
+
+func mtxSpends(mtx *MempoolTx, txOutPos types.Position) bool {
+	for _, txIn := range mtx.Tx.Inputs {
+		if txIn.IsNull() {
+			continue
+		}
+		if types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex) == txOutPos {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mempoolQueue is a heap of mempool entries with the lowest priority at the top.
+type mempoolQueue []*mempoolEntry
+
+func (q mempoolQueue) Len() int {
+	return len(q)
+}
+
+func (q mempoolQueue) Less(i, j int) bool {
+	return q[j].mtx.HasPriorityOver(q[i].mtx)
+}
+
+func (q mempoolQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].queueIndex = i
+	q[j].queueIndex = j
+}
+
+func (q *mempoolQueue) Push(x interface{}) {
+	entry := x.(*mempoolEntry)
+	entry.queueIndex = len(*q)
+	*q = append(*q, entry)
+}
+
+func (q *mempoolQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return entry
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestDB(tb testing.TB) (KV, func()) {
+	db := NewMemoryKV()
+
+	return db, func() {
+		db.Close()
+	}
+}
+
+// fillMempool loads n synthetic txes into the mempool index with tx indexes from 0 to n-1.
+func fillMempool(mp *Mempool, n int) {
+	for i := 0; i < n; i++ {
+		mtx := NewMempoolTx(types.NewTx(), uint64(i), big.NewInt(int64(i%100)), time.Now())
+		mp.load(common.BigToHash(big.NewInt(int64(i+1))), mtx)
+	}
+}
+
+func TestMempool_Stage(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	mp := NewMempool()
+	fillMempool(mp, 3)
+
+	txHash := common.BigToHash(big.NewInt(100))
+	mtx := NewMempoolTx(types.NewTx(), 3, big.NewInt(1), time.Now())
+	removedTxHash := common.BigToHash(big.NewInt(1))
+
+	// discarded changes are dropped
+	txn := db.NewTransaction(true)
+	mp.put(txn, txHash, mtx)
+	mp.del(txn, removedTxHash)
+	require.Equal(t, 3, mp.len(txn))
+	require.NotNil(t, mp.get(txn, txHash))
+	require.Nil(t, mp.get(txn, removedTxHash))
+	mp.discard(txn)
+	txn.Discard()
+	require.Equal(t, 3, mp.Len())
+
+	// staged changes are invisible to other txns until committed
+	txn1 := db.NewTransaction(true)
+	txn2 := db.NewTransaction(false)
+	mp.put(txn1, txHash, mtx)
+	mp.del(txn1, removedTxHash)
+	require.Nil(t, mp.get(txn2, txHash))
+	require.NotNil(t, mp.get(txn2, removedTxHash))
+	mp.commit(txn1)
+	txn1.Discard()
+	require.NotNil(t, mp.get(txn2, txHash))
+	require.Nil(t, mp.get(txn2, removedTxHash))
+	txn2.Discard()
+
+	require.Equal(t, 3, mp.Len())
+	txHashByIndex, ok := mp.getTxHashByIndex(nil, 3)
+	require.True(t, ok)
+	require.Equal(t, txHash, txHashByIndex)
+	_, ok = mp.getTxHashByIndex(nil, 0)
+	require.False(t, ok)
+}
+
+func TestMempool_Lowest(t *testing.T) {
+	mp := NewMempool()
+	fillMempool(mp, 10)
+
+	// fee 0 at tx index 0
+	lowest := mp.lowest(nil)
+	require.Equal(t, uint64(0), lowest.TxIndex)
+
+	txes := mp.Txes()
+	require.Len(t, txes, 10)
+	require.Equal(t, uint64(9), txes[0].TxIndex)
+	require.Equal(t, uint64(0), txes[9].TxIndex)
+}
+
+func benchmarkMempoolPut(b *testing.B, pending int) {
+	db, closeDB := openTestDB(b)
+	defer closeDB()
+
+	mp := NewMempool()
+	fillMempool(mp, pending)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		txn := db.NewTransaction(true)
+
+		txHash := common.BigToHash(big.NewInt(int64(pending + i + 1)))
+		if mp.len(txn) >= pending+i+1 {
+			b.Fatal("unexpected mempool size")
+		}
+		mp.put(txn, txHash, NewMempoolTx(types.NewTx(), uint64(pending+i), big.NewInt(1), time.Now()))
+		mp.commit(txn)
+
+		txn.Discard()
+	}
+}
+
+func BenchmarkMempool_Put10k(b *testing.B) {
+	benchmarkMempoolPut(b, 10000)
+}
+
+func BenchmarkMempool_Put100k(b *testing.B) {
+	benchmarkMempoolPut(b, 100000)
+}
+
+// benchmarkAddTxToMempool measures submitting a tx while pending txes wait in the mempool.
+// Every submission is discarded, so that the mempool stays at the same size.
+func benchmarkAddTxToMempool(b *testing.B, pending int) {
+	db, closeDB := openTestDB(b)
+	defer closeDB()
+
+	privKey, err := crypto.GenerateKey()
+	require.NoError(b, err)
+	a := types.NewAccount(privKey)
+
+	txn := db.NewTransaction(true)
+	cc, err := NewChildChain(txn)
+	require.NoError(b, err)
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(1), a)
+	require.NoError(b, err)
+	require.NoError(b, cc.setNextMempoolTxIndex(txn, uint64(pending)))
+	require.NoError(b, cc.Commit(txn))
+
+	fillMempool(cc.mempool, pending)
+
+	tx := types.NewTx()
+	require.NoError(b, tx.SetInput(0, types.NewTxIn(depositBlkNum, 0, 0)))
+	require.NoError(b, tx.SetOutput(0, types.NewTxOut(a.Address(), big.NewInt(1))))
+	require.NoError(b, tx.Sign(0, a))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		txn := db.NewTransaction(true)
+		if err := cc.AddTxToMempool(txn, tx); err != nil {
+			b.Fatal(err)
+		}
+		cc.Discard(txn)
+	}
+}
+
+func BenchmarkChildChain_AddTxToMempool10k(b *testing.B) {
+	benchmarkAddTxToMempool(b, 10000)
+}
+
+// the mempool of a child chain cannot hold 100k txes since a block cannot,
+// so this is the closest to the 100k case of the mempool benchmarks.
+func BenchmarkChildChain_AddTxToMempoolFull(b *testing.B) {
+	benchmarkAddTxToMempool(b, MempoolSize-1)
+}
@@ -0,0 +1,282 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+const (
+	MaxPruneBlocksNum = 100 // blocks gone through by a call of Prune
+)
+
+// PrunedTx is what is kept of a pruned tx. The leaf hash rebuilds the tx Merkle tree of its block,
+// and the tx without its signatures still has the inputs and confirmation signatures
+// to challenge exits of the txouts it spent and the outputs to look up their tokens.
+type PrunedTx struct {
+	LeafHash common.Hash
+	Tx       *types.Tx
+}
+
+func NewPrunedTx(tx *types.Tx) (*PrunedTx, error) {
+	leafHash, err := tx.MerkleLeafHash()
+	if err != nil {
+		return nil, err
+	}
+
+	prunedTx := types.NewTx()
+	for i, txIn := range tx.Inputs {
+		prunedTx.Inputs[i] = &types.TxIn{
+			TxInCore:              txIn.TxInCore,
+			Signature:             types.NullSignature,
+			ConfirmationSignature: txIn.ConfirmationSignature,
+		}
+	}
+	prunedTx.Outputs = tx.Outputs
+
+	return &PrunedTx{
+		LeafHash: leafHash,
+		Tx:       prunedTx,
+	}, nil
+}
+
+// Prune prunes the txes whose txouts were all spent in blocks at least depth blocks before the current block.
+// It goes through the blocks spending them from where the last call stopped,
+// up to MaxPruneBlocksNum blocks and no further than lastBlkNum, and returns the number of pruned txes.
+// Since a tx is spent in its own block or a later one, the txes of the blocks after lastBlkNum are kept.
+func (cc *ChildChain) Prune(txn Txn, depth, lastBlkNum uint64) (int, error) {
+	currentBlkNum, err := cc.getCurrentBlockNumber(txn)
+	if err != nil {
+		return 0, err
+	}
+
+	blkNum, err := cc.getNextPruneBlockNumber(txn)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for i := 0; i < MaxPruneBlocksNum && blkNum+depth < currentBlkNum && blkNum <= lastBlkNum; i++ {
+		for txIndex := uint64(0); ; txIndex++ {
+			tx, err := cc.getStoredTx(txn, blkNum, txIndex)
+			if err != nil {
+				if err == ErrKeyNotFound {
+					break
+				} else {
+					return 0, err
+				}
+			}
+
+			for _, txIn := range tx.Inputs {
+				if txIn.IsNull() {
+					continue
+				}
+
+				ok, err := cc.pruneTx(txn, txIn.BlockNumber, txIn.TxIndex, currentBlkNum-depth)
+				if err != nil {
+					return 0, err
+				}
+				if ok {
+					n++
+				}
+			}
+		}
+
+		blkNum++
+	}
+
+	if err := cc.setNextPruneBlockNumber(txn, blkNum); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// pruneTx prunes the tx if all of its txouts were spent in blocks before limitBlkNum.
+func (cc *ChildChain) pruneTx(txn Txn, blkNum, txIndex, limitBlkNum uint64) (bool, error) {
+	tx, err := cc.getTx(txn, blkNum, txIndex)
+	if err != nil {
+		if err == ErrKeyNotFound { // already pruned
+			return false, nil
+		} else {
+			return false, err
+		}
+	}
+
+	for i, txOut := range tx.Outputs {
+		if txOut.OwnerAddress == types.NullAddress {
+			continue
+		}
+
+		token, err := cc.getToken(txn, txOut.OwnerAddress, types.NewTxOutPosition(blkNum, txIndex, uint64(i)))
+		if err != nil {
+			return false, err
+		}
+		if !token.IsSpent() {
+			return false, nil
+		}
+		if spendingBlkNum, _, _ := types.ParseTxInPosition(token.SpendingTxInPos); spendingBlkNum >= limitBlkNum {
+			return false, nil
+		}
+	}
+
+	prunedTx, err := NewPrunedTx(tx)
+	if err != nil {
+		return false, err
+	}
+	if err := cc.setPrunedTx(txn, blkNum, txIndex, prunedTx); err != nil {
+		return false, err
+	}
+	if err := txn.Delete(cc.txKey(blkNum, txIndex)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetBlockRoot returns the root of the tx Merkle tree of the block, which may be pruned.
+func (cc *ChildChain) GetBlockRoot(txn Txn, blkNum uint64) (common.Hash, error) {
+	if _, err := cc.getBlockHeader(txn, blkNum); err != nil {
+		if err == ErrKeyNotFound {
+			return types.NullHash, ErrBlockNotFound
+		} else {
+			return types.NullHash, err
+		}
+	}
+
+	leafHashes, err := cc.getBlockLeafHashes(txn, blkNum)
+	if err != nil {
+		return types.NullHash, err
+	}
+
+	return types.TxMerkleRoot(leafHashes)
+}
+
+// getBlockLeafHashes returns the leaf hashes of the tx Merkle tree of the block,
+// taking them from the pruned txes for the txes that are pruned.
+func (cc *ChildChain) getBlockLeafHashes(txn Txn, blkNum uint64) ([]common.Hash, error) {
+	leafHashes := []common.Hash{}
+
+	for txIndex := uint64(0); ; txIndex++ {
+		tx, err := cc.getTx(txn, blkNum, txIndex)
+		if err == nil {
+			leafHash, err := tx.MerkleLeafHash()
+			if err != nil {
+				return nil, err
+			}
+			leafHashes = append(leafHashes, leafHash)
+			continue
+		}
+		if err != ErrKeyNotFound {
+			return nil, err
+		}
+
+		prunedTx, err := cc.getPrunedTx(txn, blkNum, txIndex)
+		if err != nil {
+			if err == ErrKeyNotFound {
+				break
+			} else {
+				return nil, err
+			}
+		}
+		leafHashes = append(leafHashes, prunedTx.LeafHash)
+	}
+
+	return leafHashes, nil
+}
+
+// txNotFoundError tells whether the tx that is not stored was pruned or never existed.
+func (cc *ChildChain) txNotFoundError(txn Txn, blkNum, txIndex uint64) error {
+	if _, err := cc.getPrunedTx(txn, blkNum, txIndex); err != nil {
+		if err == ErrKeyNotFound {
+			return ErrTxNotFound
+		} else {
+			return err
+		}
+	}
+
+	return ErrTxPruned
+}
+
+// getStoredTx returns the tx, or the tx kept in its pruned tx without signatures if it is pruned.
+// It must only be used where the signatures are not needed.
+func (cc *ChildChain) getStoredTx(txn Txn, blkNum, txIndex uint64) (*types.Tx, error) {
+	tx, err := cc.getTx(txn, blkNum, txIndex)
+	if err != ErrKeyNotFound {
+		return tx, err
+	}
+
+	prunedTx, err := cc.getPrunedTx(txn, blkNum, txIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	return prunedTx.Tx, nil
+}
+
+// setStoredTx updates the tx, which is kept in its pruned tx if it is pruned.
+func (cc *ChildChain) setStoredTx(txn Txn, blkNum, txIndex uint64, tx *types.Tx) error {
+	prunedTx, err := cc.getPrunedTx(txn, blkNum, txIndex)
+	if err != nil {
+		if err == ErrKeyNotFound {
+			return cc.setTx(txn, blkNum, txIndex, tx)
+		} else {
+			return err
+		}
+	}
+
+	prunedTx.Tx = tx
+
+	return cc.setPrunedTx(txn, blkNum, txIndex, prunedTx)
+}
+
+func (cc *ChildChain) prunedTxKey(blkNum, txIndex uint64) []byte {
+	return []byte(fmt.Sprintf("%s_%d_%d", prunedTxKeyPrefix, blkNum, txIndex))
+}
+
+func (cc *ChildChain) setPrunedTx(txn Txn, blkNum, txIndex uint64, prunedTx *PrunedTx) error {
+	prunedTxBytes, err := rlp.EncodeToBytes(prunedTx)
+	if err != nil {
+		return err
+	}
+
+	return txn.Set(cc.prunedTxKey(blkNum, txIndex), prunedTxBytes)
+}
+
+func (cc *ChildChain) getPrunedTx(txn Txn, blkNum, txIndex uint64) (*PrunedTx, error) {
+	prunedTxBytes, err := txn.Get(cc.prunedTxKey(blkNum, txIndex))
+	if err != nil {
+		return nil, err
+	}
+
+	var prunedTx PrunedTx
+	if err := rlp.DecodeBytes(prunedTxBytes, &prunedTx); err != nil {
+		return nil, err
+	}
+
+	return &prunedTx, nil
+}
+
+func (cc *ChildChain) nextPruneBlockNumberKey() []byte {
+	return []byte(nextPruneBlockNumberKey)
+}
+
+func (cc *ChildChain) getNextPruneBlockNumber(txn Txn) (uint64, error) {
+	blkNumBytes, err := txn.Get(cc.nextPruneBlockNumberKey())
+	if err != nil {
+		if err == ErrKeyNotFound {
+			return FirstBlockNumber, nil
+		} else {
+			return 0, err
+		}
+	}
+
+	return utils.BytesToUint64(blkNumBytes)
+}
+
+func (cc *ChildChain) setNextPruneBlockNumber(txn Txn, blkNum uint64) error {
+	return txn.Set(cc.nextPruneBlockNumberKey(), utils.Uint64ToBytes(blkNum))
+}
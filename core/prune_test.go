@@ -0,0 +1,204 @@
+package core
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAccounts(t *testing.T) (*types.Account, *types.Account) {
+	privKeyA, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	privKeyB, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	return types.NewAccount(privKeyA), types.NewAccount(privKeyB)
+}
+
+// newTestChildChain opens a read-write txn on db and a child chain with it.
+func newTestChildChain(t *testing.T, db KV) (Txn, *ChildChain) {
+	txn := db.NewTransaction(true)
+	cc, err := NewChildChain(txn)
+	require.NoError(t, err)
+
+	return txn, cc
+}
+
+func newTestSpendingTx(t *testing.T, txIn *types.TxIn, signer *types.Account, txOuts ...*types.TxOut) *types.Tx {
+	tx := types.NewTx()
+	require.NoError(t, tx.SetInput(0, txIn))
+	for i, txOut := range txOuts {
+		require.NoError(t, tx.SetOutput(uint64(i), txOut))
+	}
+	require.NoError(t, tx.Sign(0, signer))
+	return tx
+}
+
+// newTestSplitTx spends the deposit of 100 of a in the deposit block, sending 60 to b and 40 back to a.
+func newTestSplitTx(t *testing.T, depositBlkNum uint64, a, b *types.Account) *types.Tx {
+	return newTestSpendingTx(t, types.NewTxIn(depositBlkNum, 0, 0), a,
+		types.NewTxOut(b.Address(), big.NewInt(60)),
+		types.NewTxOut(a.Address(), big.NewInt(40)),
+	)
+}
+
+// addTestBlock adds txes to the mempool and includes them in a block signed by signer.
+func addTestBlock(t *testing.T, txn Txn, cc *ChildChain, signer *types.Account, txes ...*types.Tx) uint64 {
+	for _, tx := range txes {
+		require.NoError(t, cc.AddTxToMempool(txn, tx))
+	}
+
+	blkNum, err := cc.AddBlock(txn, signer)
+	require.NoError(t, err)
+
+	return blkNum
+}
+
+// addTestInvalidBlock adds a block of txes without validating them, as a buggy or malicious operator would.
+func addTestInvalidBlock(t *testing.T, txn Txn, cc *ChildChain, txes ...*types.Tx) *types.Block {
+	blkNum, err := cc.GetCurrentBlockNumber(txn)
+	require.NoError(t, err)
+
+	blk, err := types.NewBlock(txes, blkNum)
+	require.NoError(t, err)
+	require.NoError(t, cc.addBlock(txn, blk))
+	_, err = cc.incrementCurrentBlockNumber(txn)
+	require.NoError(t, err)
+
+	return blk
+}
+
+func TestChildChain_Prune(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	// blocks 1 and 2: deposits
+	depositBlkNumA, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	depositBlkNumB, _, err := cc.AddDepositBlock(txn, b.Address(), big.NewInt(50), a)
+	require.NoError(t, err)
+
+	// block 3: both deposits are spent, and a txout of the first tx is left unspent
+	tx1 := newTestSplitTx(t, depositBlkNumA, a, b)
+	tx2 := newTestSpendingTx(t, types.NewTxIn(depositBlkNumB, 0, 0), b, types.NewTxOut(a.Address(), big.NewInt(50)))
+	blkNum := addTestBlock(t, txn, cc, a, tx1, tx2)
+
+	blk, err := cc.GetBlock(txn, blkNum)
+	require.NoError(t, err)
+	rootHash, err := blk.Root()
+	require.NoError(t, err)
+	tree, err := blk.MerkleTree()
+	require.NoError(t, err)
+	proof, err := tree.CreateMembershipProof(1)
+	require.NoError(t, err)
+
+	// block 4: the first txout of tx1 is spent and the spend is confirmed
+	tx3 := newTestSpendingTx(t, types.NewTxIn(blkNum, 0, 0), b, types.NewTxOut(a.Address(), big.NewInt(60)))
+	spendingBlkNum := addTestBlock(t, txn, cc, a, tx3)
+	require.NoError(t, tx3.Confirm(0, b))
+	require.NoError(t, cc.ConfirmTx(txn, types.NewTxInPosition(spendingBlkNum, 0, 0), tx3.GetInput(0).ConfirmationSignature))
+
+	// the deposits were spent in block 3, which is 1 block before the current block
+	n, err := cc.Prune(txn, 1, math.MaxUint64)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	_, err = cc.GetTx(txn, types.NewTxPosition(depositBlkNumA, 0))
+	require.Equal(t, ErrTxPruned, err)
+	_, err = cc.GetBlock(txn, depositBlkNumA)
+	require.Equal(t, ErrBlockPruned, err)
+	_, err = cc.GetTx(txn, types.NewTxPosition(spendingBlkNum+1, 0))
+	require.Equal(t, ErrTxNotFound, err)
+
+	// a pruned txout is still known to be spent
+	tx := newTestSpendingTx(t, types.NewTxIn(depositBlkNumA, 0, 0), a, types.NewTxOut(a.Address(), big.NewInt(100)))
+	res, err := cc.CheckTx(txn, tx)
+	require.NoError(t, err)
+	require.Equal(t, ErrTxOutAlreadySpent, res.Errors[0].(*TxInError).Cause)
+
+	// block 5: the second txout of tx1 is spent
+	tx4 := newTestSpendingTx(t, types.NewTxIn(blkNum, 0, 1), a, types.NewTxOut(b.Address(), big.NewInt(40)))
+	addTestBlock(t, txn, cc, a, tx4)
+
+	// tx1 is kept until both of its txouts were spent long enough ago
+	n, err = cc.Prune(txn, 1, math.MaxUint64)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+	_, _, err = cc.AddDepositBlock(txn, b.Address(), big.NewInt(1), a)
+	require.NoError(t, err)
+	n, err = cc.Prune(txn, 1, math.MaxUint64)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	// block 3 still has its root and the proof of the tx that is left
+	_, err = cc.GetBlock(txn, blkNum)
+	require.Equal(t, ErrBlockPruned, err)
+	_, err = cc.GetTx(txn, types.NewTxPosition(blkNum, 0))
+	require.Equal(t, ErrTxPruned, err)
+	prunedRootHash, err := cc.GetBlockRoot(txn, blkNum)
+	require.NoError(t, err)
+	require.Equal(t, rootHash, prunedRootHash)
+	prunedProof, err := cc.GetTxProof(txn, types.NewTxPosition(blkNum, 1))
+	require.NoError(t, err)
+	require.Equal(t, proof, prunedProof)
+
+	// exits of the pruned txouts can still be challenged
+	spender, err := cc.GetSpender(txn, types.NewTxOutPosition(blkNum, 0, 0))
+	require.NoError(t, err)
+	require.Equal(t, types.NewTxInPosition(spendingBlkNum, 0, 0), spender.TxInPosition)
+	require.Equal(t, tx3.GetInput(0).ConfirmationSignature, spender.ConfirmationSignature)
+	spenderTxHash, err := spender.Tx.Hash()
+	require.NoError(t, err)
+	tx3Hash, err := tx3.Hash()
+	require.NoError(t, err)
+	require.Equal(t, tx3Hash, spenderTxHash)
+
+	spender, err = cc.GetSpender(txn, types.NewTxOutPosition(depositBlkNumA, 0, 0))
+	require.NoError(t, err)
+	spenderTxHash, err = spender.Tx.Hash()
+	require.NoError(t, err)
+	tx1Hash, err := tx1.Hash()
+	require.NoError(t, err)
+	require.Equal(t, tx1Hash, spenderTxHash)
+}
+
+func TestChildChain_Prune_LastBlockNumber(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	// the deposit is spent in the next block, which is followed by 2 more blocks
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	blkNum := addTestBlock(t, txn, cc, a, newTestSplitTx(t, depositBlkNum, a, b))
+	for i := 0; i < 2; i++ {
+		_, _, err := cc.AddDepositBlock(txn, b.Address(), big.NewInt(1), a)
+		require.NoError(t, err)
+	}
+
+	// the block spending the deposit is after the last archived block, so it is not gone through yet
+	n, err := cc.Prune(txn, 1, depositBlkNum)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+	_, err = cc.GetBlock(txn, depositBlkNum)
+	require.NoError(t, err)
+
+	n, err = cc.Prune(txn, 1, blkNum)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	_, err = cc.GetBlock(txn, depositBlkNum)
+	require.Equal(t, ErrBlockPruned, err)
+}
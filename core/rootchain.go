@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -19,12 +21,18 @@ import (
 
 const (
 	DefaultExitBondAmount = 123456789
+
+	logsBlockRange = 5000 // the number of root chain blocks whose logs are fetched at once
 )
 
+// RootChainConfig configures the connection to the root chain contract.
+// DeployedBlockNumber is the number of the root chain block in which the contract was deployed,
+// from which its logs are searched.
 type RootChainConfig struct {
-	RPC        string `json:"rpc"`
-	WS         string `json:"ws"`
-	AddressStr string `json:"address"`
+	RPC                 string `json:"rpc"`
+	WS                  string `json:"ws"`
+	AddressStr          string `json:"address"`
+	DeployedBlockNumber uint64 `json:"deployed"`
 }
 
 func (conf RootChainConfig) Address() (common.Address, error) {
@@ -42,11 +50,17 @@ type RootChain struct {
 	rpcClient *ethclient.Client
 	wsClient  *rpc.Client
 	contract  *bind.BoundContract
+
+	commitmentsMu         sync.Mutex
+	commitments           map[uint64]uint64 // root chain block numbers of the commitments by plasma block number
+	commitmentsNextBlkNum uint64            // the root chain block from which commitments are yet to be scanned
 }
 
 func NewRootChain(conf RootChainConfig) (*RootChain, error) {
 	rc := &RootChain{
-		config: conf,
+		config:                conf,
+		commitments:           map[uint64]uint64{},
+		commitmentsNextBlkNum: conf.DeployedBlockNumber,
 	}
 
 	if err := rc.initAddress(); err != nil {
@@ -112,6 +126,15 @@ func (rc *RootChain) initContract() {
 	)
 }
 
+func (rc *RootChain) Operator() (common.Address, error) {
+	addr := new(common.Address)
+	if err := rc.contract.Call(nil, addr, "operator"); err != nil {
+		return types.NullAddress, err
+	}
+
+	return *addr, nil
+}
+
 func (rc *RootChain) CurrentPlasmaBlockNumber() (uint64, error) {
 	blkNum := new(*big.Int)
 	if err := rc.contract.Call(nil, blkNum, "currentPlasmaBlockNumber"); err != nil {
@@ -121,6 +144,79 @@ func (rc *RootChain) CurrentPlasmaBlockNumber() (uint64, error) {
 	return (*blkNum).Uint64(), nil
 }
 
+func (rc *RootChain) PlasmaBlocks(blkNum uint64) (types.PlasmaBlock, error) {
+	pb := new(types.PlasmaBlock)
+	if err := rc.contract.Call(nil, pb, "plasmaBlocks", new(big.Int).SetUint64(blkNum)); err != nil {
+		return types.PlasmaBlock{}, err
+	}
+
+	return *pb, nil
+}
+
+// BlockNumber returns the number of the latest root chain block.
+func (rc *RootChain) BlockNumber(ctx context.Context) (uint64, error) {
+	header, err := rc.rpcClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return header.Number.Uint64(), nil
+}
+
+// PlasmaBlockCommitmentBlockNumber returns the number of the root chain block
+// in which the root of the plasma block was committed, either by the operator or by a deposit.
+// The commitments are cached, so that only the root chain blocks mined since the last call are scanned.
+func (rc *RootChain) PlasmaBlockCommitmentBlockNumber(ctx context.Context, blkNum uint64) (uint64, error) {
+	rc.commitmentsMu.Lock()
+	defer rc.commitmentsMu.Unlock()
+
+	if rootBlkNum, ok := rc.commitments[blkNum]; ok {
+		return rootBlkNum, nil
+	}
+
+	latestBlkNum, err := rc.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := rc.iterateLogs(ctx, rc.commitmentsNextBlkNum, latestBlkNum, []string{"PlasmaBlockRootCommitted", "DepositCreated"}, func(log gethtypes.Log) (bool, error) {
+		var logBlkNum *big.Int
+		switch log.Topics[0] {
+		case rc.abi.Events["PlasmaBlockRootCommitted"].Id():
+			event := new(RootChainPlasmaBlockRootCommitted)
+			if err := rc.contract.UnpackLog(event, "PlasmaBlockRootCommitted", log); err != nil {
+				return false, err
+			}
+			logBlkNum = event.BlockNumber
+		default:
+			event := new(RootChainDepositCreated)
+			if err := rc.contract.UnpackLog(event, "DepositCreated", log); err != nil {
+				return false, err
+			}
+			logBlkNum = event.DepositBlock
+		}
+
+		rc.commitments[logBlkNum.Uint64()] = log.BlockNumber
+		return true, nil
+	}); err != nil {
+		return 0, err
+	}
+	if latestBlkNum >= rc.commitmentsNextBlkNum {
+		rc.commitmentsNextBlkNum = latestBlkNum + 1
+	}
+
+	if rootBlkNum, ok := rc.commitments[blkNum]; ok {
+		return rootBlkNum, nil
+	}
+
+	return 0, ErrBlockNotCommitted
+}
+
+// Balance returns the ETH balance of the root chain contract.
+func (rc *RootChain) Balance(ctx context.Context) (*big.Int, error) {
+	return rc.rpcClient.BalanceAt(ctx, rc.address, nil)
+}
+
 func (rc *RootChain) PlasmaExits(txOutPos types.Position) (types.Exit, error) {
 	exit := new(types.Exit)
 	if err := rc.contract.Call(nil, exit, "plasmaExits", new(big.Int).SetUint64(txOutPos.Uint64())); err != nil {
@@ -141,34 +237,35 @@ func (rc *RootChain) Deposit(a *types.Account, amount *big.Int) (*gethtypes.Tran
 	return rc.contract.Transact(opts, "deposit")
 }
 
-func (rc *RootChain) StartExit(a *types.Account, txOutPos types.Position, tx *types.Tx, txProofBytes []byte) (*gethtypes.Transaction, error) {
-	blkNum, txIndex, outIndex := types.ParseTxOutPosition(txOutPos)
-
-	encodedTxBytes, err := tx.Encode()
-	if err != nil {
+func (rc *RootChain) ExitBond() (*big.Int, error) {
+	bond := new(*big.Int)
+	if err := rc.contract.Call(nil, bond, "EXIT_BOND"); err != nil {
 		return nil, err
 	}
 
-	sigsBytes, err := tx.SignaturesBytes()
-	if err != nil {
-		return nil, err
-	}
+	return *bond, nil
+}
 
-	confSigsBytes, err := tx.ConfirmationSignaturesBytes()
+func (rc *RootChain) StartExit(a *types.Account, txOutPos types.Position, tx *types.Tx, txProofBytes []byte) (*gethtypes.Transaction, error) {
+	exitData, err := NewExitData(txOutPos, tx, txProofBytes)
 	if err != nil {
 		return nil, err
 	}
 
+	return rc.StartExitWithData(a, exitData)
+}
+
+func (rc *RootChain) StartExitWithData(a *types.Account, exitData *ExitData) (*gethtypes.Transaction, error) {
 	opts := a.TransactOpts()
-	opts.Value = big.NewInt(DefaultExitBondAmount)
+	opts.Value = exitData.Bond
 
 	return rc.contract.Transact(
 		opts,
 		"startExit",
-		new(big.Int).SetUint64(blkNum), new(big.Int).SetUint64(txIndex), new(big.Int).SetUint64(outIndex),
-		encodedTxBytes,
-		txProofBytes,
-		sigsBytes, confSigsBytes,
+		new(big.Int).SetUint64(exitData.BlockNumber), new(big.Int).SetUint64(exitData.TxIndex), new(big.Int).SetUint64(exitData.OutputIndex),
+		[]byte(exitData.EncodedTx),
+		[]byte(exitData.TxProof),
+		[]byte(exitData.Signatures), []byte(exitData.ConfirmationSignatures),
 	)
 }
 
@@ -208,6 +305,27 @@ type RootChainDepositCreated struct {
 	Raw          gethtypes.Log
 }
 
+// FilterDepositCreated returns all DepositCreated events emitted so far, oldest first.
+func (rc *RootChain) FilterDepositCreated(ctx context.Context) ([]*RootChainDepositCreated, error) {
+	logs, _, err := rc.filterLogs(ctx, rc.config.DeployedBlockNumber, "DepositCreated")
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*RootChainDepositCreated, len(logs))
+	for i, log := range logs {
+		event := new(RootChainDepositCreated)
+		if err := rc.contract.UnpackLog(event, "DepositCreated", log); err != nil {
+			return nil, err
+		}
+		event.Raw = log
+
+		events[i] = event
+	}
+
+	return events, nil
+}
+
 func (rc *RootChain) WatchDepositCreated(ctx context.Context, sink chan<- *RootChainDepositCreated) (event.Subscription, error) {
 	logs := make(chan gethtypes.Log)
 	arg := map[string]interface{}{
@@ -253,6 +371,12 @@ func (rc *RootChain) WatchDepositCreated(ctx context.Context, sink chan<- *RootC
 	}), nil
 }
 
+type RootChainPlasmaBlockRootCommitted struct {
+	BlockNumber *big.Int
+	Root        [32]byte
+	Raw         gethtypes.Log
+}
+
 type RootChainExitStarted struct {
 	Owner        common.Address
 	UtxoPosition *big.Int
@@ -260,6 +384,34 @@ type RootChainExitStarted struct {
 	Raw          gethtypes.Log
 }
 
+// FilterExitStarted returns all ExitStarted events emitted so far, oldest first.
+func (rc *RootChain) FilterExitStarted(ctx context.Context) ([]*RootChainExitStarted, error) {
+	events, _, err := rc.FilterExitStartedFrom(ctx, rc.config.DeployedBlockNumber)
+	return events, err
+}
+
+// FilterExitStartedFrom returns the ExitStarted events emitted since the root chain block at fromBlkNum, oldest first,
+// and the number of the latest root chain block, up to which the events are returned.
+func (rc *RootChain) FilterExitStartedFrom(ctx context.Context, fromBlkNum uint64) ([]*RootChainExitStarted, uint64, error) {
+	logs, latestBlkNum, err := rc.filterLogs(ctx, fromBlkNum, "ExitStarted")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	events := make([]*RootChainExitStarted, len(logs))
+	for i, log := range logs {
+		event := new(RootChainExitStarted)
+		if err := rc.contract.UnpackLog(event, "ExitStarted", log); err != nil {
+			return nil, 0, err
+		}
+		event.Raw = log
+
+		events[i] = event
+	}
+
+	return events, latestBlkNum, nil
+}
+
 func (rc *RootChain) WatchExitStarted(ctx context.Context, sink chan<- *RootChainExitStarted) (event.Subscription, error) {
 	logs := make(chan gethtypes.Log)
 	arg := map[string]interface{}{
@@ -305,6 +457,68 @@ func (rc *RootChain) WatchExitStarted(ctx context.Context, sink chan<- *RootChai
 	}), nil
 }
 
+// filterLogs returns the logs of the event emitted since the root chain block at fromBlkNum
+// and the number of the latest root chain block, up to which the logs are returned.
+func (rc *RootChain) filterLogs(ctx context.Context, fromBlkNum uint64, eventName string) ([]gethtypes.Log, uint64, error) {
+	latestBlkNum, err := rc.BlockNumber(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if fromBlkNum < rc.config.DeployedBlockNumber {
+		fromBlkNum = rc.config.DeployedBlockNumber
+	}
+
+	logs := []gethtypes.Log{}
+	if err := rc.iterateLogs(ctx, fromBlkNum, latestBlkNum, []string{eventName}, func(log gethtypes.Log) (bool, error) {
+		logs = append(logs, log)
+		return true, nil
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	return logs, latestBlkNum, nil
+}
+
+// iterateLogs calls fn with the logs of the events emitted by the contract between the root chain blocks
+// at fromBlkNum and toBlkNum, oldest first, until fn returns false.
+// The logs are fetched logsBlockRange blocks at a time, so that no single query covers the whole chain.
+func (rc *RootChain) iterateLogs(ctx context.Context, fromBlkNum, toBlkNum uint64, eventNames []string, fn func(log gethtypes.Log) (bool, error)) error {
+	eventIDs := make([]common.Hash, len(eventNames))
+	for i, eventName := range eventNames {
+		eventIDs[i] = rc.abi.Events[eventName].Id()
+	}
+
+	for rangeFromBlkNum := fromBlkNum; rangeFromBlkNum <= toBlkNum; rangeFromBlkNum += logsBlockRange {
+		rangeToBlkNum := rangeFromBlkNum + logsBlockRange - 1
+		if rangeToBlkNum > toBlkNum {
+			rangeToBlkNum = toBlkNum
+		}
+
+		logs, err := rc.rpcClient.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(rangeFromBlkNum),
+			ToBlock:   new(big.Int).SetUint64(rangeToBlkNum),
+			Addresses: []common.Address{rc.address},
+			Topics:    [][]common.Hash{eventIDs},
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, log := range logs {
+			ok, err := fn(log)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
 func (rc *RootChain) Ping() error {
 	if err := rc.wsClient.Call(nil, "eth_getFilterLogs", "0x0"); err != nil {
 		if err.Error() == "filter not found" {
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testRootChainCall answers a call of a contract method with its outputs.
+type testRootChainCall func(input []byte) []interface{}
+
+// testRootChainMethod answers a JSON-RPC method other than eth_call with its result.
+type testRootChainMethod func(params []json.RawMessage) interface{}
+
+// newTestRootChain connects to a JSON-RPC server answering eth_call to the root chain contract
+// with calls, keyed by method name.
+func newTestRootChain(t *testing.T, calls map[string]testRootChainCall) (*RootChain, func()) {
+	return newTestRootChainWithMethods(t, calls, nil)
+}
+
+// newTestRootChainWithMethods is newTestRootChain also answering the JSON-RPC methods of methods.
+func newTestRootChainWithMethods(t *testing.T, calls map[string]testRootChainCall, methods map[string]testRootChainMethod) (*RootChain, func()) {
+	var rc *RootChain
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result interface{}
+		if req.Method == "eth_call" {
+			var msg struct {
+				Data hexutil.Bytes `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(req.Params[0], &msg))
+
+			method, err := rc.abi.MethodById(msg.Data[:4])
+			require.NoError(t, err)
+			call, ok := calls[method.Name]
+			require.True(t, ok, method.Name)
+
+			out, err := method.Outputs.Pack(call(msg.Data[4:])...)
+			require.NoError(t, err)
+			result = hexutil.Bytes(out)
+		} else {
+			method, ok := methods[req.Method]
+			require.True(t, ok, req.Method)
+			result = method(req.Params)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  result,
+		}))
+	}))
+
+	rc, err := NewRootChain(RootChainConfig{
+		RPC:        server.URL,
+		WS:         server.URL,
+		AddressStr: "0x0000000000000000000000000000000000000001",
+	})
+	require.NoError(t, err)
+
+	return rc, server.Close
+}
+
+// newTestPlasmaBlocksCall answers plasmaBlocks with roots, keyed by block number.
+func newTestPlasmaBlocksCall(roots map[uint64][32]byte) testRootChainCall {
+	return func(input []byte) []interface{} {
+		blkNum := new(big.Int).SetBytes(input[:32]).Uint64()
+		return []interface{}{roots[blkNum], big.NewInt(0)}
+	}
+}
+
+// newTestUintCall answers a method returning a single uint256.
+func newTestUintCall(n uint64) testRootChainCall {
+	return func(input []byte) []interface{} {
+		return []interface{}{new(big.Int).SetUint64(n)}
+	}
+}
+
+// newTestHeaderMethod answers eth_getBlockByNumber with the header of the latest block, numbered *blkNum.
+func newTestHeaderMethod(blkNum *uint64) testRootChainMethod {
+	return func(params []json.RawMessage) interface{} {
+		return &gethtypes.Header{
+			Number:     new(big.Int).SetUint64(*blkNum),
+			Difficulty: big.NewInt(0),
+			Time:       big.NewInt(0),
+		}
+	}
+}
+
+// newTestLogsMethod answers eth_getLogs with the logs of *logs within the queried block range,
+// counting the queries in *queried.
+func newTestLogsMethod(t *testing.T, logs *[]gethtypes.Log, queried *int) testRootChainMethod {
+	return func(params []json.RawMessage) interface{} {
+		var q struct {
+			FromBlock hexutil.Uint64 `json:"fromBlock"`
+			ToBlock   hexutil.Uint64 `json:"toBlock"`
+		}
+		require.NoError(t, json.Unmarshal(params[0], &q))
+		*queried++
+
+		found := []gethtypes.Log{}
+		for _, log := range *logs {
+			if log.BlockNumber >= uint64(q.FromBlock) && log.BlockNumber <= uint64(q.ToBlock) {
+				found = append(found, log)
+			}
+		}
+		return found
+	}
+}
+
+// newTestLog builds the log of the event emitted in the root chain block at rootBlkNum,
+// with the indexed args in topics and the others in args.
+func newTestLog(t *testing.T, rc *RootChain, eventName string, rootBlkNum uint64, topics []common.Hash, args ...interface{}) gethtypes.Log {
+	event := rc.abi.Events[eventName]
+	data, err := event.Inputs.NonIndexed().Pack(args...)
+	require.NoError(t, err)
+
+	return gethtypes.Log{
+		Address:     rc.address,
+		Topics:      append([]common.Hash{event.Id()}, topics...),
+		Data:        data,
+		BlockNumber: rootBlkNum,
+	}
+}
+
+func TestRootChain_PlasmaBlockCommitmentBlockNumber(t *testing.T) {
+	latestBlkNum := uint64(20)
+	logs := []gethtypes.Log{}
+	queried := 0
+
+	rc, closeRC := newTestRootChainWithMethods(t, nil, map[string]testRootChainMethod{
+		"eth_getBlockByNumber": newTestHeaderMethod(&latestBlkNum),
+		"eth_getLogs":          newTestLogsMethod(t, &logs, &queried),
+	})
+	defer closeRC()
+
+	owner := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	logs = append(logs,
+		newTestLog(t, rc, "PlasmaBlockRootCommitted", 10, nil, big.NewInt(1000), [32]byte{0x01}),
+		newTestLog(t, rc, "DepositCreated", 12, []common.Hash{owner.Hash()}, big.NewInt(100), big.NewInt(1001)),
+	)
+
+	ctx := context.Background()
+
+	rootBlkNum, err := rc.PlasmaBlockCommitmentBlockNumber(ctx, 1000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), rootBlkNum)
+	require.Equal(t, 1, queried)
+
+	// cached by the first scan
+	rootBlkNum, err = rc.PlasmaBlockCommitmentBlockNumber(ctx, 1001)
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), rootBlkNum)
+	require.Equal(t, 1, queried)
+
+	// no root chain block is mined since the last scan
+	_, err = rc.PlasmaBlockCommitmentBlockNumber(ctx, 2000)
+	require.Equal(t, ErrBlockNotCommitted, err)
+	require.Equal(t, 1, queried)
+
+	// only the blocks mined since the last scan are scanned
+	logs = append(logs, newTestLog(t, rc, "PlasmaBlockRootCommitted", 25, nil, big.NewInt(2000), [32]byte{0x02}))
+	latestBlkNum = 30
+
+	rootBlkNum, err = rc.PlasmaBlockCommitmentBlockNumber(ctx, 2000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(25), rootBlkNum)
+	require.Equal(t, 2, queried)
+}
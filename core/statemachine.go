@@ -0,0 +1,81 @@
+package core
+
+// Command is a mutation of the child chain state applied with txn.
+type Command func(txn Txn) error
+
+type command struct {
+	fn    Command
+	errCh chan error
+}
+
+// StateMachine applies the commands submitted to it one at a time in submission order.
+// Each command runs in its own read-write txn, which is committed if the command succeeds
+// and discarded otherwise. Since no two read-write txns are ever open at the same time,
+// mutations never conflict with each other.
+type StateMachine struct {
+	kv     KV
+	cc     *ChildChain
+	cmdCh  chan *command
+	quitCh chan struct{}
+	doneCh chan struct{}
+}
+
+func NewStateMachine(kv KV, cc *ChildChain) *StateMachine {
+	sm := &StateMachine{
+		kv:     kv,
+		cc:     cc,
+		cmdCh:  make(chan *command),
+		quitCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+
+	go sm.run()
+
+	return sm
+}
+
+// Apply submits cmd and waits until it is applied.
+func (sm *StateMachine) Apply(cmd Command) error {
+	c := &command{
+		fn:    cmd,
+		errCh: make(chan error, 1),
+	}
+
+	select {
+	case sm.cmdCh <- c:
+	case <-sm.quitCh:
+		return ErrStateMachineStopped
+	}
+
+	return <-c.errCh
+}
+
+// Stop waits for the command being applied and stops accepting commands.
+func (sm *StateMachine) Stop() {
+	close(sm.quitCh)
+	<-sm.doneCh
+}
+
+func (sm *StateMachine) run() {
+	defer close(sm.doneCh)
+
+	for {
+		select {
+		case c := <-sm.cmdCh:
+			c.errCh <- sm.apply(c.fn)
+		case <-sm.quitCh:
+			return
+		}
+	}
+}
+
+func (sm *StateMachine) apply(cmd Command) error {
+	txn := sm.kv.NewTransaction(true)
+	defer sm.cc.Discard(txn)
+
+	if err := cmd(txn); err != nil {
+		return err
+	}
+
+	return sm.cc.Commit(txn)
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateMachine_Apply(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	a := types.NewAccount(privKey)
+
+	txn := db.NewTransaction(true)
+	cc, err := NewChildChain(txn)
+	require.NoError(t, err)
+	require.NoError(t, cc.Commit(txn))
+
+	sm := NewStateMachine(db, cc)
+
+	// concurrent deposits would conflict if they were applied in parallel txns
+	n := 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- sm.Apply(func(txn Txn) error {
+				_, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(1), a)
+				return err
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	rtxn := db.NewTransaction(false)
+	defer rtxn.Discard()
+
+	blkNum, err := cc.GetCurrentBlockNumber(rtxn)
+	require.NoError(t, err)
+	require.Equal(t, FirstBlockNumber+uint64(n), blkNum)
+
+	// a failed command is discarded
+	require.Equal(t, ErrEmptyBlock, sm.Apply(func(txn Txn) error {
+		if err := cc.setCurrentBlockNumber(txn, 0); err != nil {
+			return err
+		}
+		return ErrEmptyBlock
+	}))
+
+	sm.Stop()
+	require.Equal(t, ErrStateMachineStopped, sm.Apply(func(txn Txn) error {
+		return nil
+	}))
+
+	rtxn2 := db.NewTransaction(false)
+	defer rtxn2.Discard()
+
+	blkNum, err = cc.GetCurrentBlockNumber(rtxn2)
+	require.NoError(t, err)
+	require.Equal(t, FirstBlockNumber+uint64(n), blkNum)
+}
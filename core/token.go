@@ -0,0 +1,345 @@
+package core
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/utils"
+)
+
+// Token is the address index entry of a txout in a block.
+// It holds what is needed to list the txouts and balance of an address
+// without decoding the txes that created them.
+type Token struct {
+	Amount           *big.Int
+	SpendingTxInPos  types.Position // 0 until spent in a block
+	IsSpentInMempool bool
+	IsExited         bool
+	ExitBlockNumber  uint64 // current block number when the exit was recorded, 0 if unknown
+}
+
+// tokenV1 is a token stored before the exit block number was recorded.
+type tokenV1 struct {
+	Amount           *big.Int
+	SpendingTxInPos  types.Position
+	IsSpentInMempool bool
+	IsExited         bool
+}
+
+func NewToken(amount *big.Int) *Token {
+	return &Token{
+		Amount: amount,
+	}
+}
+
+func (token *Token) IsSpent() bool {
+	return token.SpendingTxInPos > 0
+}
+
+// IsSpendable reports whether the txout can be spent by a new tx.
+func (token *Token) IsSpendable() bool {
+	return !token.IsSpent() && !token.IsSpentInMempool && !token.IsExited
+}
+
+// IsUnspentAt reports whether the txout at txOutPos was in a block and neither spent in a block
+// nor exited right after the block at blkNum. An exit whose block number is unknown is not taken into account.
+func (token *Token) IsUnspentAt(txOutPos types.Position, blkNum uint64) bool {
+	if txOutBlkNum, _, _ := types.ParseTxOutPosition(txOutPos); txOutBlkNum > blkNum {
+		return false
+	}
+	if token.IsExited && token.ExitBlockNumber > 0 && token.ExitBlockNumber <= blkNum {
+		return false
+	}
+	if !token.IsSpent() {
+		return true
+	}
+
+	spendingBlkNum, _, _ := types.ParseTxInPosition(token.SpendingTxInPos)
+
+	return spendingBlkNum > blkNum
+}
+
+// UTXO is an unspent txout in a block.
+type UTXO struct {
+	Position    types.Position `json:"pos"`
+	Amount      *big.Int       `json:"amount"`
+	TxPosition  types.Position `json:"txpos"`
+	BlockNumber uint64         `json:"blknum"`
+}
+
+func NewUTXO(txOutPos types.Position, amount *big.Int) *UTXO {
+	blkNum, txIndex, _ := types.ParseTxOutPosition(txOutPos)
+
+	return &UTXO{
+		Position:    txOutPos,
+		Amount:      amount,
+		TxPosition:  types.NewTxPosition(blkNum, txIndex),
+		BlockNumber: blkNum,
+	}
+}
+
+// BalanceEntry sums up txouts in the same state.
+type BalanceEntry struct {
+	Amount *big.Int `json:"amount"`
+	Count  uint64   `json:"count"`
+}
+
+func newBalanceEntry() *BalanceEntry {
+	return &BalanceEntry{
+		Amount: big.NewInt(0),
+	}
+}
+
+func (entry *BalanceEntry) add(amount *big.Int) {
+	entry.Amount.Add(entry.Amount, amount)
+	entry.Count++
+}
+
+// Balance sums up the txouts of an address that are not spent in a block.
+type Balance struct {
+	Total          *big.Int      `json:"total"`
+	Count          uint64        `json:"count"`
+	Spendable      *BalanceEntry `json:"spendable"`
+	SpentInMempool *BalanceEntry `json:"mempool"`
+	Exiting        *BalanceEntry `json:"exiting"`
+}
+
+func NewBalance() *Balance {
+	return &Balance{
+		Total:          big.NewInt(0),
+		Spendable:      newBalanceEntry(),
+		SpentInMempool: newBalanceEntry(),
+		Exiting:        newBalanceEntry(),
+	}
+}
+
+func (cc *ChildChain) GetUTXOPositions(txn Txn, addr common.Address) ([]types.Position, error) {
+	utxos, err := cc.GetUTXOs(txn, addr, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	poses := make([]types.Position, len(utxos))
+	for i, utxo := range utxos {
+		poses[i] = utxo.Position
+	}
+
+	return poses, nil
+}
+
+// GetUTXOs returns the spendable txouts of addr in position order.
+// It skips txouts of less than minAmount unless minAmount is nil,
+// and returns at most limit txouts unless limit is 0.
+func (cc *ChildChain) GetUTXOs(txn Txn, addr common.Address, minAmount *big.Int, limit uint64) ([]*UTXO, error) {
+	return cc.getUTXOs(txn, addr, minAmount, limit, func(txOutPos types.Position, token *Token) bool {
+		return token.IsSpendable()
+	})
+}
+
+// GetUTXOsAt returns the txouts of addr that were unspent right after the block at blkNum, in position order,
+// filtered like GetUTXOs. Exits recorded before the block after blkNum was made are taken into account.
+func (cc *ChildChain) GetUTXOsAt(txn Txn, addr common.Address, blkNum uint64, minAmount *big.Int, limit uint64) ([]*UTXO, error) {
+	if err := cc.checkBlockNumber(txn, blkNum); err != nil {
+		return nil, err
+	}
+
+	return cc.getUTXOs(txn, addr, minAmount, limit, func(txOutPos types.Position, token *Token) bool {
+		return token.IsUnspentAt(txOutPos, blkNum)
+	})
+}
+
+func (cc *ChildChain) getUTXOs(txn Txn, addr common.Address, minAmount *big.Int, limit uint64, isUTXO func(txOutPos types.Position, token *Token) bool) ([]*UTXO, error) {
+	utxos := []*UTXO{}
+	if err := cc.iterateTokens(txn, addr, func(txOutPos types.Position, token *Token) error {
+		if !isUTXO(txOutPos, token) {
+			return nil
+		}
+		if minAmount != nil && token.Amount.Cmp(minAmount) < 0 {
+			return nil
+		}
+
+		utxos = append(utxos, NewUTXO(txOutPos, token.Amount))
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// keys are ordered as strings
+	sort.Slice(utxos, func(i, j int) bool {
+		return utxos[i].Position < utxos[j].Position
+	})
+
+	if limit > 0 && limit < uint64(len(utxos)) {
+		utxos = utxos[:limit]
+	}
+
+	return utxos, nil
+}
+
+func (cc *ChildChain) GetBalance(txn Txn, addr common.Address) (*Balance, error) {
+	balance := NewBalance()
+	if err := cc.iterateTokens(txn, addr, func(txOutPos types.Position, token *Token) error {
+		switch {
+		case token.IsSpent():
+			return nil
+		case token.IsExited:
+			balance.Exiting.add(token.Amount)
+		case token.IsSpentInMempool:
+			balance.SpentInMempool.add(token.Amount)
+		default:
+			balance.Spendable.add(token.Amount)
+		}
+
+		balance.Total.Add(balance.Total, token.Amount)
+		balance.Count++
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+// GetBalanceAt sums up the txouts of addr that were unspent right after the block at blkNum.
+// They are all counted as spendable, since the mempool at that time is not recorded.
+func (cc *ChildChain) GetBalanceAt(txn Txn, addr common.Address, blkNum uint64) (*Balance, error) {
+	if err := cc.checkBlockNumber(txn, blkNum); err != nil {
+		return nil, err
+	}
+
+	balance := NewBalance()
+	if err := cc.iterateTokens(txn, addr, func(txOutPos types.Position, token *Token) error {
+		if !token.IsUnspentAt(txOutPos, blkNum) {
+			return nil
+		}
+
+		balance.Spendable.add(token.Amount)
+		balance.Total.Add(balance.Total, token.Amount)
+		balance.Count++
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+// checkBlockNumber returns ErrBlockNotFound unless the block at blkNum is stored.
+func (cc *ChildChain) checkBlockNumber(txn Txn, blkNum uint64) error {
+	currentBlkNum, err := cc.getCurrentBlockNumber(txn)
+	if err != nil {
+		return err
+	}
+	if blkNum < FirstBlockNumber || blkNum >= currentBlkNum {
+		return ErrBlockNotFound
+	}
+
+	return nil
+}
+
+// AddressUTXO is a txout in a block that is neither spent in a block nor exited, together with its owner.
+type AddressUTXO struct {
+	Address common.Address `json:"address"`
+	*UTXO
+}
+
+// GetAllUTXOs returns the utxo set of the child chain in key order.
+// Unlike GetUTXOs, txouts spent in the mempool are included, since they are not spent in a block yet.
+func (cc *ChildChain) GetAllUTXOs(txn Txn) ([]*AddressUTXO, error) {
+	utxos := []*AddressUTXO{}
+	if err := cc.iterateAllTokens(txn, func(addr common.Address, txOutPos types.Position, token *Token) error {
+		if addr == types.NullAddress {
+			// the empty outputs of deposit txs are not tokens of anyone
+			return nil
+		}
+		if token.IsSpent() || token.IsExited {
+			return nil
+		}
+
+		utxos = append(utxos, &AddressUTXO{
+			Address: addr,
+			UTXO:    NewUTXO(txOutPos, token.Amount),
+		})
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return utxos, nil
+}
+
+// iterateAllTokens iterates the tokens of every address in key order.
+func (cc *ChildChain) iterateAllTokens(txn Txn, fn func(addr common.Address, txOutPos types.Position, token *Token) error) error {
+	prefix := []byte(tokenKeyPrefix + "_")
+
+	it := txn.NewIterator(prefix)
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		// get address and position
+		keyStr := strings.TrimPrefix(string(it.Key()), string(prefix))
+		i := strings.LastIndex(keyStr, "_")
+		if i < 0 || !utils.IsHexAddress(keyStr[:i]) {
+			continue
+		}
+		addr := utils.HexToAddress(keyStr[:i])
+		txOutPos, err := types.StrToPosition(keyStr[i+1:])
+		if err != nil {
+			return err
+		}
+
+		// get token
+		tokenBytes, err := it.Value()
+		if err != nil {
+			return err
+		}
+		token, err := cc.decodeToken(txn, txOutPos, tokenBytes)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(addr, txOutPos, token); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cc *ChildChain) iterateTokens(txn Txn, addr common.Address, fn func(txOutPos types.Position, token *Token) error) error {
+	prefix := cc.tokenKeyPrefix(addr)
+
+	it := txn.NewIterator(prefix)
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		// get position
+		txOutPos, err := types.StrToPosition(strings.TrimPrefix(string(it.Key()), string(prefix)))
+		if err != nil {
+			return err
+		}
+
+		// get token
+		tokenBytes, err := it.Value()
+		if err != nil {
+			return err
+		}
+		token, err := cc.decodeToken(txn, txOutPos, tokenBytes)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(txOutPos, token); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChildChain_GetUTXOs(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNums := []uint64{}
+	for _, amount := range []int64{100, 50, 30, 10} {
+		depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(amount), a)
+		require.NoError(t, err)
+		depositBlkNums = append(depositBlkNums, depositBlkNum)
+	}
+
+	// spend the deposit of 50 in the mempool and exit the deposit of 30
+	require.NoError(t, cc.AddTxToMempool(txn,
+		newTestSpendingTx(t, types.NewTxIn(depositBlkNums[1], 0, 0), a, types.NewTxOut(b.Address(), big.NewInt(50))),
+	))
+	require.NoError(t, cc.ExitTxOut(txn, types.NewTxOutPosition(depositBlkNums[2], 0, 0)))
+
+	utxos, err := cc.GetUTXOs(txn, a.Address(), nil, 0)
+	require.NoError(t, err)
+	require.Len(t, utxos, 2)
+	require.Equal(t, types.NewTxOutPosition(depositBlkNums[0], 0, 0), utxos[0].Position)
+	require.Equal(t, big.NewInt(100), utxos[0].Amount)
+	require.Equal(t, types.NewTxPosition(depositBlkNums[0], 0), utxos[0].TxPosition)
+	require.Equal(t, depositBlkNums[0], utxos[0].BlockNumber)
+	require.Equal(t, types.NewTxOutPosition(depositBlkNums[3], 0, 0), utxos[1].Position)
+
+	utxos, err = cc.GetUTXOs(txn, a.Address(), big.NewInt(20), 0)
+	require.NoError(t, err)
+	require.Len(t, utxos, 1)
+	require.Equal(t, big.NewInt(100), utxos[0].Amount)
+
+	utxos, err = cc.GetUTXOs(txn, a.Address(), nil, 1)
+	require.NoError(t, err)
+	require.Len(t, utxos, 1)
+	require.Equal(t, types.NewTxOutPosition(depositBlkNums[0], 0, 0), utxos[0].Position)
+
+	balance, err := cc.GetBalance(txn, a.Address())
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(190), balance.Total)
+	require.Equal(t, uint64(4), balance.Count)
+	require.Equal(t, &BalanceEntry{Amount: big.NewInt(110), Count: 2}, balance.Spendable)
+	require.Equal(t, &BalanceEntry{Amount: big.NewInt(50), Count: 1}, balance.SpentInMempool)
+	require.Equal(t, &BalanceEntry{Amount: big.NewInt(30), Count: 1}, balance.Exiting)
+
+	// the txes in the mempool are not in a block yet
+	balance, err = cc.GetBalance(txn, b.Address())
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(0), balance.Total)
+}
+
+func TestChildChain_GetUTXOsAt_Exit(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, _ := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	txOutPos := types.NewTxOutPosition(depositBlkNum, 0, 0)
+
+	// the exit is recorded before the next block
+	require.NoError(t, cc.ExitTxOut(txn, txOutPos))
+	lastDepositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(10), a)
+	require.NoError(t, err)
+
+	utxos, err := cc.GetUTXOsAt(txn, a.Address(), depositBlkNum, nil, 0)
+	require.NoError(t, err)
+	require.Len(t, utxos, 1)
+	require.Equal(t, txOutPos, utxos[0].Position)
+
+	utxos, err = cc.GetUTXOsAt(txn, a.Address(), lastDepositBlkNum, nil, 0)
+	require.NoError(t, err)
+	require.Len(t, utxos, 1)
+	require.Equal(t, types.NewTxOutPosition(lastDepositBlkNum, 0, 0), utxos[0].Position)
+
+	balance, err := cc.GetBalanceAt(txn, a.Address(), lastDepositBlkNum)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(10), balance.Total)
+
+	// an exit seen again keeps its block number
+	require.NoError(t, cc.ExitTxOut(txn, txOutPos))
+	token, err := cc.getToken(txn, a.Address(), txOutPos)
+	require.NoError(t, err)
+	require.Equal(t, lastDepositBlkNum, token.ExitBlockNumber)
+}
+
+func TestChildChain_DecodeToken_WithoutExitBlockNumber(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, _ := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	txOutPos := types.NewTxOutPosition(depositBlkNum, 0, 0)
+
+	tokenBytes, err := rlp.EncodeToBytes(&tokenV1{
+		Amount:   big.NewInt(100),
+		IsExited: true,
+	})
+	require.NoError(t, err)
+
+	token, err := cc.decodeToken(txn, txOutPos, tokenBytes)
+	require.NoError(t, err)
+	require.Equal(t, &Token{
+		Amount:   big.NewInt(100),
+		IsExited: true,
+	}, token)
+
+	// an exit without its block number is not taken into account
+	require.True(t, token.IsUnspentAt(txOutPos, depositBlkNum))
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+)
+
+const (
+	TxStatePending   = "pending"   // in the mempool
+	TxStateIncluded  = "included"  // in a block
+	TxStateCommitted = "committed" // in a block whose root is committed to the root chain
+	TxStateEvicted   = "evicted"   // removed from the mempool without being included in a block
+)
+
+// TxStatus tells where a tx is on its way into the child chain.
+// Position is only set once the tx is included in a block.
+// Deposit txes of the same owner and amount have the same hash,
+// in which case Positions lists all of their positions, the first of which is Position.
+type TxStatus struct {
+	Hash      common.Hash      `json:"hash"`
+	State     string           `json:"state"`
+	Position  types.Position   `json:"txpos"`
+	Positions []types.Position `json:"txposes,omitempty"`
+}
+
+// txHashEntry is the tx hash index entry of a tx that left the mempool.
+// LaterPositions holds the positions of the txes with the same hash included after the first.
+type txHashEntry struct {
+	Position       types.Position // 0 if the tx was evicted
+	IsEvicted      bool
+	LaterPositions []types.Position `rlp:"tail"`
+}
+
+func (entry *txHashEntry) hasPosition(txPos types.Position) bool {
+	if entry.Position == txPos {
+		return true
+	}
+	for _, pos := range entry.LaterPositions {
+		if pos == txPos {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetTxStatus looks up the tx with txHash in the mempool and the tx hash index.
+// Whether the block is committed to the root chain is not known to the child chain,
+// so a tx in a block is reported as included.
+func (cc *ChildChain) GetTxStatus(txn Txn, txHash common.Hash) (*TxStatus, error) {
+	status := &TxStatus{
+		Hash: txHash,
+	}
+
+	// a tx evicted once may be submitted again
+	if cc.mempool.get(txn, txHash) != nil {
+		status.State = TxStatePending
+		return status, nil
+	}
+
+	entry, err := cc.getTxHashEntry(txn, txHash)
+	if err != nil {
+		if err == ErrKeyNotFound {
+			return nil, ErrTxNotFound
+		} else {
+			return nil, err
+		}
+	}
+
+	if entry.IsEvicted {
+		status.State = TxStateEvicted
+	} else {
+		status.State = TxStateIncluded
+		status.Position = entry.Position
+		if len(entry.LaterPositions) > 0 {
+			status.Positions = append([]types.Position{entry.Position}, entry.LaterPositions...)
+		}
+	}
+
+	return status, nil
+}
+
+// TxFinality tells how final a tx is, combining its status with the state of its inputs and outputs.
+// Whether the block is committed to the root chain has to be filled in by the caller.
+type TxFinality struct {
+	*TxStatus
+	BlockNumber   uint64           `json:"blknum"`
+	IsCommitted   bool             `json:"committed"`
+	Confirmations uint64           `json:"confirmations"`
+	Inputs        []*TxInFinality  `json:"inputs"`
+	Outputs       []*TxOutFinality `json:"outputs"`
+}
+
+// TxInFinality tells whether the owner of the spent txout confirmed the tx.
+// Position is the position of the spent txout.
+type TxInFinality struct {
+	Index       uint64         `json:"index"`
+	Position    types.Position `json:"pos"`
+	IsConfirmed bool           `json:"confirmed"`
+}
+
+// TxOutFinality tells whether the txout is spent or exiting.
+// Position is only set once the tx is included in a block.
+type TxOutFinality struct {
+	Index     uint64         `json:"index"`
+	Position  types.Position `json:"pos"`
+	IsSpent   bool           `json:"spent"`
+	IsExiting bool           `json:"exiting"`
+}
+
+// NewTxFinality builds the finality of tx. tx is nil if it was evicted.
+func NewTxFinality(status *TxStatus, tx *types.Tx) *TxFinality {
+	f := &TxFinality{
+		TxStatus: status,
+		Inputs:   []*TxInFinality{},
+		Outputs:  []*TxOutFinality{},
+	}
+	if status.Position > 0 {
+		f.BlockNumber, _ = types.ParseTxPosition(status.Position)
+	}
+	if tx == nil {
+		return f
+	}
+
+	for i, txIn := range tx.Inputs {
+		if txIn.IsNull() {
+			continue
+		}
+
+		f.Inputs = append(f.Inputs, &TxInFinality{
+			Index:       uint64(i),
+			Position:    types.NewTxOutPosition(txIn.BlockNumber, txIn.TxIndex, txIn.OutputIndex),
+			IsConfirmed: !txIn.ConfirmationSignature.IsNull(),
+		})
+	}
+
+	for i, txOut := range tx.Outputs {
+		if txOut.OwnerAddress == types.NullAddress {
+			continue
+		}
+
+		txOutF := &TxOutFinality{
+			Index:     uint64(i),
+			IsSpent:   txOut.IsSpent,
+			IsExiting: txOut.IsExited,
+		}
+		if status.Position > 0 {
+			_, txIndex := types.ParseTxPosition(status.Position)
+			txOutF.Position = types.NewTxOutPosition(f.BlockNumber, txIndex, uint64(i))
+		}
+
+		f.Outputs = append(f.Outputs, txOutF)
+	}
+
+	return f
+}
+
+// GetTxWithStatus returns the tx with txHash and its status.
+// The tx is nil if it was evicted.
+func (cc *ChildChain) GetTxWithStatus(txn Txn, txHash common.Hash) (*types.Tx, *TxStatus, error) {
+	status, err := cc.GetTxStatus(txn, txHash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch status.State {
+	case TxStatePending:
+		mtx, err := cc.GetMempoolTx(txn, txHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		return mtx.Tx, status, nil
+	case TxStateEvicted:
+		return nil, status, nil
+	default:
+		tx, err := cc.GetTx(txn, status.Position)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tx, status, nil
+	}
+}
+
+// GetTxWithStatusByPosition returns the tx at txPos and its status.
+func (cc *ChildChain) GetTxWithStatusByPosition(txn Txn, txPos types.Position) (*types.Tx, *TxStatus, error) {
+	tx, err := cc.GetTx(txn, txPos)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txHash, err := tx.Hash()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tx, &TxStatus{
+		Hash:     txHash,
+		State:    TxStateIncluded,
+		Position: txPos,
+	}, nil
+}
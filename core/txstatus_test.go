@@ -0,0 +1,131 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChildChain_GetTxStatus(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+
+	tx := newTestSplitTx(t, depositBlkNum, a, b)
+	txHash, err := tx.Hash()
+	require.NoError(t, err)
+
+	_, err = cc.GetTxStatus(txn, txHash)
+	require.Equal(t, ErrTxNotFound, err)
+
+	require.NoError(t, cc.AddTxToMempool(txn, tx))
+	status, err := cc.GetTxStatus(txn, txHash)
+	require.NoError(t, err)
+	require.Equal(t, TxStatePending, status.State)
+
+	_, err = cc.RemoveTxFromMempool(txn, txHash)
+	require.NoError(t, err)
+	status, err = cc.GetTxStatus(txn, txHash)
+	require.NoError(t, err)
+	require.Equal(t, TxStateEvicted, status.State)
+
+	// submitted again after the eviction
+	blkNum := addTestBlock(t, txn, cc, a, tx)
+	status, err = cc.GetTxStatus(txn, txHash)
+	require.NoError(t, err)
+	require.Equal(t, TxStateIncluded, status.State)
+	require.Equal(t, types.NewTxPosition(blkNum, 0), status.Position)
+	require.Empty(t, status.Positions)
+}
+
+func TestChildChain_GetTxStatus_SameDeposits(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, _ := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	// deposits of the same owner and amount have the same hash
+	depositBlkNumA, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	depositBlkNumB, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+
+	depositTx, err := cc.GetTx(txn, types.NewTxPosition(depositBlkNumA, 0))
+	require.NoError(t, err)
+	txHash, err := depositTx.Hash()
+	require.NoError(t, err)
+
+	status, err := cc.GetTxStatus(txn, txHash)
+	require.NoError(t, err)
+	require.Equal(t, TxStateIncluded, status.State)
+	require.Equal(t, types.NewTxPosition(depositBlkNumA, 0), status.Position)
+	require.Equal(t, []types.Position{
+		types.NewTxPosition(depositBlkNumA, 0),
+		types.NewTxPosition(depositBlkNumB, 0),
+	}, status.Positions)
+}
+
+func TestTxHashEntry_DecodeWithoutLaterPositions(t *testing.T) {
+	// an entry stored before later positions were recorded
+	entryBytes, err := rlp.EncodeToBytes([]interface{}{types.Position(100000), false})
+	require.NoError(t, err)
+
+	var entry txHashEntry
+	require.NoError(t, rlp.DecodeBytes(entryBytes, &entry))
+	require.Equal(t, types.Position(100000), entry.Position)
+	require.Empty(t, entry.LaterPositions)
+}
+
+func TestNewTxFinality(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	a, b := newTestAccounts(t)
+
+	txn, cc := newTestChildChain(t, db)
+	defer txn.Discard()
+
+	depositBlkNum, _, err := cc.AddDepositBlock(txn, a.Address(), big.NewInt(100), a)
+	require.NoError(t, err)
+	tx := newTestSplitTx(t, depositBlkNum, a, b)
+	blkNum := addTestBlock(t, txn, cc, a, tx)
+	txPos := types.NewTxPosition(blkNum, 0)
+
+	// confirm the input and exit the output sent back to a
+	require.NoError(t, tx.Confirm(0, a))
+	require.NoError(t, cc.ConfirmTx(txn, types.NewTxInPosition(blkNum, 0, 0), tx.GetInput(0).ConfirmationSignature))
+	require.NoError(t, cc.ExitTxOut(txn, types.NewTxOutPosition(blkNum, 0, 1)))
+
+	storedTx, status, err := cc.GetTxWithStatusByPosition(txn, txPos)
+	require.NoError(t, err)
+
+	f := NewTxFinality(status, storedTx)
+	require.Equal(t, TxStateIncluded, f.State)
+	require.Equal(t, blkNum, f.BlockNumber)
+	require.Equal(t, []*TxInFinality{
+		{Index: 0, Position: types.NewTxOutPosition(depositBlkNum, 0, 0), IsConfirmed: true},
+	}, f.Inputs)
+	require.Equal(t, []*TxOutFinality{
+		{Index: 0, Position: types.NewTxOutPosition(blkNum, 0, 0)},
+		{Index: 1, Position: types.NewTxOutPosition(blkNum, 0, 1), IsExiting: true},
+	}, f.Outputs)
+
+	// an evicted tx has neither inputs nor outputs
+	f = NewTxFinality(&TxStatus{State: TxStateEvicted}, nil)
+	require.Equal(t, uint64(0), f.BlockNumber)
+	require.Empty(t, f.Inputs)
+	require.Empty(t, f.Outputs)
+}
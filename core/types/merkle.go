@@ -0,0 +1,85 @@
+package types
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	merkle "github.com/m0t0k1ch1/fixed-merkle-tree"
+)
+
+// MerkleLeafHash returns the hash of the leaf of tx in the tx Merkle tree,
+// from which the tree is rebuilt once tx itself is dropped.
+func (tx *Tx) MerkleLeafHash() (common.Hash, error) {
+	leaf, err := tx.MerkleLeaf()
+	if err != nil {
+		return NullHash, err
+	}
+
+	return crypto.Keccak256Hash(leaf), nil
+}
+
+// TxMerkleRoot returns the root of the tx Merkle tree whose leaves have leafHashes,
+// which is the same as the root of the tree built by Block.MerkleTree.
+func TxMerkleRoot(leafHashes []common.Hash) (common.Hash, error) {
+	levels, err := txMerkleLevels(leafHashes)
+	if err != nil {
+		return NullHash, err
+	}
+
+	return levels[len(levels)-1][0], nil
+}
+
+// CreateTxMerkleProof creates the membership proof of the leaf at index
+// in the tx Merkle tree whose leaves have leafHashes.
+func CreateTxMerkleProof(leafHashes []common.Hash, index uint64) ([]byte, error) {
+	levels, err := txMerkleLevels(leafHashes)
+	if err != nil {
+		return nil, err
+	}
+	if index >= uint64(len(levels[0])) {
+		return nil, merkle.ErrTooLargeLeafIndex
+	}
+
+	buf := bytes.NewBuffer(nil)
+	for _, level := range levels[:len(levels)-1] {
+		if _, err := buf.Write(level[index^1].Bytes()); err != nil {
+			return nil, err
+		}
+		index /= 2
+	}
+
+	return buf.Bytes(), nil
+}
+
+// txMerkleLevels builds the levels of the tx Merkle tree from the leaves up to the root.
+func txMerkleLevels(leafHashes []common.Hash) ([][]common.Hash, error) {
+	leavesNum := 1 << TxMerkleTreeDepth
+	if len(leafHashes) > leavesNum {
+		return nil, merkle.ErrTooManyLeaves
+	}
+
+	// the empty leaves are hashed like any other leaf
+	emptyLeafHash := crypto.Keccak256Hash(NullHash.Bytes())
+
+	level := make([]common.Hash, leavesNum)
+	for i := range level {
+		if i < len(leafHashes) {
+			level[i] = leafHashes[i]
+		} else {
+			level[i] = emptyLeafHash
+		}
+	}
+
+	levels := [][]common.Hash{level}
+	for len(level) > 1 {
+		parents := make([]common.Hash, len(level)/2)
+		for i := range parents {
+			parents[i] = crypto.Keccak256Hash(level[2*i].Bytes(), level[2*i+1].Bytes())
+		}
+		levels = append(levels, parents)
+		level = parents
+	}
+
+	return levels, nil
+}
@@ -0,0 +1,39 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxMerkleTree_FromLeafHashes(t *testing.T) {
+	blk := newTestBlock(t, []*Tx{newTestDepositTx(t), newTestNullTx(t), newTestDepositTx(t)}, 1)
+
+	leafHashes := make([]common.Hash, len(blk.Txes))
+	for i, tx := range blk.Txes {
+		leafHash, err := tx.MerkleLeafHash()
+		require.NoError(t, err)
+		leafHashes[i] = leafHash
+	}
+
+	tree, err := blk.MerkleTree()
+	require.NoError(t, err)
+
+	// root
+	rootHash, err := TxMerkleRoot(leafHashes)
+	require.NoError(t, err)
+	blkRootHash, err := blk.Root()
+	require.NoError(t, err)
+	assert.Equal(t, blkRootHash, rootHash)
+
+	// proof
+	for i := range blk.Txes {
+		proof, err := CreateTxMerkleProof(leafHashes, uint64(i))
+		require.NoError(t, err)
+		expectedProof, err := tree.CreateMembershipProof(uint64(i))
+		require.NoError(t, err)
+		assert.Equal(t, expectedProof, proof)
+	}
+}
@@ -0,0 +1,21 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PlasmaBlock is a block root committed to the root chain.
+type PlasmaBlock struct {
+	Root      [32]byte `json:"root"`
+	Timestamp *big.Int `json:"timestamp"`
+}
+
+func (pb PlasmaBlock) RootHash() common.Hash {
+	return common.Hash(pb.Root)
+}
+
+func (pb PlasmaBlock) IsCommitted() bool {
+	return pb.RootHash() != NullHash
+}
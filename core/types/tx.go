@@ -13,6 +13,8 @@ import (
 
 const (
 	TxElementsNum = 2
+
+	txCancellationPrefix = "cancel"
 )
 
 var (
@@ -87,6 +89,16 @@ func (tx *Tx) ConfirmationHash() (common.Hash, error) {
 	return utils.BytesToHash(crypto.Keccak256(h.Bytes())), nil
 }
 
+// CancellationHash is the hash signed to cancel the tx while it is in the mempool.
+func (tx *Tx) CancellationHash() (common.Hash, error) {
+	h, err := tx.Hash()
+	if err != nil {
+		return NullHash, err
+	}
+
+	return utils.BytesToHash(crypto.Keccak256([]byte(txCancellationPrefix), h.Bytes())), nil
+}
+
 func (tx *Tx) MerkleLeaf() ([]byte, error) {
 	b, err := tx.Encode()
 	if err != nil {
@@ -175,6 +187,16 @@ func (tx *Tx) SpendOutput(outIndex uint64) error {
 	return nil
 }
 
+func (tx *Tx) UnspendOutput(outIndex uint64) error {
+	if !tx.IsExistOutput(outIndex) {
+		return ErrInvalidTxOutIndex
+	}
+
+	tx.GetOutput(outIndex).IsSpent = false
+
+	return nil
+}
+
 func (tx *Tx) ExitOutput(outIndex uint64) error {
 	if !tx.IsExistOutput(outIndex) {
 		return ErrInvalidTxOutIndex
@@ -185,6 +207,22 @@ func (tx *Tx) ExitOutput(outIndex uint64) error {
 	return nil
 }
 
+// IsNull reports whether tx has neither inputs nor outputs, as a tx padding a block does.
+func (tx *Tx) IsNull() bool {
+	for _, txIn := range tx.Inputs {
+		if !txIn.IsNull() {
+			return false
+		}
+	}
+	for _, txOut := range tx.Outputs {
+		if !txOut.IsNull() {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (tx *Tx) IsExistOutput(outIndex uint64) bool {
 	return outIndex < TxElementsNum
 }
@@ -237,6 +275,20 @@ func (tx *Tx) Confirm(inIndex uint64, signer *Account) error {
 	return nil
 }
 
+func (tx *Tx) SignCancellation(signer *Account) (Signature, error) {
+	h, err := tx.CancellationHash()
+	if err != nil {
+		return NullSignature, err
+	}
+
+	sigBytes, err := signer.Sign(h)
+	if err != nil {
+		return NullSignature, err
+	}
+
+	return BytesToSignature(sigBytes)
+}
+
 func (tx *Tx) SignerAddress(inIndex uint64) (common.Address, error) {
 	if !tx.IsExistInput(inIndex) {
 		return NullAddress, ErrInvalidTxInIndex
@@ -263,6 +315,15 @@ func (tx *Tx) ConfirmationSignerAddress(inIndex uint64) (common.Address, error)
 	return tx.signerAddress(h, tx.GetInput(inIndex).ConfirmationSignature)
 }
 
+func (tx *Tx) CancellationSignerAddress(sig Signature) (common.Address, error) {
+	h, err := tx.CancellationHash()
+	if err != nil {
+		return NullAddress, err
+	}
+
+	return tx.signerAddress(h, sig)
+}
+
 func (tx *Tx) signerAddress(h common.Hash, sig Signature) (common.Address, error) {
 	if bytes.Equal(sig.Bytes(), NullSignature.Bytes()) {
 		return NullAddress, nil
@@ -153,3 +153,27 @@ func TestTx_Confirm(t *testing.T) {
 		}
 	}
 }
+
+func TestTx_SignCancellation(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	signer := NewAccount(privKey)
+	tx := newTestDepositTx(t)
+
+	// sign
+	sig, err := tx.SignCancellation(signer)
+	require.NoError(t, err)
+
+	// verify
+	signerAddr, err := tx.CancellationSignerAddress(sig)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), signerAddr)
+
+	// cancellation signature must not be reusable as a tx signature
+	h, err := tx.Hash()
+	require.NoError(t, err)
+	otherAddr, err := sig.SignerAddress(h)
+	require.NoError(t, err)
+	assert.NotEqual(t, signer.Address(), otherAddr)
+}
@@ -27,3 +27,8 @@ func NewTxOut(ownerAddr common.Address, amount *big.Int) *TxOut {
 		IsExited: false,
 	}
 }
+
+func (txOut *TxOut) IsNull() bool {
+	return txOut.OwnerAddress == NullAddress &&
+		txOut.Amount.Sign() == 0
+}
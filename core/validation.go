@@ -0,0 +1,85 @@
+package core
+
+import (
+	"math/big"
+
+	"github.com/m0t0k1ch1/more-minimal-plasma-chain/core/types"
+)
+
+// TxValidationResult is the outcome of CheckTx.
+type TxValidationResult struct {
+	InputAmount  *big.Int
+	OutputAmount *big.Int
+	Fee          *big.Int // InputAmount - OutputAmount, negative if the tx is unbalanced
+
+	// SignatureErrors holds the inputs whose signer could not be recovered.
+	// They are also included in Errors.
+	SignatureErrors []*TxInError
+	Errors          []error
+}
+
+func NewTxValidationResult() *TxValidationResult {
+	return &TxValidationResult{
+		InputAmount:     big.NewInt(0),
+		OutputAmount:    big.NewInt(0),
+		Fee:             big.NewInt(0),
+		SignatureErrors: []*TxInError{},
+		Errors:          []error{},
+	}
+}
+
+func (res *TxValidationResult) IsValid() bool {
+	return len(res.Errors) == 0
+}
+
+// Err returns the first violation found, or nil if the tx is valid.
+func (res *TxValidationResult) Err() error {
+	if res.IsValid() {
+		return nil
+	}
+
+	return res.Errors[0]
+}
+
+func (res *TxValidationResult) addError(err error) {
+	res.Errors = append(res.Errors, err)
+}
+
+func (res *TxValidationResult) addSignatureError(err *TxInError) {
+	res.SignatureErrors = append(res.SignatureErrors, err)
+	res.addError(err)
+}
+
+// VerifyDepositBlock checks that blk is the deposit block created for event, which is nil if no deposit
+// was made for the block number: a block of a single tx with no inputs paying the deposit to the depositor.
+func VerifyDepositBlock(blk *types.Block, event *RootChainDepositCreated) error {
+	if len(blk.Txes) != 1 {
+		return ErrInvalidDepositTx
+	}
+
+	return verifyDepositTx(blk.Number, blk.Txes[0], event)
+}
+
+// verifyDepositTx checks that tx, the only tx of the block at blkNum, is the deposit tx created for event.
+func verifyDepositTx(blkNum uint64, tx *types.Tx, event *RootChainDepositCreated) error {
+	if event == nil || event.DepositBlock.Cmp(new(big.Int).SetUint64(blkNum)) != 0 {
+		return ErrInvalidDepositTx
+	}
+
+	for _, txIn := range tx.Inputs {
+		if !txIn.IsNull() {
+			return ErrInvalidDepositTx
+		}
+	}
+	for i, txOut := range tx.Outputs {
+		if i == 0 {
+			if txOut.OwnerAddress != event.Owner || txOut.Amount.Cmp(event.Amount) != 0 {
+				return ErrInvalidDepositTx
+			}
+		} else if txOut.OwnerAddress != types.NullAddress || txOut.Amount.Sign() != 0 {
+			return ErrInvalidDepositTx
+		}
+	}
+
+	return nil
+}